@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"traffic-sim/internal/api"
+	"traffic-sim/internal/figure"
+	"traffic-sim/internal/heatmap"
+	"traffic-sim/internal/interop/geojson"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/replay"
+	"traffic-sim/internal/scenario"
+	"traffic-sim/internal/sim"
+	"traffic-sim/internal/triplog"
+	"traffic-sim/internal/world"
+)
+
+func main() {
+	loadPath := flag.String("load", "", "save file to simulate")
+	repair := flag.Bool("repair", false, "repair what can be fixed safely in a damaged save before simulating it")
+	scenarioPath := flag.String("scenario", "", "scenario file (defaults to <save>.scenario.json/.yaml next to the save)")
+	duration := flag.Float64("duration", 600, "simulated seconds to run")
+	tick := flag.Duration("tick", 8*time.Millisecond, "fixed simulation step")
+	metricsPath := flag.String("metrics", "", "write per-road metrics to this CSV file at the end of the run")
+	detectorsPath := flag.String("detectors", "", "write loop detector readings to this CSV file at the end of the run")
+	intersectionsPath := flag.String("intersections", "", "write the intersection delay/queue/LOS report to this CSV file at the end of the run")
+	geojsonPath := flag.String("geojson", "", "write the network with per-road metrics to this GeoJSON file at the end of the run")
+	origin := flag.String("origin", "0,0", "latitude and longitude of the world origin for -geojson")
+	imagePath := flag.String("image", "", "draw the network and traffic at the end of the run to this .png or .svg file")
+	imageBounds := flag.String("image-bounds", "", "area to draw as minX,minY,maxX,maxY in metres (defaults to the whole network)")
+	imageScale := flag.Float64("image-scale", 1, "pixels per metre for -image")
+	imageLegend := flag.Bool("image-legend", true, "add a legend with the overlay scales, a scale bar and the time to -image")
+	imageRoads := flag.String("image-roads", "none", "road heatmap for -image: none, speed, avgspeed, density or volume")
+	imageVehicles := flag.String("image-vehicles", "none", "vehicle heatmap for -image: none, speed, waiting or destination")
+	record := flag.String("record", "", "write a trajectory recording to this file")
+	trips := flag.String("trips", "", "append completed trips to this file (.csv, otherwise JSON Lines)")
+	seed := flag.Uint64("seed", 0, "random seed (ignored when resuming a snapshot)")
+	apiAddr := flag.String("api", "", "serve the HTTP API on this loopback address and run in real time (-duration 0 runs until interrupted)")
+	flag.Parse()
+
+	if *loadPath == "" {
+		log.Fatal("-load is required")
+	}
+	lat, lon, err := geojson.ParseOrigin(*origin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imageOpts, err := parseImageOptions(*imageBounds, *imageScale, *imageLegend, *imageRoads, *imageVehicles)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	load := persistence.LoadWorldFrom
+	if *repair {
+		load = persistence.LoadRepairedWorldFrom
+	}
+	w, saveData, err := load(*loadPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *seed != 0 && saveData.State == nil {
+		w.SetSeed(*seed)
+	}
+
+	simulator := sim.NewSimulator(w, *tick)
+
+	if *scenarioPath == "" {
+		if path, ok := scenario.FindFor(*loadPath); ok {
+			*scenarioPath = path
+		}
+	}
+	if *scenarioPath != "" {
+		sc, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		simulator.AddSystem(scenario.NewRunner(sc, simulator.Metrics()))
+		log.Printf("Scenario loaded from: %s (%d actions)", *scenarioPath, len(sc.Actions))
+	}
+
+	if saveData.State != nil {
+		if err := simulator.ImportSystemState(saveData.State.Systems); err != nil {
+			log.Fatalf("Failed to restore system state: %v", err)
+		}
+	}
+
+	if *record != "" {
+		recorder, err := replay.NewRecorder(*record, replay.DefaultInterval)
+		if err != nil {
+			log.Fatal(err)
+		}
+		simulator.AddSystem(recorder)
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				log.Printf("Failed to finish recording: %v", err)
+			}
+		}()
+	}
+
+	if *trips != "" {
+		logger, err := triplog.NewLogger(*trips)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger.Subscribe(w.Events)
+		defer logger.Close()
+	}
+
+	start := time.Now()
+	if *apiAddr != "" {
+		server := api.NewServer(simulator)
+		if err := server.Start(*apiAddr); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("API listening on http://%s", server.Addr())
+		runRealtime(simulator, *duration)
+		if err := server.Close(); err != nil {
+			log.Printf("Failed to stop API server: %v", err)
+		}
+	} else {
+		for w.SimTime < *duration {
+			simulator.Step()
+		}
+	}
+
+	if *metricsPath != "" || *detectorsPath != "" || *geojsonPath != "" || *imagePath != "" {
+		simulator.Metrics().Flush(w)
+	}
+	if *metricsPath != "" {
+		if err := simulator.Metrics().ExportCSV(*metricsPath); err != nil {
+			log.Printf("Failed to export metrics: %v", err)
+		} else {
+			log.Printf("Metrics written to: %s", *metricsPath)
+		}
+	}
+	if *detectorsPath != "" {
+		if err := simulator.Metrics().ExportDetectorCSV(*detectorsPath); err != nil {
+			log.Printf("Failed to export detector data: %v", err)
+		} else {
+			log.Printf("Detector data written to: %s", *detectorsPath)
+		}
+	}
+	if *geojsonPath != "" {
+		if err := writeGeoJSON(*geojsonPath, w, geojson.Options{Lat: lat, Lon: lon, Metrics: simulator.Metrics().Intervals()}); err != nil {
+			log.Printf("Failed to export GeoJSON: %v", err)
+		} else {
+			log.Printf("GeoJSON written to: %s", *geojsonPath)
+		}
+	}
+	if *imagePath != "" {
+		imageOpts.Metrics = simulator.Metrics()
+		w.Mu.RLock()
+		err := figure.Write(*imagePath, w, imageOpts)
+		w.Mu.RUnlock()
+		if err != nil {
+			log.Printf("Failed to export image: %v", err)
+		} else {
+			log.Printf("Image written to: %s", *imagePath)
+		}
+	}
+	if *intersectionsPath != "" {
+		if err := simulator.Intersections().ExportCSV(w, *intersectionsPath); err != nil {
+			log.Printf("Failed to export intersection report: %v", err)
+		} else {
+			log.Printf("Intersection report written to: %s", *intersectionsPath)
+		}
+	}
+
+	log.Printf("Simulated %.1fs in %s (%d vehicles on the network)", w.SimTime, time.Since(start).Round(time.Millisecond), len(w.Vehicles))
+}
+
+// runRealtime advances the simulation with the wall clock, so API clients
+// can pause and step it, until the duration is reached or the process is
+// interrupted.
+func runRealtime(simulator *sim.Simulator, duration float64) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := simulator.World()
+	ticker := time.NewTicker(simulator.TickRate())
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			simulator.UpdateOnce(now.Sub(last).Seconds())
+			last = now
+		}
+
+		w.Mu.RLock()
+		done := duration > 0 && w.SimTime >= duration
+		w.Mu.RUnlock()
+		if done {
+			return
+		}
+	}
+}
+
+func writeGeoJSON(path string, w *world.World, opts geojson.Options) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w.Mu.RLock()
+	fc := geojson.Export(w, opts)
+	w.Mu.RUnlock()
+	if err := geojson.Write(out, fc); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func parseImageOptions(bounds string, scale float64, legend bool, roads, vehicles string) (figure.Options, error) {
+	opts := figure.Options{Scale: scale, Legend: legend}
+	if scale <= 0 {
+		return opts, fmt.Errorf("invalid -image-scale %g", scale)
+	}
+	var err error
+	if bounds != "" {
+		if opts.Bounds, err = figure.ParseBounds(bounds); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Roads, err = heatmap.ParseRoadOverlay(roads); err != nil {
+		return opts, err
+	}
+	if opts.Vehicles, err = heatmap.ParseVehicleOverlay(vehicles); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/rlenv"
+)
+
+func main() {
+	addr := flag.String("listen", "127.0.0.1:5600", "loopback address to accept agent connections on")
+	tick := flag.Duration("tick", rlenv.DefaultTick, "fixed simulation step")
+	interval := flag.Float64("interval", rlenv.DefaultInterval, "simulated seconds between agent decisions")
+	duration := flag.Float64("duration", rlenv.DefaultDuration, "simulated seconds per episode (0 never ends an episode)")
+	configPath := flag.String("config", "", "configuration file (default: built-in settings)")
+	phasesPath := flag.String("phases", "", "JSON file mapping intersection IDs to phases of light IDs (default: the lights' fixed-time plans)")
+	flag.Parse()
+
+	cfg := rlenv.Config{Tick: *tick, Interval: *interval, Duration: *duration, Sim: config.Default()}
+	if *configPath != "" {
+		var err error
+		if cfg.Sim, err = config.LoadConfigFrom(*configPath); err != nil {
+			log.Fatalf("Could not load config: %v", err)
+		}
+	}
+	if *phasesPath != "" {
+		data, err := os.ReadFile(*phasesPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &cfg.Phases); err != nil {
+			log.Fatalf("Invalid phases file %s: %v", *phasesPath, err)
+		}
+	}
+
+	ln, err := rlenv.Listen(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	log.Printf("RL environment listening on %s", ln.Addr())
+	if err := rlenv.Serve(ln, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"traffic-sim/internal/interop/geojson"
+	"traffic-sim/internal/interop/osm"
+	"traffic-sim/internal/interop/sumo"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/world"
+)
+
+const usage = `usage: savetool <command> [flags] <args>...
+
+commands:
+  upgrade [-backup] [-dry-run] <file or directory>...
+             migrate save files to the current format in place
+  validate [-repair] [-backup] <file or directory>...
+             report broken references and bad values in save files, and
+             with -repair fix in place what can be fixed safely
+  import-osm <in.osm> <out.json>
+             build a save file from an OpenStreetMap extract
+  export-sumo [-duration s] <in.json> <prefix>
+             write SUMO plain-XML files <prefix>.nod.xml, .edg.xml, ...
+  import-sumo <prefix> <out.json>
+             build a save file from SUMO plain-XML files
+  export-geojson [-origin lat,lon] <in.json> <out.geojson>
+             write nodes and roads as GeoJSON
+  import-geojson [-origin lat,lon] [-into save.json] <in.geojson> <out.json>
+             build a save file from GeoJSON lines, or add them to an existing one
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "upgrade":
+		os.Exit(upgrade(os.Args[2:]))
+	case "validate":
+		os.Exit(validate(os.Args[2:]))
+	case "import-osm":
+		os.Exit(importOSM(os.Args[2:]))
+	case "export-sumo":
+		os.Exit(exportSUMO(os.Args[2:]))
+	case "import-sumo":
+		os.Exit(importSUMO(os.Args[2:]))
+	case "export-geojson":
+		os.Exit(exportGeoJSON(os.Args[2:]))
+	case "import-geojson":
+		os.Exit(importGeoJSON(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func upgrade(args []string) int {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	backup := flags.Bool("backup", false, "keep each original file as <file>.bak")
+	dryRun := flags.Bool("dry-run", false, "only report which files would be upgraded")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	paths, err := saveFiles(flags.Args())
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	var upgraded, current, failed int
+	for _, path := range paths {
+		var from string
+		if *dryRun {
+			_, from, err = readSave(path)
+		} else {
+			from, err = persistence.UpgradeFile(path, *backup)
+		}
+
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("FAIL     %s: %v\n", path, err)
+		case from == persistence.CurrentVersion:
+			current++
+		default:
+			upgraded++
+			fmt.Printf("UPGRADE  %s: %s -> %s\n", path, from, persistence.CurrentVersion)
+		}
+	}
+
+	verb := "upgraded"
+	if *dryRun {
+		verb = "to upgrade"
+	}
+	fmt.Printf("%d %s, %d already current, %d failed\n", upgraded, verb, current, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "fix what can be fixed safely and rewrite the files")
+	backup := flags.Bool("backup", false, "with -repair, keep each original file as <file>.bak")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	paths, err := saveFiles(flags.Args())
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	var errs, warnings, repaired, failed int
+	for _, path := range paths {
+		var report *persistence.Report
+		if *repair {
+			report, err = persistence.RepairFile(path, *backup)
+		} else {
+			var saveData *persistence.SaveFormat
+			if saveData, _, err = readSave(path); err == nil {
+				report = persistence.Validate(saveData)
+			}
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL     %s: %v\n", path, err)
+			continue
+		}
+
+		for _, p := range report.Problems {
+			label := "WARN"
+			switch {
+			case *repair && p.Repairable:
+				label = "REPAIRED"
+				repaired++
+			case p.Severity == persistence.SeverityError:
+				label = "ERROR"
+				errs++
+			default:
+				warnings++
+			}
+			note := ""
+			if !*repair && p.Repairable {
+				note = " (repairable)"
+			}
+			fmt.Printf("%-8s %s: %s: %s%s\n", label, path, p.Object, p.Message, note)
+		}
+	}
+
+	fmt.Printf("%d files checked, %d errors, %d warnings", len(paths), errs, warnings)
+	if *repair {
+		fmt.Printf(", %d repaired", repaired)
+	}
+	fmt.Printf(", %d failed\n", failed)
+	if errs > 0 || failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func importOSM(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer f.Close()
+
+	w, report, err := osm.Import(f)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARN     %s\n", warning)
+	}
+	if err := persistence.WriteSaveFile(args[1], persistence.SerializeWorld(w)); err != nil {
+		log.Print(err)
+		return 1
+	}
+	fmt.Printf("%d nodes, %d roads written to %s, %d ways skipped\n", report.Nodes, report.Roads, args[1], report.SkippedWays)
+	return 0
+}
+
+func exportSUMO(args []string) int {
+	flags := flag.NewFlagSet("export-sumo", flag.ExitOnError)
+	duration := flags.Float64("duration", sumo.DefaultDuration, "seconds of demand to write to the route file")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	w, err := loadWorld(flags.Arg(0))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	files, report := sumo.Export(w, sumo.ExportOptions{Duration: *duration})
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARN     %s\n", warning)
+	}
+	if err := files.Write(flags.Arg(1)); err != nil {
+		log.Print(err)
+		return 1
+	}
+	fmt.Printf("%d nodes, %d edges, %d traffic lights, %d flows written to %s.*.xml\n", report.Nodes, report.Roads, report.Lights, report.Flows, flags.Arg(1))
+	return 0
+}
+
+func importSUMO(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	files, err := sumo.ReadFiles(args[0])
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	w, report, err := sumo.Import(files)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARN     %s\n", warning)
+	}
+	if err := persistence.WriteSaveFile(args[1], persistence.SerializeWorld(w)); err != nil {
+		log.Print(err)
+		return 1
+	}
+	fmt.Printf("%d nodes, %d roads, %d traffic lights, %d spawn points written to %s\n", report.Nodes, report.Roads, report.Lights, report.SpawnPoints, args[1])
+	return 0
+}
+
+func exportGeoJSON(args []string) int {
+	flags := flag.NewFlagSet("export-geojson", flag.ExitOnError)
+	origin := flags.String("origin", "0,0", "latitude and longitude of the world origin")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	lat, lon, err := geojson.ParseOrigin(*origin)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	w, err := loadWorld(flags.Arg(0))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	out, err := os.Create(flags.Arg(1))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer out.Close()
+	if err := geojson.Write(out, geojson.Export(w, geojson.Options{Lat: lat, Lon: lon})); err != nil {
+		log.Print(err)
+		return 1
+	}
+	if err := out.Close(); err != nil {
+		log.Print(err)
+		return 1
+	}
+	fmt.Printf("%d nodes, %d roads written to %s\n", len(w.Nodes), len(w.Roads), flags.Arg(1))
+	return 0
+}
+
+func importGeoJSON(args []string) int {
+	flags := flag.NewFlagSet("import-geojson", flag.ExitOnError)
+	origin := flags.String("origin", "0,0", "latitude and longitude of the world origin")
+	into := flags.String("into", "", "save file to add the roads to instead of starting empty")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	lat, lon, err := geojson.ParseOrigin(*origin)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	w := world.New()
+	if *into != "" {
+		if w, err = loadWorld(*into); err != nil {
+			log.Print(err)
+			return 1
+		}
+	}
+	in, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer in.Close()
+	fc, err := geojson.Read(in)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	report, err := geojson.Import(w, fc, geojson.ImportOptions{Lat: lat, Lon: lon})
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("WARN     %s\n", warning)
+	}
+	if err := persistence.WriteSaveFile(flags.Arg(1), persistence.SerializeWorld(w)); err != nil {
+		log.Print(err)
+		return 1
+	}
+	fmt.Printf("%d nodes, %d roads added, %d features skipped, written to %s\n", report.Nodes, report.Roads, report.Skipped, flags.Arg(1))
+	return 0
+}
+
+func loadWorld(path string) (*world.World, error) {
+	saveData, _, err := readSave(path)
+	if err != nil {
+		return nil, err
+	}
+	return persistence.DeserializeWorld(saveData)
+}
+
+func readSave(path string) (*persistence.SaveFormat, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return persistence.ParseSaveData(data)
+}
+
+// saveFiles expands directories into the save files under them. Scenario
+// files and other JSON without a network are skipped; files named
+// explicitly are always included.
+func saveFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != ".json" || strings.Contains(filepath.Base(path), ".scenario.") {
+				return nil
+			}
+			if isSave(path) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+func isSave(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true // let the caller report it
+	}
+	var doc struct {
+		Version *string          `json:"version"`
+		Nodes   *json.RawMessage `json:"nodes"`
+	}
+	return json.Unmarshal(data, &doc) == nil && doc.Version != nil && doc.Nodes != nil
+}
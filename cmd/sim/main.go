@@ -1,16 +1,26 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
+	"traffic-sim/internal/api"
+	"traffic-sim/internal/autosave"
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/config"
 	"traffic-sim/internal/events"
+	"traffic-sim/internal/filedialog"
 	"traffic-sim/internal/input"
 	"traffic-sim/internal/renderer"
+	"traffic-sim/internal/replay"
+	"traffic-sim/internal/scenario"
 	"traffic-sim/internal/sim"
+	"traffic-sim/internal/triplog"
 	"traffic-sim/internal/world"
 )
 
@@ -20,21 +30,38 @@ type Game struct {
 	world        *world.World
 	InputHandler *input.InputHandler
 	lastTime     time.Time
+	cfg          *config.Config
+
+	tripLog      *triplog.Logger
+	unsubTrips   func()
+	apiServer    *api.Server
+	autosaver    *autosave.Autosaver
 }
 
 func (g *Game) Update() error {
 	mouseX, mouseY := ebiten.CursorPosition()
 	clicked := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
 	
+	now := time.Now()
+	dt := 0.0
+	if !g.lastTime.IsZero() {
+		dt = now.Sub(g.lastTime).Seconds()
+	}
+	g.lastTime = now
+	g.autosaver.Update(dt)
+
+	g.renderer.UpdateOverlays()
+	if g.renderer.Replaying() {
+		g.renderer.UpdateReplay(mouseX, mouseY, clicked, dt)
+		return nil
+	}
+	
 	g.renderer.Toolbar.Update(mouseX, mouseY, clicked)
 	g.InputHandler.Update()
 	
-	now := time.Now()
-	if !g.lastTime.IsZero() {
-		dt := now.Sub(g.lastTime).Seconds()
+	if dt > 0 {
 		g.simulator.UpdateOnce(dt)
 	}
-	g.lastTime = now
 	
 	return nil
 }
@@ -47,33 +74,118 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return g.renderer.Layout(outsideWidth, outsideHeight)
 }
 
-func (g *Game) replaceWorld(newWorld *world.World) {
+func (g *Game) replaceWorld(ev events.WorldLoadedEvent) {
 
-	g.world = newWorld
+	g.world = ev.World.(*world.World)
 
-	g.simulator = sim.NewSimulator(g.world, 8*time.Millisecond)
+	speed := g.simulator.Speed()
+	g.simulator = sim.NewSimulatorWithConfig(g.world, 8*time.Millisecond, g.cfg)
+	g.simulator.SetSpeed(speed)
 	g.simulator.ResetSystems()
+	g.attachScenario(ev.Path)
+	if ev.SystemState != nil {
+		if err := g.simulator.ImportSystemState(ev.SystemState); err != nil {
+			log.Printf("Failed to restore system state: %v", err)
+		}
+	}
+	g.simulator.SetPaused(ev.Paused)
 	
+	g.autosaver.Attach(g.world)
 	g.InputHandler.ReplaceWorld(g.world)
 	g.InputHandler.Simulator = g.simulator
 	
 	g.renderer.ReplaceWorld(g.world)
+	g.subscribeTripLog()
+	if g.apiServer != nil {
+		g.apiServer.SetSimulation(g.simulator)
+	}
 	
 	g.world.Events.Subscribe(events.EventWorldLoaded, func(p any) {
 		ev, ok := p.(events.WorldLoadedEvent)
 		if !ok {
 			return
 		}
-		g.replaceWorld(ev.World.(*world.World))
+		g.replaceWorld(ev)
 	})
 	
 	log.Println("World replaced successfully")
 }
 
+// attachScenario runs the scenario stored next to a save file, if any.
+func (g *Game) attachScenario(savePath string) {
+	if savePath == "" {
+		return
+	}
+
+	path, ok := scenario.FindFor(savePath)
+	if !ok {
+		return
+	}
+
+	sc, err := scenario.Load(path)
+	if err != nil {
+		log.Printf("Failed to load scenario %s: %v", path, err)
+		return
+	}
+
+	g.simulator.AddSystem(scenario.NewRunner(sc, g.simulator.Metrics()))
+	log.Printf("Scenario loaded from: %s (%d actions)", path, len(sc.Actions))
+}
+
+// offerRecovery asks whether to restore the newest autosave when it holds
+// edits made after the last save.
+func (g *Game) offerRecovery() {
+	if !g.autosaver.Enabled() {
+		return
+	}
+	latest, ok := autosave.Recoverable(autosave.Dir)
+	if !ok {
+		return
+	}
+
+	message := fmt.Sprintf("An autosave from %s has edits that were\nnot saved. Restore it?",
+		latest.ModTime.Format("2006-01-02 15:04:05"))
+	g.renderer.Toolbar.Confirm("Restore autosave", message, func() {
+		g.autosaver.Acknowledge()
+		g.InputHandler.LoadWorld(latest.Path)
+	}, g.autosaver.Acknowledge)
+}
+
+// subscribeTripLog moves the trip log, if configured, over to the current world.
+func (g *Game) subscribeTripLog() {
+	if g.tripLog == nil {
+		return
+	}
+	if g.unsubTrips != nil {
+		g.unsubTrips()
+	}
+	g.unsubTrips = g.tripLog.Subscribe(g.world.Events)
+}
+
 func main() {
+	loadPath := flag.String("load", "", "save file to open at startup")
+	paused := flag.Bool("paused", false, "start with the simulation paused")
+	speed := flag.Float64("speed", 1, "simulation speed as a multiple of real time")
+	configPath := flag.String("config", config.DefaultPath, "configuration file")
+	noDialogs := flag.Bool("no-dialogs", false, "pick files in the window instead of native dialogs")
+	repair := flag.Bool("repair", false, "repair what can be fixed safely in damaged saves as they are loaded")
+	flag.Parse()
+
+	if *speed <= 0 {
+		log.Fatalf("-speed must be positive, got %g", *speed)
+	}
+	filedialog.Disabled = *noDialogs
+
+	cfg, err := config.LoadConfigFrom(*configPath)
+	if err != nil {
+		log.Fatalf("Could not load config: %v", err)
+	}
+
 	w := world.New()
-	simulator := sim.NewSimulator(w, 8*time.Millisecond)
+	simulator := sim.NewSimulatorWithConfig(w, 8*time.Millisecond, cfg)
+	simulator.SetSpeed(*speed)
 	inputHandler := input.NewInputHandler(w, simulator)
+	inputHandler.RepairOnLoad = *repair
 
 	rend := renderer.NewRenderer(w, inputHandler)
 
@@ -82,6 +194,36 @@ func main() {
 		simulator:    simulator,
 		world:        w,
 		InputHandler: inputHandler,
+		cfg:          cfg,
+		autosaver:    autosave.New(autosave.Dir, cfg.Autosave),
+	}
+	game.autosaver.Attach(w)
+
+	rend.SetImageConfig(cfg.Images)
+	if cfg.Trips.Log != "" {
+		logger, err := triplog.NewLogger(cfg.Trips.Log)
+		if err != nil {
+			log.Printf("Failed to open trip log: %v", err)
+		} else {
+			game.tripLog = logger
+			defer logger.Close()
+			game.subscribeTripLog()
+		}
+	}
+
+	if cfg.API.Addr != "" {
+		server := api.NewServer(simulator)
+		if err := server.Start(cfg.API.Addr); err != nil {
+			log.Printf("Failed to start API server: %v", err)
+		} else {
+			game.apiServer = server
+			defer server.Close()
+			log.Printf("API listening on http://%s", server.Addr())
+		}
+	}
+
+	inputHandler.OnReplayOpened = func(player *replay.Player, path string) {
+		rend.StartReplay(player, path, rend.StopReplay)
 	}
 	
 	if w.Events != nil {
@@ -90,15 +232,28 @@ func main() {
 			if !ok {
 				return
 			}
-			game.replaceWorld(ev.World.(*world.World))
+			game.replaceWorld(ev)
 		})
 	}
 
+	if *loadPath != "" {
+		cmd := &commands.LoadWorldCommand{Path: *loadPath, Repair: *repair}
+		if err := cmd.Execute(w); err != nil {
+			log.Fatalf("Failed to load %s: %v", *loadPath, err)
+		}
+	}
+	if *paused {
+		game.simulator.SetPaused(true)
+	}
+	game.offerRecovery()
+
 	ebiten.SetWindowSize(1920, 1080)
 	ebiten.SetWindowTitle("Traffic Simulation")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
-	if err := ebiten.RunGame(game); err != nil {
+	err = ebiten.RunGame(game)
+	game.autosaver.Save()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
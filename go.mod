@@ -6,6 +6,8 @@ require (
 	github.com/hajimehoshi/ebiten/v2 v2.9.4
 	github.com/spf13/viper v1.21.0
 	github.com/sqweek/dialog v0.0.0-20240226140203-065105509627
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/image v0.31.0
 )
 
 require (
@@ -25,8 +27,6 @@ require (
 	github.com/spf13/cast v1.10.0 // indirect
 	github.com/spf13/pflag v1.0.10 // indirect
 	github.com/subosito/gotenv v1.6.0 // indirect
-	go.yaml.in/yaml/v3 v3.0.4 // indirect
-	golang.org/x/image v0.31.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
@@ -0,0 +1,484 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func (s *Server) state(sim Simulation) StateJSON {
+	w := sim.World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	return StateJSON{
+		SimTime:       w.SimTime,
+		Paused:        sim.IsPaused(),
+		TickRate:      sim.TickRate().Seconds(),
+		Nodes:         len(w.Nodes),
+		Roads:         len(w.Roads),
+		Vehicles:      len(w.Vehicles),
+		TrafficLights: len(w.TrafficLights),
+	}
+}
+
+func (s *Server) handleState(rw http.ResponseWriter, r *http.Request) {
+	writeJSON(rw, http.StatusOK, s.state(s.simulation()))
+}
+
+func (s *Server) handleNodes(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	nodes := make([]NodeJSON, 0, len(w.Nodes))
+	for _, n := range w.Nodes {
+		nodes = append(nodes, nodeJSON(n))
+	}
+	writeJSON(rw, http.StatusOK, nodes)
+}
+
+func (s *Server) handleRoads(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	counts := make(map[*road.Road]int)
+	for _, v := range w.Vehicles {
+		counts[v.Road]++
+	}
+	roads := make([]RoadJSON, 0, len(w.Roads))
+	for _, rd := range w.Roads {
+		roads = append(roads, roadJSON(w, rd, counts[rd]))
+	}
+	writeJSON(rw, http.StatusOK, roads)
+}
+
+func (s *Server) handleVehicles(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	vehicles := make([]VehicleJSON, 0, len(w.Vehicles))
+	for _, v := range w.Vehicles {
+		vehicles = append(vehicles, vehicleJSON(v))
+	}
+	writeJSON(rw, http.StatusOK, vehicles)
+}
+
+func (s *Server) handleLights(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	lights := make([]LightJSON, 0, len(w.TrafficLights))
+	for _, tl := range w.TrafficLights {
+		lights = append(lights, lightJSON(tl))
+	}
+	writeJSON(rw, http.StatusOK, lights)
+}
+
+func (s *Server) handleSpawnPoints(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	points := make([]SpawnPointJSON, 0, len(w.SpawnPoints))
+	for _, sp := range w.SpawnPoints {
+		points = append(points, spawnPointJSON(sp))
+	}
+	writeJSON(rw, http.StatusOK, points)
+}
+
+func (s *Server) handleDespawnPoints(rw http.ResponseWriter, r *http.Request) {
+	w := s.simulation().World()
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	points := make([]DespawnPointJSON, 0, len(w.DespawnPoints))
+	for _, dp := range w.DespawnPoints {
+		points = append(points, despawnPointJSON(dp))
+	}
+	writeJSON(rw, http.StatusOK, points)
+}
+
+// handleRoadMetrics returns the latest closed interval of every road, every
+// interval with ?all=1, or one road's intervals with ?road=<id>.
+func (s *Server) handleRoadMetrics(rw http.ResponseWriter, r *http.Request) {
+	collector := s.simulation().Metrics()
+	switch {
+	case r.URL.Query().Get("road") != "":
+		writeJSON(rw, http.StatusOK, collector.ForRoad(r.URL.Query().Get("road")))
+	case r.URL.Query().Get("all") != "":
+		writeJSON(rw, http.StatusOK, collector.Intervals())
+	default:
+		writeJSON(rw, http.StatusOK, collector.Latest())
+	}
+}
+
+func (s *Server) handleDetectorMetrics(rw http.ResponseWriter, r *http.Request) {
+	collector := s.simulation().Metrics()
+	if id := r.URL.Query().Get("detector"); id != "" {
+		writeJSON(rw, http.StatusOK, collector.ForDetector(id))
+		return
+	}
+	writeJSON(rw, http.StatusOK, collector.DetectorIntervals())
+}
+
+func (s *Server) handleIntersectionMetrics(rw http.ResponseWriter, r *http.Request) {
+	sim := s.simulation()
+	if id := r.URL.Query().Get("node"); id != "" {
+		report := sim.Intersections().Report(sim.World(), id)
+		if report == nil {
+			writeError(rw, http.StatusNotFound, "no intersection at node %q", id)
+			return
+		}
+		writeJSON(rw, http.StatusOK, report)
+		return
+	}
+	writeJSON(rw, http.StatusOK, sim.Intersections().Reports(sim.World()))
+}
+
+// handleSeries returns the sampled network time series, or one road's
+// series with ?road=<id>.
+func (s *Server) handleSeries(rw http.ResponseWriter, r *http.Request) {
+	sampler := s.simulation().Sampler()
+	if id := r.URL.Query().Get("road"); id != "" {
+		writeJSON(rw, http.StatusOK, sampler.Road(id))
+		return
+	}
+	writeJSON(rw, http.StatusOK, sampler.Samples())
+}
+
+type createNodeRequest struct {
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+func (s *Server) handleCreateNode(rw http.ResponseWriter, r *http.Request) {
+	var req createNodeRequest
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	if req.ID == "" {
+		req.ID = s.nextNodeID(w)
+	}
+	exists := findNode(w, req.ID) != nil
+	w.Mu.RUnlock()
+	if exists {
+		writeError(rw, http.StatusConflict, "node %q already exists", req.ID)
+		return
+	}
+
+	if err := s.execute(w, &commands.CreateNodeCommand{X: req.X, Y: req.Y, NodeID: req.ID}); err != nil {
+		writeError(rw, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(rw, http.StatusCreated, NodeJSON{ID: req.ID, X: req.X, Y: req.Y})
+}
+
+// nextNodeID picks an unused ID for a node created without one. The caller
+// holds the world lock.
+func (s *Server) nextNodeID(w *world.World) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		s.nodeID++
+		id := fmt.Sprintf("api%d", s.nodeID)
+		if findNode(w, id) == nil {
+			return id
+		}
+	}
+}
+
+func vehiclesOn(w *world.World, rd *road.Road) int {
+	count := 0
+	for _, v := range w.Vehicles {
+		if v.Road == rd {
+			count++
+		}
+	}
+	return count
+}
+
+func findNode(w *world.World, id string) *road.Node {
+	for _, n := range w.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+type moveNodeRequest struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func (s *Server) handleMoveNode(rw http.ResponseWriter, r *http.Request) {
+	var req moveNodeRequest
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	node := findNode(w, r.PathValue("id"))
+	w.Mu.RUnlock()
+	if node == nil {
+		writeError(rw, http.StatusNotFound, "node %q not found", r.PathValue("id"))
+		return
+	}
+
+	if err := s.execute(w, &commands.MoveNodeCommand{Node: node, NewX: req.X, NewY: req.Y}); err != nil {
+		writeError(rw, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, NodeJSON{ID: node.ID, X: req.X, Y: req.Y})
+}
+
+type createRoadRequest struct {
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	MaxSpeed      float64 `json:"maxSpeed"`
+	Width         float64 `json:"width"`
+	Bidirectional bool    `json:"bidirectional"`
+}
+
+// handleCreateRoad builds a road between two existing nodes, and its
+// reverse when bidirectional is set, with the editor's defaults for any
+// speed or width left out.
+func (s *Server) handleCreateRoad(rw http.ResponseWriter, r *http.Request) {
+	req := createRoadRequest{MaxSpeed: 40.0, Width: 8.0}
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if req.MaxSpeed <= 0 || req.Width <= 0 {
+		writeError(rw, http.StatusBadRequest, "maxSpeed and width must be positive")
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	from, to := findNode(w, req.From), findNode(w, req.To)
+	var pairs [][2]*road.Node
+	if from != nil && to != nil {
+		pairs = append(pairs, [2]*road.Node{from, to})
+		if req.Bidirectional && from != to && w.FindRoad(fmt.Sprintf("%s-%s", to.ID, from.ID)) == nil {
+			pairs = append(pairs, [2]*road.Node{to, from})
+		}
+	}
+	exists := from != nil && to != nil && w.FindRoad(fmt.Sprintf("%s-%s", from.ID, to.ID)) != nil
+	w.Mu.RUnlock()
+
+	switch {
+	case from == nil:
+		writeError(rw, http.StatusNotFound, "node %q not found", req.From)
+		return
+	case to == nil:
+		writeError(rw, http.StatusNotFound, "node %q not found", req.To)
+		return
+	case exists:
+		writeError(rw, http.StatusConflict, "road %s-%s already exists", from.ID, to.ID)
+		return
+	}
+
+	for _, p := range pairs {
+		cmd := &commands.CreateRoadCommand{From: p[0], To: p[1], MaxSpeed: req.MaxSpeed, Width: req.Width}
+		if err := s.execute(w, cmd); err != nil {
+			writeError(rw, http.StatusInternalServerError, "%v", err)
+			return
+		}
+	}
+
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+	created := make([]RoadJSON, 0, len(pairs))
+	for _, p := range pairs {
+		if rd := w.FindRoad(fmt.Sprintf("%s-%s", p[0].ID, p[1].ID)); rd != nil {
+			created = append(created, roadJSON(w, rd, vehiclesOn(w, rd)))
+		}
+	}
+	writeJSON(rw, http.StatusCreated, created)
+}
+
+type updateRoadRequest struct {
+	MaxSpeed float64 `json:"maxSpeed"`
+	Width    float64 `json:"width"`
+}
+
+func (s *Server) handleUpdateRoad(rw http.ResponseWriter, r *http.Request) {
+	var req updateRoadRequest
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	rd := w.FindRoad(r.PathValue("id"))
+	w.Mu.RUnlock()
+	if rd == nil {
+		writeError(rw, http.StatusNotFound, "road %q not found", r.PathValue("id"))
+		return
+	}
+
+	if err := s.execute(w, &commands.UpdateRoadPropertiesCommand{Road: rd, MaxSpeed: req.MaxSpeed, Width: req.Width}); err != nil {
+		writeError(rw, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+	writeJSON(rw, http.StatusOK, roadJSON(w, rd, vehiclesOn(w, rd)))
+}
+
+// updateSpawnPointRequest uses pointers so fields left out keep their
+// current value.
+type updateSpawnPointRequest struct {
+	Enabled     *bool    `json:"enabled"`
+	Interval    *float64 `json:"interval"`
+	MinSpeed    *float64 `json:"minSpeed"`
+	MaxSpeed    *float64 `json:"maxSpeed"`
+	MaxVehicles *int     `json:"maxVehicles"`
+}
+
+func (s *Server) handleUpdateSpawnPoint(rw http.ResponseWriter, r *http.Request) {
+	var req updateSpawnPointRequest
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	sp := w.FindSpawnPoint(r.PathValue("id"))
+	var cmd *commands.UpdateSpawnPointPropertiesCommand
+	if sp != nil {
+		cmd = &commands.UpdateSpawnPointPropertiesCommand{
+			SpawnPoint:  sp,
+			Interval:    sp.Interval,
+			MinSpeed:    sp.MinSpeed,
+			MaxSpeed:    sp.MaxSpeed,
+			MaxVehicles: sp.MaxVehicles,
+			Enabled:     sp.Enabled,
+		}
+	}
+	w.Mu.RUnlock()
+	if sp == nil {
+		writeError(rw, http.StatusNotFound, "spawn point %q not found", r.PathValue("id"))
+		return
+	}
+
+	if req.Enabled != nil {
+		cmd.Enabled = *req.Enabled
+	}
+	if req.Interval != nil {
+		cmd.Interval = *req.Interval
+	}
+	if req.MinSpeed != nil {
+		cmd.MinSpeed = *req.MinSpeed
+	}
+	if req.MaxSpeed != nil {
+		cmd.MaxSpeed = *req.MaxSpeed
+	}
+	if req.MaxVehicles != nil {
+		cmd.MaxVehicles = *req.MaxVehicles
+	}
+	if err := s.execute(w, cmd); err != nil {
+		writeError(rw, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+	writeJSON(rw, http.StatusOK, spawnPointJSON(sp))
+}
+
+// updateDespawnPointRequest has a single field, so it must be given: an
+// empty body would otherwise read as disabling the despawn point.
+type updateDespawnPointRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+func (s *Server) handleUpdateDespawnPoint(rw http.ResponseWriter, r *http.Request) {
+	var req updateDespawnPointRequest
+	if err := decode(rw, r, &req); err != nil {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if req.Enabled == nil {
+		writeError(rw, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	w := s.simulation().World()
+	w.Mu.RLock()
+	dp := w.FindDespawnPoint(r.PathValue("id"))
+	w.Mu.RUnlock()
+	if dp == nil {
+		writeError(rw, http.StatusNotFound, "despawn point %q not found", r.PathValue("id"))
+		return
+	}
+
+	if err := s.execute(w, &commands.SetDespawnPointEnabledCommand{DespawnPoint: dp, Enabled: *req.Enabled}); err != nil {
+		writeError(rw, http.StatusInternalServerError, "%v", err)
+		return
+	}
+
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+	writeJSON(rw, http.StatusOK, despawnPointJSON(dp))
+}
+
+func (s *Server) handlePause(rw http.ResponseWriter, r *http.Request) {
+	sim := s.simulation()
+	sim.SetPaused(true)
+	writeJSON(rw, http.StatusOK, s.state(sim))
+}
+
+func (s *Server) handleResume(rw http.ResponseWriter, r *http.Request) {
+	sim := s.simulation()
+	sim.SetPaused(false)
+	writeJSON(rw, http.StatusOK, s.state(sim))
+}
+
+type stepRequest struct {
+	Ticks int `json:"ticks"`
+}
+
+// handleStep advances the simulation by a number of fixed ticks, one by
+// default, whether or not it is paused.
+func (s *Server) handleStep(rw http.ResponseWriter, r *http.Request) {
+	req := stepRequest{Ticks: 1}
+	if err := decode(rw, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(rw, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if req.Ticks < 1 || req.Ticks > maxStepTicks {
+		writeError(rw, http.StatusBadRequest, "ticks must be between 1 and %d", maxStepTicks)
+		return
+	}
+
+	sim := s.simulation()
+	for i := 0; i < req.Ticks; i++ {
+		if r.Context().Err() != nil {
+			break
+		}
+		sim.Step()
+	}
+	writeJSON(rw, http.StatusOK, s.state(sim))
+}
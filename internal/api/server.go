@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/systems"
+	"traffic-sim/internal/world"
+)
+
+// Simulation is the part of sim.Simulator the server drives.
+type Simulation interface {
+	World() *world.World
+	Step()
+	IsPaused() bool
+	SetPaused(paused bool)
+	TickRate() time.Duration
+	Metrics() *metrics.Collector
+	Intersections() *metrics.IntersectionMonitor
+	Sampler() *metrics.Sampler
+	AddSystem(system systems.System)
+	RemoveSystem(system systems.System)
+}
+
+// maxStepTicks bounds a single step request so one call cannot stall the
+// simulation for minutes.
+const maxStepTicks = 100000
+
+// Server is a JSON API over HTTP for reading the world and metrics and for
+// editing and controlling the simulation, plus a websocket stream of frames
+// and events. Edits go through commands.CommandExecutor, so they take the
+// world lock the same way the editor tools do. It only listens on loopback
+// addresses.
+type Server struct {
+	mu     sync.RWMutex
+	sim    Simulation
+	nodeID int
+	stream *Stream
+
+	mux     *http.ServeMux
+	handler http.Handler
+	http    *http.Server
+	addr    string
+}
+
+func NewServer(s Simulation) *Server {
+	srv := &Server{sim: s, stream: NewStream(), mux: http.NewServeMux()}
+	s.AddSystem(srv.stream)
+	srv.stream.attach(s.World())
+	srv.routes()
+	srv.handler = guard(srv.mux)
+	return srv
+}
+
+// SetSimulation points the server at a new simulation, e.g. after a world
+// is loaded in the editor.
+func (s *Server) SetSimulation(sim Simulation) {
+	s.mu.Lock()
+	old := s.sim
+	s.sim = sim
+	s.mu.Unlock()
+
+	old.RemoveSystem(s.stream)
+	sim.AddSystem(s.stream)
+	s.stream.attach(sim.World())
+}
+
+func (s *Server) simulation() Simulation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sim
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Start listens on addr and serves in the background. An address without a
+// host binds to 127.0.0.1; anything that is not a loopback address is
+// rejected.
+func (s *Server) Start(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid API address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if !loopbackHost(host) {
+		return fmt.Errorf("API address %q is not a loopback address", addr)
+	}
+
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+	s.addr = ln.Addr().String()
+	s.http = &http.Server{Handler: s.handler, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Addr is the address the server listens on once started.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+func (s *Server) Close() error {
+	s.stream.closeAll()
+	if s.http == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/state", s.handleState)
+	s.mux.HandleFunc("GET /api/nodes", s.handleNodes)
+	s.mux.HandleFunc("GET /api/roads", s.handleRoads)
+	s.mux.HandleFunc("GET /api/vehicles", s.handleVehicles)
+	s.mux.HandleFunc("GET /api/lights", s.handleLights)
+	s.mux.HandleFunc("GET /api/spawn-points", s.handleSpawnPoints)
+	s.mux.HandleFunc("GET /api/despawn-points", s.handleDespawnPoints)
+
+	s.mux.HandleFunc("GET /api/metrics/roads", s.handleRoadMetrics)
+	s.mux.HandleFunc("GET /api/metrics/detectors", s.handleDetectorMetrics)
+	s.mux.HandleFunc("GET /api/metrics/intersections", s.handleIntersectionMetrics)
+	s.mux.HandleFunc("GET /api/metrics/series", s.handleSeries)
+
+	s.mux.HandleFunc("POST /api/nodes", s.handleCreateNode)
+	s.mux.HandleFunc("PATCH /api/nodes/{id}", s.handleMoveNode)
+	s.mux.HandleFunc("POST /api/roads", s.handleCreateRoad)
+	s.mux.HandleFunc("PATCH /api/roads/{id}", s.handleUpdateRoad)
+	s.mux.HandleFunc("PATCH /api/spawn-points/{id}", s.handleUpdateSpawnPoint)
+	s.mux.HandleFunc("PATCH /api/despawn-points/{id}", s.handleUpdateDespawnPoint)
+
+	s.mux.HandleFunc("POST /api/sim/pause", s.handlePause)
+	s.mux.HandleFunc("POST /api/sim/resume", s.handleResume)
+	s.mux.HandleFunc("POST /api/sim/step", s.handleStep)
+
+	s.mux.HandleFunc("GET /api/stream", s.handleStream)
+}
+
+// guard keeps web pages out of the API. Listening on loopback alone does
+// not: any page the user has open can send requests to 127.0.0.1, and DNS
+// rebinding lets a page read the responses under its own host name. So the
+// Host must name a loopback address, an Origin, if sent, must be this
+// server, and requests that change anything must be JSON, which a page
+// cannot send to another origin without a preflight.
+func guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !loopbackHost(host) {
+			writeError(rw, http.StatusForbidden, "host %q is not a loopback address", r.Host)
+			return
+		}
+		if !sameOrigin(r) {
+			writeError(rw, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeError(rw, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+				return
+			}
+		}
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// loopbackHost reports whether host, without a port, is localhost or a
+// loopback IP.
+func loopbackHost(host string) bool {
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sameOrigin reports whether r carries no Origin header or one naming the
+// host and port it was sent to. Browsers always send Origin on cross-origin
+// requests and websocket handshakes; other clients usually send none.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// execute runs a command against the current world with the executor's
+// usual locking.
+func (s *Server) execute(w *world.World, cmd commands.Command) error {
+	return commands.NewCommandExecutor(w).Execute(cmd)
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
+
+func writeError(rw http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(rw, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func decode(rw http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(rw, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
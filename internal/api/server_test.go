@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/systems"
+	"traffic-sim/internal/world"
+)
+
+type fakeSim struct {
+	world   *world.World
+	paused  bool
+	steps   int
+	systems []systems.System
+}
+
+func (f *fakeSim) World() *world.World { return f.world }
+func (f *fakeSim) Step() {
+	f.steps++
+	f.world.SimTime += 0.1
+	for _, s := range f.systems {
+		s.Update(f.world, 0.1)
+	}
+}
+func (f *fakeSim) IsPaused() bool              { return f.paused }
+func (f *fakeSim) SetPaused(paused bool)       { f.paused = paused }
+func (f *fakeSim) TickRate() time.Duration     { return 100 * time.Millisecond }
+func (f *fakeSim) Metrics() *metrics.Collector { return metrics.NewCollector(60) }
+func (f *fakeSim) Intersections() *metrics.IntersectionMonitor {
+	return metrics.NewIntersectionMonitor()
+}
+func (f *fakeSim) Sampler() *metrics.Sampler  { return metrics.NewSampler(1, 60) }
+func (f *fakeSim) AddSystem(s systems.System) { f.systems = append(f.systems, s) }
+func (f *fakeSim) RemoveSystem(s systems.System) {
+	for i, existing := range f.systems {
+		if existing == s {
+			f.systems = append(f.systems[:i], f.systems[i+1:]...)
+			return
+		}
+	}
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string, want int, out any) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Host = "127.0.0.1:8080"
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != want {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, want, rec.Code, rec.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: bad response: %v", method, path, err)
+		}
+	}
+}
+
+func TestServerEditsWorld(t *testing.T) {
+	w := world.New()
+	h := NewServer(&fakeSim{world: w}).Handler()
+
+	var node NodeJSON
+	do(t, h, "POST", "/api/nodes", `{"x": 10, "y": 20}`, http.StatusCreated, &node)
+	if node.ID == "" || node.X != 10 {
+		t.Errorf("unexpected node %+v", node)
+	}
+	do(t, h, "POST", "/api/nodes", `{"id": "b", "x": 210, "y": 20}`, http.StatusCreated, nil)
+	do(t, h, "POST", "/api/nodes", `{"id": "b"}`, http.StatusConflict, nil)
+
+	var created []RoadJSON
+	do(t, h, "POST", "/api/roads", `{"from": "`+node.ID+`", "to": "b", "bidirectional": true}`, http.StatusCreated, &created)
+	if len(created) != 2 || created[0].MaxSpeed != 40 || created[0].Reverse != created[1].ID {
+		t.Fatalf("expected a bidirectional pair with default speed, got %+v", created)
+	}
+	do(t, h, "POST", "/api/roads", `{"from": "`+node.ID+`", "to": "b"}`, http.StatusConflict, nil)
+	do(t, h, "POST", "/api/roads", `{"from": "nope", "to": "b"}`, http.StatusNotFound, nil)
+
+	var updated RoadJSON
+	do(t, h, "PATCH", "/api/roads/"+created[0].ID, `{"maxSpeed": 15}`, http.StatusOK, &updated)
+	if updated.MaxSpeed != 15 || updated.Width != 8 {
+		t.Errorf("expected only the speed to change, got %+v", updated)
+	}
+
+	do(t, h, "PATCH", "/api/nodes/b", `{"x": 110, "y": 20}`, http.StatusOK, nil)
+	if rd := w.FindRoad(created[0].ID); rd.Length != 100 {
+		t.Errorf("expected road length to follow the moved node, got %f", rd.Length)
+	}
+
+	var roads []RoadJSON
+	do(t, h, "GET", "/api/roads", "", http.StatusOK, &roads)
+	if len(roads) != 2 {
+		t.Errorf("expected 2 roads, got %d", len(roads))
+	}
+	do(t, h, "POST", "/api/roads", `{"from": "b", "to": "b", "color": "red"}`, http.StatusBadRequest, nil)
+}
+
+func TestServerUpdatesSpawnPoint(t *testing.T) {
+	w := world.New()
+	n1 := &road.Node{ID: "n1"}
+	n2 := &road.Node{ID: "n2", X: 100}
+	rd := road.NewRoad("r1", n1, n2, 20)
+	sp := road.NewSpawnPoint("sp1", n1, rd)
+	sp.Interval = 3
+	w.Nodes = append(w.Nodes, n1, n2)
+	w.Roads = append(w.Roads, rd)
+	w.SpawnPoints = append(w.SpawnPoints, sp)
+	h := NewServer(&fakeSim{world: w}).Handler()
+
+	var got SpawnPointJSON
+	do(t, h, "PATCH", "/api/spawn-points/sp1", `{"enabled": false}`, http.StatusOK, &got)
+	if got.Enabled || got.Interval != 3 {
+		t.Errorf("expected spawn point disabled with its interval kept, got %+v", got)
+	}
+	do(t, h, "PATCH", "/api/spawn-points/missing", `{"enabled": true}`, http.StatusNotFound, nil)
+}
+
+func TestServerUpdatesDespawnPoint(t *testing.T) {
+	w := world.New()
+	n1 := &road.Node{ID: "n1"}
+	n2 := &road.Node{ID: "n2", X: 100}
+	rd := road.NewRoad("r1", n1, n2, 20)
+	dp := &road.DespawnPoint{ID: "dp1", Node: n2, Road: rd, Enabled: true}
+	w.Nodes = append(w.Nodes, n1, n2)
+	w.Roads = append(w.Roads, rd)
+	w.DespawnPoints = append(w.DespawnPoints, dp)
+	h := NewServer(&fakeSim{world: w}).Handler()
+
+	do(t, h, "PATCH", "/api/despawn-points/dp1", `{}`, http.StatusBadRequest, nil)
+	if !dp.Enabled {
+		t.Fatal("expected a body without enabled to leave the despawn point alone")
+	}
+
+	var got DespawnPointJSON
+	do(t, h, "PATCH", "/api/despawn-points/dp1", `{"enabled": false}`, http.StatusOK, &got)
+	if got.Enabled || dp.Enabled {
+		t.Errorf("expected despawn point disabled, got %+v", got)
+	}
+}
+
+func TestServerControlsSimulation(t *testing.T) {
+	fake := &fakeSim{world: world.New()}
+	h := NewServer(fake).Handler()
+
+	var state StateJSON
+	do(t, h, "POST", "/api/sim/pause", "", http.StatusOK, &state)
+	if !state.Paused {
+		t.Errorf("expected paused state")
+	}
+
+	do(t, h, "POST", "/api/sim/step", "", http.StatusOK, nil)
+	do(t, h, "POST", "/api/sim/step", `{"ticks": 4}`, http.StatusOK, &state)
+	if fake.steps != 5 {
+		t.Errorf("expected 5 steps, got %d", fake.steps)
+	}
+	do(t, h, "POST", "/api/sim/step", `{"ticks": 0}`, http.StatusBadRequest, nil)
+
+	do(t, h, "POST", "/api/sim/resume", "", http.StatusOK, &state)
+	if state.Paused {
+		t.Errorf("expected running state")
+	}
+}
+
+func TestServerRejectsNonLoopback(t *testing.T) {
+	s := NewServer(&fakeSim{world: world.New()})
+	if err := s.Start("0.0.0.0:0"); err == nil {
+		s.Close()
+		t.Fatal("expected a non-loopback address to be rejected")
+	}
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start on loopback: %v", err)
+	}
+	s.Close()
+}
+
+func TestServerRejectsBrowserRequests(t *testing.T) {
+	h := NewServer(&fakeSim{world: world.New()}).Handler()
+
+	tests := []struct {
+		name, method, host, origin, contentType string
+		want                                    int
+	}{
+		{"loopback read", "GET", "127.0.0.1:8080", "", "", http.StatusOK},
+		{"localhost read", "GET", "localhost:8080", "", "", http.StatusOK},
+		{"same-origin read", "GET", "127.0.0.1:8080", "http://127.0.0.1:8080", "", http.StatusOK},
+		{"rebound host", "GET", "evil.example:8080", "", "", http.StatusForbidden},
+		{"cross-origin read", "GET", "127.0.0.1:8080", "http://evil.example", "", http.StatusForbidden},
+		{"null origin", "GET", "127.0.0.1:8080", "null", "", http.StatusForbidden},
+		{"cross-origin edit", "POST", "127.0.0.1:8080", "http://evil.example", "application/json", http.StatusForbidden},
+		{"form edit", "POST", "127.0.0.1:8080", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"edit without type", "POST", "127.0.0.1:8080", "", "", http.StatusUnsupportedMediaType},
+		{"json edit", "POST", "127.0.0.1:8080", "", "application/json; charset=utf-8", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/nodes", bytes.NewBufferString(`{"x": 1, "y": 2}`))
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
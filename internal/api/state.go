@@ -0,0 +1,182 @@
+package api
+
+import (
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+// The types below are the JSON shapes the API returns. World objects point
+// at each other, so they are flattened to IDs here.
+
+type StateJSON struct {
+	SimTime       float64 `json:"simTime"`
+	Paused        bool    `json:"paused"`
+	TickRate      float64 `json:"tickRate"`
+	Nodes         int     `json:"nodes"`
+	Roads         int     `json:"roads"`
+	Vehicles      int     `json:"vehicles"`
+	TrafficLights int     `json:"trafficLights"`
+}
+
+type NodeJSON struct {
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+type RoadJSON struct {
+	ID       string  `json:"id"`
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	MaxSpeed float64 `json:"maxSpeed"`
+	Width    float64 `json:"width"`
+	Length   float64 `json:"length"`
+	Reverse  string  `json:"reverse,omitempty"`
+	Curved   bool    `json:"curved"`
+	Vehicles int     `json:"vehicles"`
+	Closed   bool    `json:"closed"`
+}
+
+type VehicleJSON struct {
+	ID           string  `json:"id"`
+	Emergency    bool    `json:"emergency"`
+	Road         string  `json:"road"`
+	NextRoad     string  `json:"nextRoad,omitempty"`
+	Distance     float64 `json:"distance"`
+	Speed        float64 `json:"speed"`
+	X            float64 `json:"x"`
+	Y            float64 `json:"y"`
+	InTransition bool    `json:"inTransition"`
+	Origin       string  `json:"origin,omitempty"`
+	Destination  string  `json:"destination,omitempty"`
+	SpawnTime    float64 `json:"spawnTime"`
+}
+
+type LightJSON struct {
+	ID           string   `json:"id"`
+	Intersection string   `json:"intersection"`
+	State        string   `json:"state"`
+	Timer        float64  `json:"timer"`
+	GreenTime    float64  `json:"greenTime"`
+	YellowTime   float64  `json:"yellowTime"`
+	RedTime      float64  `json:"redTime"`
+	Enabled      bool     `json:"enabled"`
+	Preempted    bool     `json:"preempted"`
+	Roads        []string `json:"roads"`
+}
+
+type SpawnPointJSON struct {
+	ID          string  `json:"id"`
+	Node        string  `json:"node"`
+	Road        string  `json:"road"`
+	Interval    float64 `json:"interval"`
+	MinSpeed    float64 `json:"minSpeed"`
+	MaxSpeed    float64 `json:"maxSpeed"`
+	MaxVehicles int     `json:"maxVehicles"`
+	Enabled     bool    `json:"enabled"`
+}
+
+type DespawnPointJSON struct {
+	ID      string `json:"id"`
+	Node    string `json:"node"`
+	Road    string `json:"road"`
+	Enabled bool   `json:"enabled"`
+}
+
+// The builders below expect the caller to hold the world lock.
+
+func nodeJSON(n *road.Node) NodeJSON {
+	return NodeJSON{ID: n.ID, X: n.X, Y: n.Y}
+}
+
+func roadJSON(w *world.World, rd *road.Road, vehicles int) RoadJSON {
+	r := RoadJSON{
+		ID:       rd.ID,
+		From:     rd.From.ID,
+		To:       rd.To.ID,
+		MaxSpeed: rd.MaxSpeed,
+		Width:    rd.Width,
+		Length:   rd.Length,
+		Curved:   rd.Curve != nil,
+		Vehicles: vehicles,
+		Closed:   w.IsRoadClosed(rd),
+	}
+	if rd.ReverseRoad != nil {
+		r.Reverse = rd.ReverseRoad.ID
+	}
+	return r
+}
+
+func vehicleJSON(v *vehicle.Vehicle) VehicleJSON {
+	vj := VehicleJSON{
+		ID:           v.ID,
+		Emergency:    v.IsEmergency(),
+		Road:         v.Road.ID,
+		Distance:     v.Distance,
+		Speed:        v.Speed,
+		X:            v.Pos.X,
+		Y:            v.Pos.Y,
+		InTransition: v.InTransition,
+		SpawnTime:    v.SpawnTime,
+	}
+	if v.NextRoad != nil {
+		vj.NextRoad = v.NextRoad.ID
+	}
+	if v.Origin != nil {
+		vj.Origin = v.Origin.ID
+	}
+	if v.TargetDespawn != nil {
+		vj.Destination = v.TargetDespawn.ID
+	}
+	return vj
+}
+
+func lightJSON(tl *road.TrafficLight) LightJSON {
+	lj := LightJSON{
+		ID:         tl.ID,
+		State:      lightStateName(tl.State),
+		Timer:      tl.Timer,
+		GreenTime:  tl.GreenTime,
+		YellowTime: tl.YellowTime,
+		RedTime:    tl.RedTime,
+		Enabled:    tl.Enabled,
+		Preempted:  tl.Preempted,
+		Roads:      make([]string, 0, len(tl.ControlledRoads)),
+	}
+	if tl.Intersection != nil {
+		lj.Intersection = tl.Intersection.ID
+	}
+	for _, rd := range tl.ControlledRoads {
+		lj.Roads = append(lj.Roads, rd.ID)
+	}
+	return lj
+}
+
+func lightStateName(s road.LightState) string {
+	switch s {
+	case road.LightGreen:
+		return "green"
+	case road.LightYellow:
+		return "yellow"
+	default:
+		return "red"
+	}
+}
+
+func spawnPointJSON(sp *road.SpawnPoint) SpawnPointJSON {
+	return SpawnPointJSON{
+		ID:          sp.ID,
+		Node:        sp.Node.ID,
+		Road:        sp.Road.ID,
+		Interval:    sp.Interval,
+		MinSpeed:    sp.MinSpeed,
+		MaxSpeed:    sp.MaxSpeed,
+		MaxVehicles: sp.MaxVehicles,
+		Enabled:     sp.Enabled,
+	}
+}
+
+func despawnPointJSON(dp *road.DespawnPoint) DespawnPointJSON {
+	return DespawnPointJSON{ID: dp.ID, Node: dp.Node.ID, Road: dp.Road.ID, Enabled: dp.Enabled}
+}
@@ -0,0 +1,395 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// Stream messages are JSON text messages of two kinds:
+//
+//	{"type":"frame","t":12.5,"vehicles":[["v1",120.4,80.0,1.571,13.42,0],...],"lights":[["tl1","green"],...]}
+//	{"type":"event","name":"road.created","t":12.5,"data":{...}}
+//
+// A vehicle is [id, x, y, heading, speed, emergency]: heading in radians
+// with 0 along +x, speed in m/s and emergency as 0 or 1.
+
+const (
+	defaultStreamInterval = 0.1
+	streamQueue           = 64
+)
+
+// streamedEvents are the dispatcher events mirrored to stream clients.
+var streamedEvents = []string{
+	events.EventRoadCreated,
+	events.EventRoadDeleted,
+	events.EventNodeCreated,
+	events.EventNodeDeleted,
+	events.EventWorldLoaded,
+	events.EventSpawnPointCreated,
+	events.EventDespawnPointCreated,
+	events.EventTrafficLightCreated,
+	events.EventRoadPropertiesUpdated,
+	events.EventEmergencyDispatched,
+	events.EventEmergencyArrived,
+	events.EventSpeedZoneCreated,
+	events.EventSpeedZoneRemoved,
+	events.EventSpeedZoneUpdated,
+	events.EventIncidentCreated,
+	events.EventIncidentCleared,
+	events.EventIncidentStarted,
+	events.EventIncidentEnded,
+	events.EventSnapshotTaken,
+	events.EventTripCompleted,
+	events.EventDetectorCreated,
+	events.EventDetectorRemoved,
+}
+
+type streamClient struct {
+	out      chan []byte
+	interval float64
+	next     float64
+	events   bool
+	frames   bool
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (c *streamClient) drop() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// Stream is a system that pushes vehicle and signal frames to websocket
+// clients, each decimated to the client's own interval of sim time, and
+// mirrors world-change events from the dispatcher. Frames are only built
+// while someone is listening. A client too slow to keep up misses frames;
+// one that falls behind on events is disconnected.
+type Stream struct {
+	mu      sync.Mutex
+	clients map[*streamClient]struct{}
+	world   *world.World
+	unsubs  []func()
+
+	scratch []byte
+}
+
+func NewStream() *Stream {
+	return &Stream{clients: make(map[*streamClient]struct{})}
+}
+
+// attach mirrors the events of a new world in place of the old one.
+func (s *Stream) attach(w *world.World) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, unsub := range s.unsubs {
+		unsub()
+	}
+	s.unsubs = nil
+	s.world = w
+	for c := range s.clients {
+		c.next = 0
+	}
+	if w.Events == nil {
+		return
+	}
+
+	for _, name := range streamedEvents {
+		s.unsubs = append(s.unsubs, w.Events.Subscribe(name, func(payload any) {
+			s.publishEvent(w, name, payload)
+		}))
+	}
+}
+
+func (s *Stream) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		c.next = 0
+	}
+}
+
+// Update never holds the stream lock while taking the world lock: event
+// handlers run with the world lock held and take the stream lock.
+func (s *Stream) Update(w *world.World, dt float64) {
+	w.Mu.RLock()
+	now := w.SimTime
+	w.Mu.RUnlock()
+
+	s.mu.Lock()
+	due := make([]*streamClient, 0, len(s.clients))
+	for c := range s.clients {
+		if c.frames && now >= c.next {
+			due = append(due, c)
+			c.next = now + c.interval
+		}
+	}
+	s.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	msg := s.buildFrame(w)
+	for _, c := range due {
+		select {
+		case c.out <- msg:
+		default:
+		}
+	}
+}
+
+func (s *Stream) buildFrame(w *world.World) []byte {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	buf := s.scratch[:0]
+	buf = append(buf, `{"type":"frame","t":`...)
+	buf = strconv.AppendFloat(buf, w.SimTime, 'f', 3, 64)
+
+	buf = append(buf, `,"vehicles":[`...)
+	for i, v := range w.Vehicles {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '[')
+		buf = appendJSONString(buf, v.ID)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, v.Pos.X, 'f', 1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, v.Pos.Y, 'f', 1, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, math.Remainder(v.GetAngle()-math.Pi/2, 2*math.Pi), 'f', 3, 64)
+		buf = append(buf, ',')
+		buf = strconv.AppendFloat(buf, v.Speed, 'f', 2, 64)
+		if v.IsEmergency() {
+			buf = append(buf, ",1]"...)
+		} else {
+			buf = append(buf, ",0]"...)
+		}
+	}
+
+	buf = append(buf, `],"lights":[`...)
+	for i, tl := range w.TrafficLights {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, '[')
+		buf = appendJSONString(buf, tl.ID)
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, lightStateName(tl.State))
+		buf = append(buf, ']')
+	}
+	buf = append(buf, "]}"...)
+
+	s.scratch = buf
+	return append([]byte(nil), buf...)
+}
+
+// appendJSONString appends s as a JSON string. Like encoding/json it
+// escapes control characters and replaces invalid UTF-8 with U+FFFD, since
+// imported networks can carry any bytes in their IDs.
+func appendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, `\n`...)
+			case c == '\r':
+				buf = append(buf, `\r`...)
+			case c == '\t':
+				buf = append(buf, `\t`...)
+			case c < 0x20:
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hex[c>>4], hex[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			buf = append(buf, `\u202`...)
+			buf = append(buf, hex[r&0xf])
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
+
+type streamEvent struct {
+	Type string  `json:"type"`
+	Name string  `json:"name"`
+	Time float64 `json:"t"`
+	Data any     `json:"data,omitempty"`
+}
+
+// publishEvent runs on whichever goroutine emitted the event, which may hold
+// the world lock, so it only reads the payload.
+func (s *Stream) publishEvent(w *world.World, name string, payload any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.clients) == 0 || s.world != w {
+		return
+	}
+
+	msg, err := json.Marshal(streamEvent{Type: "event", Name: name, Time: w.SimTime, Data: eventData(w, payload)})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", name, err)
+		return
+	}
+	for c := range s.clients {
+		if !c.events {
+			continue
+		}
+		select {
+		case c.out <- msg:
+		default:
+			c.drop()
+		}
+	}
+}
+
+// eventData flattens an event payload into something JSON can carry; world
+// objects are replaced by the API's own shapes or their IDs.
+func eventData(w *world.World, payload any) any {
+	switch ev := payload.(type) {
+	case events.RoadCreatedEvent:
+		return roadJSON(w, ev.Road, 0)
+	case events.RoadDeletedEvent:
+		return map[string]string{"id": ev.RoadID}
+	case events.NodeCreatedEvent:
+		return nodeJSON(ev.Node)
+	case events.NodeDeletedEvent:
+		return map[string]string{"id": ev.NodeID}
+	case events.WorldLoadedEvent:
+		return map[string]any{"path": ev.Path, "paused": ev.Paused}
+	case events.SpawnPointCreatedEvent:
+		return spawnPointJSON(ev.SpawnPoint)
+	case events.DespawnPointCreatedEvent:
+		return despawnPointJSON(ev.DespawnPoint)
+	case events.TrafficLightCreatedEvent:
+		return lightJSON(ev.TrafficLight)
+	case events.RoadPropertiesUpdatedEvent:
+		return map[string]any{"road": ev.Road.ID, "maxSpeed": ev.Road.MaxSpeed, "width": ev.Road.Width}
+	case events.EmergencyDispatchedEvent:
+		return map[string]any{"vehicleId": ev.VehicleID, "origin": spawnID(ev.Origin), "target": despawnID(ev.Target), "time": ev.Time}
+	case events.EmergencyArrivedEvent:
+		return map[string]any{"vehicleId": ev.VehicleID, "origin": spawnID(ev.Origin), "target": despawnID(ev.Target), "responseTime": ev.ResponseTime}
+	case events.SpeedZoneEvent:
+		return map[string]any{"road": ev.Road.ID, "id": ev.Zone.ID, "start": ev.Zone.Start, "end": ev.Zone.End, "limit": ev.Zone.Limit}
+	case events.DetectorEvent:
+		return map[string]any{"road": ev.Road.ID, "id": ev.Detector.ID, "position": ev.Detector.Position, "length": ev.Detector.Length}
+	case events.IncidentEvent:
+		return map[string]any{"id": ev.Incident.ID, "kind": ev.Incident.Kind.String(), "road": ev.Incident.Road.ID, "active": ev.Incident.Active}
+	case events.SnapshotTakenEvent:
+		return map[string]any{"label": ev.Label, "simTime": ev.SimTime}
+	case events.TripCompletedEvent:
+		return ev
+	}
+	return nil
+}
+
+func spawnID(sp *road.SpawnPoint) string {
+	if sp == nil {
+		return ""
+	}
+	return sp.ID
+}
+
+func despawnID(dp *road.DespawnPoint) string {
+	if dp == nil {
+		return ""
+	}
+	return dp.ID
+}
+
+// closeAll disconnects every client, e.g. when the server shuts down.
+func (s *Stream) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		c.drop()
+	}
+}
+
+// handleStream upgrades to a websocket and streams until the client leaves.
+// Query parameters: interval (sim seconds between frames, 0 for every tick,
+// default 0.1), frames=0 and events=0 to turn either kind off.
+func (s *Server) handleStream(rw http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	c := &streamClient{
+		out:      make(chan []byte, streamQueue),
+		interval: defaultStreamInterval,
+		frames:   q.Get("frames") != "0",
+		events:   q.Get("events") != "0",
+		done:     make(chan struct{}),
+	}
+	if v := q.Get("interval"); v != "" {
+		interval, err := strconv.ParseFloat(v, 64)
+		if err != nil || interval < 0 {
+			writeError(rw, http.StatusBadRequest, "invalid interval %q", v)
+			return
+		}
+		c.interval = interval
+	}
+
+	stream := s.stream
+	conn, err := upgrade(rw, r, func() {
+		stream.mu.Lock()
+		stream.clients[c] = struct{}{}
+		stream.mu.Unlock()
+	})
+	if err != nil {
+		stream.mu.Lock()
+		delete(stream.clients, c)
+		stream.mu.Unlock()
+		return
+	}
+	defer func() {
+		stream.mu.Lock()
+		delete(stream.clients, c)
+		stream.mu.Unlock()
+		conn.Close()
+	}()
+
+	go func() {
+		conn.readLoop()
+		c.drop()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.out:
+			if err := conn.WriteText(msg); err != nil {
+				return
+			}
+		}
+	}
+}
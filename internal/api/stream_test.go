@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialStream(t *testing.T, url string) *wsClient {
+	t.Helper()
+	addr := strings.TrimPrefix(url, "http://")
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	fmt.Fprintf(conn, "GET /api/stream?interval=0 HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n\r\n", addr, key)
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected handshake response: %d %v", resp.StatusCode, resp.Header)
+	}
+	return &wsClient{conn: conn, br: br}
+}
+
+func (c *wsClient) next(t *testing.T) map[string]any {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	opcode, payload, err := readFrame(c.br, 1<<20)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if opcode != opText {
+		t.Fatalf("expected a text message, got opcode %d", opcode)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatalf("bad message %s: %v", payload, err)
+	}
+	return msg
+}
+
+func TestStreamFramesAndEvents(t *testing.T) {
+	w := world.New()
+	n1 := &road.Node{ID: "n1"}
+	n2 := &road.Node{ID: "n2", Y: 100}
+	rd := road.NewRoad("r1", n1, n2, 20)
+	w.Nodes = append(w.Nodes, n1, n2)
+	w.Roads = append(w.Roads, rd)
+	w.Vehicles = append(w.Vehicles, &vehicle.Vehicle{ID: "v1", Road: rd, Speed: 12.5, Pos: vehicle.Vec2{X: 0, Y: 40}})
+
+	fake := &fakeSim{world: w}
+	srv := NewServer(fake)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	defer srv.Close()
+
+	client := dialStream(t, ts.URL)
+	defer client.conn.Close()
+
+	fake.Step()
+	frame := client.next(t)
+	if frame["type"] != "frame" {
+		t.Fatalf("expected a frame, got %v", frame)
+	}
+	vehicles := frame["vehicles"].([]any)
+	if len(vehicles) != 1 {
+		t.Fatalf("expected one vehicle, got %v", vehicles)
+	}
+	v := vehicles[0].([]any)
+	if v[0] != "v1" || v[2].(float64) != 40 || v[4].(float64) != 12.5 {
+		t.Errorf("unexpected vehicle entry %v", v)
+	}
+	// The road runs along +y.
+	if heading := v[3].(float64); heading < 1.57 || heading > 1.571 {
+		t.Errorf("expected heading pi/2, got %f", heading)
+	}
+
+	w.Events.Emit(events.EventRoadDeleted, events.RoadDeletedEvent{RoadID: "r1"})
+	ev := client.next(t)
+	if ev["type"] != "event" || ev["name"] != events.EventRoadDeleted {
+		t.Fatalf("expected a road.deleted event, got %v", ev)
+	}
+	if data := ev["data"].(map[string]any); data["id"] != "r1" {
+		t.Errorf("unexpected event data %v", data)
+	}
+
+	// A new simulation moves both the frames and the mirrored events over.
+	next := &fakeSim{world: world.New()}
+	srv.SetSimulation(next)
+	if len(fake.systems) != 0 || len(next.systems) != 1 {
+		t.Errorf("expected the stream system to move to the new simulation")
+	}
+	w.Events.Emit(events.EventRoadDeleted, events.RoadDeletedEvent{RoadID: "old"})
+	next.world.Events.Emit(events.EventNodeDeleted, events.NodeDeletedEvent{NodeID: "n9"})
+	if ev := client.next(t); ev["name"] != events.EventNodeDeleted {
+		t.Errorf("expected only the new world's events, got %v", ev)
+	}
+}
+
+func TestUpgradeRejectsCrossOrigin(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/stream", nil)
+	req.Host = "127.0.0.1:8080"
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Origin", "http://evil.example")
+
+	rec := httptest.NewRecorder()
+	ready := false
+	if _, err := upgrade(rec, req, func() { ready = true }); err == nil {
+		t.Fatal("expected a cross-origin handshake to fail")
+	}
+	if rec.Code != http.StatusForbidden || ready {
+		t.Errorf("expected 403 before the stream is set up, got %d (ready %v)", rec.Code, ready)
+	}
+}
+
+func TestFrameEscapesIDs(t *testing.T) {
+	w := world.New()
+	a := &road.Node{ID: "a", X: 0, Y: 0}
+	b := &road.Node{ID: "b", X: 100, Y: 0}
+	rd := road.NewRoad("a-b", a, b, 20)
+	ids := []string{"bell\a", "unit\x1f\"sep\\", "bad\xffutf8", "line\u2028sep", "naïve"}
+	for _, id := range ids {
+		w.Vehicles = append(w.Vehicles, &vehicle.Vehicle{ID: id, Road: rd})
+	}
+
+	var frame struct {
+		Vehicles [][]any `json:"vehicles"`
+	}
+	if err := json.Unmarshal(NewStream().buildFrame(w), &frame); err != nil {
+		t.Fatalf("frame is not valid JSON: %v", err)
+	}
+	want := []string{"bell\a", "unit\x1f\"sep\\", "bad�utf8", "line\u2028sep", "naïve"}
+	for i, v := range frame.Vehicles {
+		if v[0] != want[i] {
+			t.Errorf("expected ID %q, got %q", want[i], v[0])
+		}
+	}
+}
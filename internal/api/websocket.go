@@ -0,0 +1,211 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal server side of RFC 6455: enough to push text messages to a
+// browser or script and to notice when it goes away. Messages from the
+// client other than ping and close are read and discarded.
+
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+
+	maxClientFrame = 1 << 16
+	wsWriteTimeout = 10 * time.Second
+)
+
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+func wsAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgrade completes the opening handshake. ready runs after the request is
+// validated and before the handshake response is sent, so anything it sets
+// up is in place by the time the client sees the connection open.
+func upgrade(rw http.ResponseWriter, r *http.Request, ready func()) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(rw, "expected a websocket upgrade", http.StatusBadRequest)
+		return nil, errors.New("not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		rw.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(rw, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+	// Browsers let any page open a websocket to any host, so the origin
+	// is checked here as well as in guard, before anything is streamed.
+	if !sameOrigin(r) {
+		http.Error(rw, "cross-origin websocket connections are not allowed", http.StatusForbidden)
+		return nil, errors.New("cross-origin websocket handshake")
+	}
+
+	hj, ok := rw.(http.Hijacker)
+	if !ok {
+		http.Error(rw, "websocket not supported", http.StatusInternalServerError)
+		return nil, errors.New("response does not support hijacking")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	if ready != nil {
+		ready()
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, br: brw.Reader}, nil
+}
+
+func (c *wsConn) WriteText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+func (c *wsConn) writeFrame(opcode byte, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	_, err := c.conn.Write(appendFrame(nil, opcode, data, nil))
+	return err
+}
+
+// appendFrame encodes a single final frame. Frames from a client must be
+// masked; frames from the server must not.
+func appendFrame(buf []byte, opcode byte, data []byte, mask []byte) []byte {
+	buf = append(buf, 0x80|opcode)
+
+	var maskBit byte
+	if mask != nil {
+		maskBit = 0x80
+	}
+	switch n := len(data); {
+	case n < 126:
+		buf = append(buf, maskBit|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if mask == nil {
+		return append(buf, data...)
+	}
+	buf = append(buf, mask...)
+	for i, b := range data {
+		buf = append(buf, b^mask[i%4])
+	}
+	return buf
+}
+
+// readFrame decodes one frame, unmasking it when a mask is present.
+func readFrame(br *bufio.Reader, limit uint64) (opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+
+	size := uint64(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > limit {
+		return 0, nil, fmt.Errorf("websocket frame of %d bytes is too large", size)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(br, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+// readLoop answers pings and returns when the client closes the connection
+// or it fails.
+func (c *wsConn) readLoop() {
+	for {
+		opcode, payload, err := readFrame(c.br, maxClientFrame)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case opPing:
+			c.writeFrame(opPong, payload)
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return
+		}
+	}
+}
+
+func (c *wsConn) Close() error {
+	return c.conn.Close()
+}
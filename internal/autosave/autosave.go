@@ -0,0 +1,221 @@
+// Package autosave keeps rotating copies of the network being edited, so
+// an editing session survives a crash or a load that replaced it by
+// mistake.
+//
+// The network counts as edited once a command has changed it since the
+// last save. It is then autosaved after the configured interval, and also
+// just before another world replaces it.
+package autosave
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/world"
+)
+
+// Dir is where autosaves are written.
+var Dir = filepath.Join("saves", "autosave")
+
+const (
+	filePrefix = "autosave_"
+	// markerName is touched when the autosaves are no longer worth
+	// offering: the world was saved on request, or a restore was answered.
+	markerName = "acknowledged"
+)
+
+type Autosaver struct {
+	dir      string
+	interval float64
+	keep     int
+
+	mu      sync.Mutex
+	world   *world.World
+	dirty   bool
+	elapsed float64
+	unsubs  []func()
+}
+
+// New returns an autosaver writing to dir. It does nothing until Attach is
+// called, and nothing at all when cfg.Interval is 0.
+func New(dir string, cfg config.AutosaveConfig) *Autosaver {
+	return &Autosaver{dir: dir, interval: cfg.Interval, keep: max(1, cfg.Keep)}
+}
+
+func (a *Autosaver) Enabled() bool {
+	return a.interval > 0
+}
+
+// Attach starts tracking w, replacing the world tracked before. If that
+// world had unsaved edits it is autosaved first.
+func (a *Autosaver) Attach(w *world.World) {
+	a.mu.Lock()
+	prev, prevDirty := a.world, a.dirty
+	for _, unsub := range a.unsubs {
+		unsub()
+	}
+	a.unsubs = nil
+	a.world = w
+	a.dirty = false
+	a.elapsed = 0
+	a.mu.Unlock()
+
+	if prevDirty && prev != nil {
+		a.write(prev)
+	}
+	if !a.Enabled() || w == nil || w.Events == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.unsubs = append(a.unsubs,
+		w.Events.Subscribe(events.EventWorldEdited, func(any) {
+			a.mu.Lock()
+			a.dirty = true
+			a.mu.Unlock()
+		}),
+		w.Events.Subscribe(events.EventWorldSaved, func(any) {
+			a.mu.Lock()
+			a.dirty = false
+			a.elapsed = 0
+			a.mu.Unlock()
+			a.Acknowledge()
+		}),
+	)
+}
+
+// Dirty reports whether the world has edits that are in neither a save nor
+// an autosave.
+func (a *Autosaver) Dirty() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dirty
+}
+
+// Update advances the timer by dt seconds of real time and autosaves once
+// the world has had unsaved edits for the interval.
+func (a *Autosaver) Update(dt float64) {
+	a.mu.Lock()
+	if !a.Enabled() || !a.dirty {
+		a.mu.Unlock()
+		return
+	}
+	a.elapsed += dt
+	due := a.elapsed >= a.interval
+	a.mu.Unlock()
+
+	if due {
+		a.Save()
+	}
+}
+
+// Save autosaves the world now if it has unsaved edits.
+func (a *Autosaver) Save() {
+	a.mu.Lock()
+	w, dirty := a.world, a.dirty
+	a.dirty = false
+	a.elapsed = 0
+	a.mu.Unlock()
+
+	if a.Enabled() && dirty && w != nil && !a.write(w) {
+		a.mu.Lock()
+		if a.world == w {
+			a.dirty = true
+		}
+		a.mu.Unlock()
+	}
+}
+
+// write autosaves w and removes the oldest autosaves beyond the number
+// kept. It must be called without a.mu held: save commands emit events,
+// which take a.mu, while holding w.Mu. The read lock taken here is the only
+// one; SaveWorldTo does not lock again.
+func (a *Autosaver) write(w *world.World) bool {
+	path := filepath.Join(a.dir, filePrefix+time.Now().Format("2006-01-02_15-04-05.000")+".json")
+
+	w.Mu.RLock()
+	err := persistence.SaveWorldTo(path, w)
+	w.Mu.RUnlock()
+	if err != nil {
+		log.Printf("Autosave failed: %v", err)
+		return false
+	}
+	log.Printf("Autosaved to: %s", path)
+
+	if err := prune(a.dir, a.keep); err != nil {
+		log.Printf("Failed to remove old autosaves: %v", err)
+	}
+	return true
+}
+
+// Acknowledge records that the current autosaves need not be offered for
+// recovery again.
+func (a *Autosaver) Acknowledge() {
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		log.Printf("Failed to create autosave directory: %v", err)
+		return
+	}
+	marker := filepath.Join(a.dir, markerName)
+	if err := os.WriteFile(marker, []byte(time.Now().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		log.Printf("Failed to write %s: %v", marker, err)
+	}
+}
+
+// List returns the autosaves in dir, newest first.
+func List(dir string) ([]persistence.SaveFileInfo, error) {
+	files, err := persistence.ListSaveFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	autosaves := files[:0]
+	for _, f := range files {
+		if strings.HasPrefix(f.Name, filePrefix) {
+			autosaves = append(autosaves, f)
+		}
+	}
+	return autosaves, nil
+}
+
+// Recoverable returns the newest autosave in dir if it was written after
+// the world was last saved on request and after the last restore offer was
+// answered.
+func Recoverable(dir string) (persistence.SaveFileInfo, bool) {
+	autosaves, err := List(dir)
+	if err != nil {
+		log.Printf("Failed to list autosaves: %v", err)
+		return persistence.SaveFileInfo{}, false
+	}
+	if len(autosaves) == 0 {
+		return persistence.SaveFileInfo{}, false
+	}
+	latest := autosaves[0]
+
+	info, err := os.Stat(filepath.Join(dir, markerName))
+	if err == nil && !latest.ModTime.After(info.ModTime()) {
+		return persistence.SaveFileInfo{}, false
+	}
+	return latest, true
+}
+
+// prune removes all but the newest keep autosaves.
+func prune(dir string, keep int) error {
+	autosaves, err := List(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range autosaves[min(keep, len(autosaves)):] {
+		if err := os.Remove(f.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
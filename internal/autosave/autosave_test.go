@@ -0,0 +1,157 @@
+package autosave
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func edit(t *testing.T, w *world.World) {
+	t.Helper()
+	if err := commands.NewCommandExecutor(w).Execute(&commands.CreateNodeCommand{X: 1, Y: 2}); err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+}
+
+func TestAutosaveAfterInterval(t *testing.T) {
+	dir := t.TempDir()
+	a := New(dir, config.AutosaveConfig{Interval: 10, Keep: 2})
+	w := world.New()
+	a.Attach(w)
+
+	a.Update(20)
+	if files, _ := List(dir); len(files) != 0 {
+		t.Fatalf("an unedited world should not be autosaved, got %d files", len(files))
+	}
+
+	for i := 0; i < 3; i++ {
+		edit(t, w)
+		if !a.Dirty() {
+			t.Fatal("expected the world to be dirty after an edit")
+		}
+		a.Update(5)
+		if files, _ := List(dir); len(files) != min(i, 2) {
+			t.Fatalf("autosaved before the interval: %d files", len(files))
+		}
+		a.Update(5)
+		if a.Dirty() {
+			t.Fatal("expected the world to be clean after autosaving")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	files, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected the 2 newest autosaves to be kept, got %d", len(files))
+	}
+}
+
+func TestAttachSavesReplacedWorld(t *testing.T) {
+	dir := t.TempDir()
+	a := New(dir, config.AutosaveConfig{Interval: 60, Keep: 5})
+	old := world.New()
+	a.Attach(old)
+	edit(t, old)
+
+	a.Attach(world.New())
+	if files, _ := List(dir); len(files) != 1 {
+		t.Errorf("expected the edited world to be autosaved when replaced, got %d files", len(files))
+	}
+	if a.Dirty() {
+		t.Error("the new world should start clean")
+	}
+
+	edit(t, old)
+	if a.Dirty() {
+		t.Error("edits to the replaced world should no longer be tracked")
+	}
+}
+
+func TestRecoverable(t *testing.T) {
+	dir := t.TempDir()
+	a := New(dir, config.AutosaveConfig{Interval: 1, Keep: 5})
+	w := world.New()
+	a.Attach(w)
+
+	if _, ok := Recoverable(dir); ok {
+		t.Fatal("nothing to recover without autosaves")
+	}
+
+	edit(t, w)
+	a.Save()
+	latest, ok := Recoverable(dir)
+	if !ok {
+		t.Fatal("expected the autosave to be recoverable")
+	}
+
+	marker := filepath.Join(dir, markerName)
+	path := filepath.Join(t.TempDir(), "net.json")
+	if err := commands.NewCommandExecutor(w).Execute(&commands.SaveWorldCommand{Path: path}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("saving should write the marker: %v", err)
+	}
+	// Make the marker newer regardless of the file system's time resolution.
+	later := latest.ModTime.Add(time.Second)
+	if err := os.Chtimes(marker, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Recoverable(dir); ok {
+		t.Error("an autosave older than the last save should not be offered")
+	}
+}
+
+func TestAutosaveWhileEditsContend(t *testing.T) {
+	a := New(t.TempDir(), config.AutosaveConfig{Interval: 1, Keep: 1})
+	w := world.New()
+	a.Attach(w)
+
+	// Edits from another goroutine, as the API server makes them, keep a
+	// writer queued on the world lock while autosaves run.
+	stop := make(chan struct{})
+	editing := make(chan struct{})
+	go func() {
+		defer close(editing)
+		ex := commands.NewCommandExecutor(w)
+		node := &road.Node{ID: "n1"}
+		w.Mu.Lock()
+		w.Nodes = append(w.Nodes, node)
+		w.Mu.Unlock()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			ex.Execute(&commands.MoveNodeCommand{Node: node, NewX: float64(i % 100), NewY: 0})
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for saves := 0; saves < 50; {
+			if a.Dirty() {
+				a.Save()
+				saves++
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("autosave deadlocked against a waiting edit")
+	}
+	close(stop)
+	<-editing
+}
@@ -1,6 +1,9 @@
 package commands
 
-import "traffic-sim/internal/world"
+import (
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/world"
+)
 
 type Command interface {
 	Execute(w *world.World) error
@@ -14,14 +17,31 @@ type ExecuteWithReadLocking interface {
     ExecuteReadUnlocked(w *world.World) error
 }
 
+// RuntimeAction marks commands that act on the running simulation rather
+// than edit the network, e.g. dispatching an emergency vehicle. They are not
+// followed by a WorldEditedEvent.
+type RuntimeAction interface {
+	RuntimeAction()
+}
+
 type CommandExecutor struct {
-	world *world.World
+	world   *world.World
+	runtime bool
 }
 
 func NewCommandExecutor(w *world.World) *CommandExecutor {
 	return &CommandExecutor{world: w}
 }
 
+// NewRuntimeExecutor returns an executor for changes the simulation makes on
+// its own, such as scenario actions. None of its commands count as edits.
+func NewRuntimeExecutor(w *world.World) *CommandExecutor {
+	return &CommandExecutor{world: w, runtime: true}
+}
+
+// Execute runs cmd with the lock it asks for. Commands that may change the
+// world are followed by a WorldEditedEvent once the lock is released, unless
+// they are runtime actions or come from a runtime executor.
 func (e *CommandExecutor) Execute(cmd Command) error {
 	if readCmd, ok := cmd.(ExecuteWithReadLocking); ok {
 		e.world.Mu.RLock()
@@ -29,6 +49,19 @@ func (e *CommandExecutor) Execute(cmd Command) error {
 		return readCmd.ExecuteReadUnlocked(e.world)
 	}
 
+	if err := e.execute(cmd); err != nil {
+		return err
+	}
+	if _, ok := cmd.(RuntimeAction); ok || e.runtime {
+		return nil
+	}
+	if e.world.Events != nil {
+		e.world.Events.Emit(events.EventWorldEdited, events.WorldEditedEvent{Command: cmd})
+	}
+	return nil
+}
+
+func (e *CommandExecutor) execute(cmd Command) error {
 	if lockingCmd, ok := cmd.(ExecuteWithLocking); ok {
 		e.world.Mu.Lock()
 		defer e.world.Mu.Unlock()
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"math"
 	"traffic-sim/internal/road"
 	"traffic-sim/internal/world"
@@ -47,7 +46,6 @@ func (c *CurveRoadCommand) ExecuteUnlocked(w *world.World) error {
 	}
 
 	baseMultiplier := c.calculateBaseMultiplier(c.Road)
-	fmt.Println("basemultiplier:",baseMultiplier)
 	dot := fromTangent.X*toTangent.X + fromTangent.Y*toTangent.Y
 	if dot > 1 {
 		dot = 1
@@ -35,6 +35,12 @@ func (c *DeleteRoadCommand) ExecuteUnlocked(w *world.World) error {
 		}
 	}
 
+	for i := len(w.Incidents) - 1; i >= 0; i-- {
+		if w.Incidents[i].Road == c.Road {
+			w.Incidents = append(w.Incidents[:i], w.Incidents[i+1:]...)
+		}
+	}
+
 	w.RemoveRoadFromIntersections(c.Road)
 
 	for i, r := range w.Roads {
@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+type SetDespawnPointEnabledCommand struct {
+	DespawnPoint *road.DespawnPoint
+	Enabled      bool
+}
+
+func (c *SetDespawnPointEnabledCommand) ExecuteUnlocked(w *world.World) error {
+	c.DespawnPoint.Enabled = c.Enabled
+	return nil
+}
+
+func (c *SetDespawnPointEnabledCommand) Execute(w *world.World) error {
+	return nil
+}
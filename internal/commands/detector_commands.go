@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+type AddDetectorCommand struct {
+	Road     *road.Road
+	Detector *road.Detector
+}
+
+func (c *AddDetectorCommand) ExecuteUnlocked(w *world.World) error {
+	if c.Road == nil || c.Detector == nil {
+		return fmt.Errorf("detector needs a road")
+	}
+	if c.Detector.Position < 0 || c.Detector.Position > c.Road.Length {
+		return fmt.Errorf("detector %s at %.1fm is outside road %s", c.Detector.ID, c.Detector.Position, c.Road.ID)
+	}
+	if _, existing := w.FindDetector(c.Detector.ID); existing != nil {
+		return fmt.Errorf("detector %s already exists", c.Detector.ID)
+	}
+
+	c.Road.AddDetector(c.Detector)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventDetectorCreated, events.DetectorEvent{Road: c.Road, Detector: c.Detector})
+	}
+
+	return nil
+}
+
+func (c *AddDetectorCommand) Execute(w *world.World) error {
+	return nil
+}
+
+type RemoveDetectorCommand struct {
+	DetectorID string
+}
+
+func (c *RemoveDetectorCommand) ExecuteUnlocked(w *world.World) error {
+	rd, detector := w.FindDetector(c.DetectorID)
+	if detector == nil {
+		return fmt.Errorf("detector %s not found", c.DetectorID)
+	}
+
+	rd.RemoveDetector(c.DetectorID)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventDetectorRemoved, events.DetectorEvent{Road: rd, Detector: detector})
+	}
+
+	return nil
+}
+
+func (c *RemoveDetectorCommand) Execute(w *world.World) error {
+	return nil
+}
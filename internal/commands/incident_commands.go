@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+type CreateIncidentCommand struct {
+	Incident *road.Incident
+}
+
+func (c *CreateIncidentCommand) ExecuteUnlocked(w *world.World) error {
+	if c.Incident == nil || c.Incident.Road == nil {
+		return fmt.Errorf("incident needs a road")
+	}
+	if w.FindIncident(c.Incident.ID) != nil {
+		return fmt.Errorf("incident %s already exists", c.Incident.ID)
+	}
+
+	c.Incident.Active = c.Incident.IsActiveAt(w.SimTime)
+	w.Incidents = append(w.Incidents, c.Incident)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventIncidentCreated, events.IncidentEvent{Incident: c.Incident})
+	}
+
+	return nil
+}
+
+func (c *CreateIncidentCommand) Execute(w *world.World) error {
+	return nil
+}
+
+// ClearIncidentCommand removes an incident, reopening the road right away.
+type ClearIncidentCommand struct {
+	IncidentID string
+}
+
+func (c *ClearIncidentCommand) ExecuteUnlocked(w *world.World) error {
+	inc := w.RemoveIncident(c.IncidentID)
+	if inc == nil {
+		return fmt.Errorf("incident %s not found", c.IncidentID)
+	}
+	inc.Active = false
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventIncidentCleared, events.IncidentEvent{Incident: inc})
+	}
+
+	return nil
+}
+
+func (c *ClearIncidentCommand) Execute(w *world.World) error {
+	return nil
+}
@@ -2,32 +2,38 @@ package commands
 
 import (
 	"fmt"
+	"log"
 	"traffic-sim/internal/events"
 	"traffic-sim/internal/persistence"
 	"traffic-sim/internal/world"
 )
 
 type LoadWorldCommand struct {
+	Path string
+	// Repair fixes what can be fixed safely in a damaged save before it is
+	// loaded.
+	Repair        bool
 	OnWorldLoaded func(*world.World)
 }
 
 func (c *LoadWorldCommand) Execute(w *world.World) error {
-	saveData, err := persistence.LoadFromFile()
-	if err != nil {
-		return fmt.Errorf("failed to load world: %w", err)
-	}
-
-	if saveData == nil {
-		return nil
+	load := persistence.LoadWorldFrom
+	if c.Repair {
+		load = persistence.LoadRepairedWorldFrom
 	}
-
-	newWorld, err := persistence.DeserializeWorld(saveData)
+	newWorld, saveData, err := load(c.Path)
 	if err != nil {
-		return fmt.Errorf("failed to deserialize world: %w", err)
+		return fmt.Errorf("failed to load world: %w", err)
 	}
+	log.Printf("Simulation loaded from: %s", c.Path)
 
 	if w != nil && w.Events != nil {
-		w.Events.Emit(events.EventWorldLoaded, events.WorldLoadedEvent{World: newWorld})
+		loaded := events.WorldLoadedEvent{World: newWorld, Path: c.Path}
+		if saveData.State != nil {
+			loaded.SystemState = saveData.State.Systems
+			loaded.Paused = saveData.State.Paused
+		}
+		w.Events.Emit(events.EventWorldLoaded, loaded)
 	}
 
 	if c.OnWorldLoaded != nil {
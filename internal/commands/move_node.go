@@ -12,11 +12,14 @@ type MoveNodeCommand struct {
 }
 
 func (c *MoveNodeCommand) ExecuteUnlocked(w *world.World) error {
+	dx := c.NewX - c.Node.X
+	dy := c.NewY - c.Node.Y
 	c.Node.X = c.NewX
 	c.Node.Y = c.NewY
 
 	for _, rd := range w.Roads {
 		if rd.From == c.Node || rd.To == c.Node {
+			rd.ShiftCurve(c.Node, dx, dy)
 			rd.UpdateLength()
 		}
 	}
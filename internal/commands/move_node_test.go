@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"math"
+	"testing"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func TestMoveNodeKeepsCurveShape(t *testing.T) {
+	w := world.New()
+	ex := NewCommandExecutor(w)
+
+	a := &road.Node{ID: "a", X: 0, Y: 0}
+	b := &road.Node{ID: "b", X: 200, Y: 0}
+	w.Nodes = append(w.Nodes, a, b)
+	rd := road.NewRoad("a-b", a, b, 40)
+	rd.Curve = &road.RoadCurve{ControlP1: road.Point{X: 50, Y: 80}, ControlP2: road.Point{X: 150, Y: 80}}
+	w.Roads = append(w.Roads, rd)
+
+	if err := ex.Execute(&MoveNodeCommand{Node: b, NewX: 300, NewY: 20}); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	if rd.Curve.ControlP1 != (road.Point{X: 50, Y: 80}) {
+		t.Errorf("expected the control point at the fixed end to stay, got %+v", rd.Curve.ControlP1)
+	}
+	if rd.Curve.ControlP2 != (road.Point{X: 250, Y: 100}) {
+		t.Errorf("expected the control point to follow the moved node, got %+v", rd.Curve.ControlP2)
+	}
+	if x, y := rd.PosAt(rd.Length); math.Abs(x-300) > 1e-9 || math.Abs(y-20) > 1e-9 {
+		t.Errorf("expected the curve to end at the moved node, got (%f, %f)", x, y)
+	}
+}
@@ -1,17 +1,53 @@
 package commands
 
 import (
+	"encoding/json"
+	"log"
+	"traffic-sim/internal/events"
 	"traffic-sim/internal/persistence"
 	"traffic-sim/internal/world"
 )
 
-type SaveWorldCommand struct{}
+type SaveWorldCommand struct {
+	Path string
+}
 
 func (c *SaveWorldCommand) ExecuteReadUnlocked(w *world.World) error {
-	saveData := persistence.SerializeWorld(w)
-	return persistence.SaveToFile(saveData)
+	if err := persistence.SaveWorldTo(c.Path, w); err != nil {
+		return err
+	}
+
+	log.Printf("Simulation saved to: %s", c.Path)
+	if w.Events != nil {
+		w.Events.Emit(events.EventWorldSaved, events.WorldSavedEvent{Path: c.Path})
+	}
+	return nil
 }
 
 func (c *SaveWorldCommand) Execute(w *world.World) error {
 	return c.ExecuteReadUnlocked(w)
-}
\ No newline at end of file
+}
+
+// SaveSnapshotCommand saves the full dynamic state of the simulation. It
+// locks the world itself, so run it directly rather than via the executor.
+type SaveSnapshotCommand struct {
+	Path        string
+	SystemState map[string]json.RawMessage
+	Paused      bool
+}
+
+func (c *SaveSnapshotCommand) Execute(w *world.World) error {
+	saveData, err := persistence.SerializeSnapshot(w, c.SystemState, c.Paused)
+	if err != nil {
+		return err
+	}
+	if err := persistence.WriteSaveFile(c.Path, saveData); err != nil {
+		return err
+	}
+
+	log.Printf("Snapshot saved to: %s", c.Path)
+	if w.Events != nil {
+		w.Events.Emit(events.EventWorldSaved, events.WorldSavedEvent{Path: c.Path, Snapshot: true})
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/world"
+)
+
+func TestTakeSnapshotWritesOutsideWorldLock(t *testing.T) {
+	w := world.New()
+	w.SimTime = 42
+	path := filepath.Join(t.TempDir(), "stats.jsonl")
+
+	// A listener that edits the world can only run once the read lock the
+	// snapshot was taken under has been released.
+	var taken []events.SnapshotTakenEvent
+	w.Events.Subscribe(events.EventSnapshotTaken, func(p any) {
+		w.Mu.Lock()
+		defer w.Mu.Unlock()
+		taken = append(taken, p.(events.SnapshotTakenEvent))
+	})
+
+	cmd := &TakeSnapshotCommand{Label: "peak", Path: path}
+	done := make(chan error, 1)
+	go func() { done <- NewCommandExecutor(w).Execute(cmd) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("snapshot failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("snapshot event was emitted with the world lock held")
+	}
+
+	if cmd.Result == nil || cmd.Result.SimTime != 42 {
+		t.Errorf("unexpected result %+v", cmd.Result)
+	}
+	if len(taken) != 1 || taken[0].Label != "peak" {
+		t.Errorf("expected one snapshot event, got %+v", taken)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"label":"peak"`) {
+		t.Errorf("expected the snapshot in %s, got %q (%v)", path, data, err)
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+type SpawnEmergencyVehicleCommand struct {
+	SpawnPoint *road.SpawnPoint
+	Target     *road.DespawnPoint
+	Speed      float64
+}
+
+func (c *SpawnEmergencyVehicleCommand) ExecuteUnlocked(w *world.World) error {
+	if c.SpawnPoint == nil || c.SpawnPoint.Road == nil {
+		return fmt.Errorf("emergency vehicle needs a spawn point")
+	}
+
+	rd := c.SpawnPoint.Road
+	speed := c.Speed
+	if speed <= 0 {
+		speed = rd.MaxSpeed
+	}
+
+	c.SpawnPoint.VehicleCounter++
+	v := &vehicle.Vehicle{
+		ID:            fmt.Sprintf("%s-e%d", c.SpawnPoint.ID, c.SpawnPoint.VehicleCounter),
+		Kind:          vehicle.KindEmergency,
+		Road:          rd,
+		Speed:         speed,
+		Pos:           vehicle.Vec2{X: rd.From.X, Y: rd.From.Y},
+		TargetDespawn: c.Target,
+		Origin:        c.SpawnPoint,
+		SpawnTime:     w.SimTime,
+	}
+	w.Vehicles = append(w.Vehicles, v)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventEmergencyDispatched, events.EmergencyDispatchedEvent{
+			VehicleID: v.ID,
+			Origin:    c.SpawnPoint,
+			Target:    c.Target,
+			Time:      w.SimTime,
+		})
+	}
+
+	return nil
+}
+
+func (c *SpawnEmergencyVehicleCommand) Execute(w *world.World) error {
+	return nil
+}
+
+// RuntimeAction marks dispatching a vehicle as something other than an edit
+// of the network.
+func (c *SpawnEmergencyVehicleCommand) RuntimeAction() {}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+type AddSpeedZoneCommand struct {
+	Road *road.Road
+	Zone *road.SpeedZone
+}
+
+func (c *AddSpeedZoneCommand) ExecuteUnlocked(w *world.World) error {
+	if c.Road == nil || c.Zone == nil {
+		return fmt.Errorf("speed zone needs a road")
+	}
+	if c.Zone.Limit <= 0 {
+		return fmt.Errorf("speed zone %s has non-positive limit %.1f", c.Zone.ID, c.Zone.Limit)
+	}
+	if c.Road.FindSpeedZone(c.Zone.ID) != nil {
+		return fmt.Errorf("speed zone %s already exists on road %s", c.Zone.ID, c.Road.ID)
+	}
+
+	c.Road.AddSpeedZone(c.Zone)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventSpeedZoneCreated, events.SpeedZoneEvent{Road: c.Road, Zone: c.Zone})
+	}
+
+	return nil
+}
+
+func (c *AddSpeedZoneCommand) Execute(w *world.World) error {
+	return nil
+}
+
+type RemoveSpeedZoneCommand struct {
+	Road   *road.Road
+	ZoneID string
+}
+
+func (c *RemoveSpeedZoneCommand) ExecuteUnlocked(w *world.World) error {
+	zone := c.Road.FindSpeedZone(c.ZoneID)
+	if zone == nil {
+		return fmt.Errorf("speed zone %s not found on road %s", c.ZoneID, c.Road.ID)
+	}
+
+	c.Road.RemoveSpeedZone(c.ZoneID)
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventSpeedZoneRemoved, events.SpeedZoneEvent{Road: c.Road, Zone: zone})
+	}
+
+	return nil
+}
+
+func (c *RemoveSpeedZoneCommand) Execute(w *world.World) error {
+	return nil
+}
+
+// UpdateSpeedZoneCommand changes the limit of an existing zone, which is how
+// dynamic limits such as highway VSL are driven at run time.
+type UpdateSpeedZoneCommand struct {
+	Road   *road.Road
+	ZoneID string
+	Limit  float64
+}
+
+func (c *UpdateSpeedZoneCommand) ExecuteUnlocked(w *world.World) error {
+	zone := c.Road.FindSpeedZone(c.ZoneID)
+	if zone == nil {
+		return fmt.Errorf("speed zone %s not found on road %s", c.ZoneID, c.Road.ID)
+	}
+
+	if c.Limit > 0 {
+		zone.Limit = c.Limit
+	}
+
+	if w.Events != nil {
+		w.Events.Emit(events.EventSpeedZoneUpdated, events.SpeedZoneEvent{Road: c.Road, Zone: zone})
+	}
+
+	return nil
+}
+
+func (c *UpdateSpeedZoneCommand) Execute(w *world.World) error {
+	return nil
+}
@@ -31,6 +31,10 @@ func (c *SplitRoadCommand) ExecuteUnlocked(w *world.World) error {
 	newRoad2 := road.NewRoad(road2ID, splitNode, c.Road.To, c.Road.MaxSpeed)
 	newRoad2.Width = c.Road.Width
 
+	splitSpeedZones(c.Road, newRoad1, newRoad2)
+	splitDetectors(c.Road, newRoad1, newRoad2)
+	splitIncidents(w, c.Road, newRoad1, newRoad2)
+
 	if c.Road.ReverseRoad != nil {
 		c.handleReverseRoad(w, splitNode, newRoad1, newRoad2, newIntersection)
 	}
@@ -76,6 +80,10 @@ func (c *SplitRoadCommand) handleReverseRoad(w *world.World, splitNode *road.Nod
 	reverseNewRoad2 := road.NewRoad(reverseRoad2ID, c.Road.To, splitNode, reverseRoad.MaxSpeed)
 	reverseNewRoad2.Width = reverseRoad.Width
 	
+	splitSpeedZones(reverseRoad, reverseNewRoad2, reverseNewRoad1)
+	splitDetectors(reverseRoad, reverseNewRoad2, reverseNewRoad1)
+	splitIncidents(w, reverseRoad, reverseNewRoad2, reverseNewRoad1)
+
 	newRoad1.ReverseRoad = reverseNewRoad1
 	reverseNewRoad1.ReverseRoad = newRoad1
 	
@@ -122,6 +130,92 @@ func (c *SplitRoadCommand) updateVehiclesOnRoad(w *world.World, oldRoad, newRoad
 	}
 }
 
+// splitSpeedZones hands each zone on the old road to the new road(s) that
+// cover its distance range, clipping zones that straddle the split point.
+func splitSpeedZones(oldRoad, first, second *road.Road) {
+	cut := first.Length
+
+	for _, z := range oldRoad.SpeedZones {
+		if z.Start < cut {
+			part := *z
+			part.End = min(z.End, cut)
+			first.AddSpeedZone(&part)
+		}
+		if z.End > cut {
+			part := *z
+			part.Start = max(z.Start-cut, 0)
+			part.End = z.End - cut
+			second.AddSpeedZone(&part)
+		}
+	}
+}
+
+// splitDetectors moves each detector to the new road holding its position,
+// keeping its counts.
+func splitDetectors(oldRoad, first, second *road.Road) {
+	cut := first.Length
+
+	for _, d := range oldRoad.Detectors {
+		if d.Position < cut {
+			first.AddDetector(d)
+		} else {
+			d.Position = max(d.Position-cut, 0)
+			second.AddDetector(d)
+		}
+	}
+}
+
+// splitIncidents moves incidents on the old road onto the new road(s) that
+// hold their span. An incident reaching past the split is kept on both, the
+// part on the second road under a new ID, so a closure of the whole road
+// still closes all of it.
+func splitIncidents(w *world.World, oldRoad, first, second *road.Road) {
+	cut := first.Length
+
+	var copies []*road.Incident
+	for _, inc := range w.Incidents {
+		if inc.Road != oldRoad {
+			continue
+		}
+
+		end := inc.EndDistance()
+		if inc.Start >= cut {
+			inc.Start -= cut
+			if inc.End > 0 {
+				inc.End -= cut
+			}
+			inc.Road = second
+			continue
+		}
+
+		if end > cut {
+			part := *inc
+			part.ID = splitIncidentID(w, inc.ID)
+			part.Road = second
+			part.Start = 0
+			part.End = end - cut
+			if inc.End <= inc.Start {
+				part.End = 0
+			}
+			copies = append(copies, &part)
+		}
+		inc.End = min(end, cut)
+		inc.Road = first
+	}
+	w.Incidents = append(w.Incidents, copies...)
+}
+
+// splitIncidentID picks an unused ID for the part of an incident copied
+// onto the second road.
+func splitIncidentID(w *world.World, id string) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		if w.FindIncident(candidate) == nil {
+			return candidate
+		}
+	}
+}
+
 func (c *SplitRoadCommand) Execute(w *world.World) error {
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"testing"
+
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func TestSplitRoadKeepsClosureOnBothHalves(t *testing.T) {
+	w := world.New()
+	a := &road.Node{ID: "a", X: 0, Y: 0}
+	b := &road.Node{ID: "b", X: 200, Y: 0}
+	w.Nodes = append(w.Nodes, a, b)
+	w.CreateIntersection("a")
+	w.CreateIntersection("b")
+	rd := road.NewRoad("a-b", a, b, 20)
+	w.Roads = append(w.Roads, rd)
+
+	closure := road.NewIncident("inc1", road.IncidentClosure, rd)
+	closure.Active = true
+	slowdown := road.NewIncident("inc2", road.IncidentSlowdown, rd)
+	slowdown.Start, slowdown.End = 50, 150
+	w.Incidents = append(w.Incidents, closure, slowdown)
+
+	cmd := &SplitRoadCommand{Road: rd, X: 100, Y: 0, NodeID: "c"}
+	if err := NewCommandExecutor(w).Execute(cmd); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	first, second := w.FindRoad("a-c"), w.FindRoad("c-b")
+	if first == nil || second == nil {
+		t.Fatalf("expected both halves, got roads %v", w.Roads)
+	}
+
+	if !w.IsRoadClosed(first) || !w.IsRoadClosed(second) {
+		t.Errorf("expected the closure to cover both halves")
+	}
+	if closure.Road != first || closure.Start != 0 || closure.End != 100 {
+		t.Errorf("unexpected first part of the closure %+v", closure)
+	}
+	if part := w.FindIncident("inc1-2"); part == nil || part.Road != second || part.Start != 0 || part.EndDistance() != second.Length {
+		t.Errorf("unexpected second part of the closure %+v", part)
+	}
+
+	if slowdown.Road != first || slowdown.Start != 50 || slowdown.End != 100 {
+		t.Errorf("unexpected first part of the slowdown %+v", slowdown)
+	}
+	if part := w.FindIncident("inc2-2"); part == nil || part.Road != second || part.Start != 0 || part.End != 50 {
+		t.Errorf("unexpected second part of the slowdown %+v", part)
+	}
+	if len(w.Incidents) != 4 {
+		t.Errorf("expected 4 incidents after the split, got %d", len(w.Incidents))
+	}
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+type UpdateTrafficLightTimingCommand struct {
+	Light      *road.TrafficLight
+	GreenTime  float64
+	YellowTime float64
+	RedTime    float64
+}
+
+func (c *UpdateTrafficLightTimingCommand) ExecuteUnlocked(w *world.World) error {
+	if c.GreenTime > 0 {
+		c.Light.GreenTime = c.GreenTime
+	}
+	if c.YellowTime > 0 {
+		c.Light.YellowTime = c.YellowTime
+	}
+	if c.RedTime > 0 {
+		c.Light.RedTime = c.RedTime
+	}
+
+	return nil
+}
+
+func (c *UpdateTrafficLightTimingCommand) Execute(w *world.World) error {
+	return nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func TestExecutorEmitsEditedAndSavedEvents(t *testing.T) {
+	w := world.New()
+	ex := NewCommandExecutor(w)
+
+	var edited, saved []any
+	w.Events.Subscribe(events.EventWorldEdited, func(p any) { edited = append(edited, p) })
+	w.Events.Subscribe(events.EventWorldSaved, func(p any) { saved = append(saved, p) })
+
+	if err := ex.Execute(&CreateNodeCommand{X: 10, Y: 20}); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if len(edited) != 1 {
+		t.Fatalf("expected one edited event after creating a node, got %d", len(edited))
+	}
+
+	path := filepath.Join(t.TempDir(), "net.json")
+	if err := ex.Execute(&SaveWorldCommand{Path: path}); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if len(edited) != 1 {
+		t.Errorf("saving should not count as an edit, got %d edited events", len(edited))
+	}
+	if len(saved) != 1 || saved[0].(events.WorldSavedEvent).Path != path {
+		t.Errorf("expected one saved event for %s, got %v", path, saved)
+	}
+}
+
+func TestRuntimeCommandsAreNotEdits(t *testing.T) {
+	w := world.New()
+	var edited int
+	w.Events.Subscribe(events.EventWorldEdited, func(any) { edited++ })
+
+	if err := NewRuntimeExecutor(w).Execute(&CreateNodeCommand{X: 10, Y: 20}); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	a := &road.Node{ID: "a", X: 0, Y: 0}
+	b := &road.Node{ID: "b", X: 100, Y: 0}
+	sp := &road.SpawnPoint{ID: "s1", Node: a, Road: road.NewRoad("a-b", a, b, 20)}
+	if err := NewCommandExecutor(w).Execute(&SpawnEmergencyVehicleCommand{SpawnPoint: sp}); err != nil {
+		t.Fatalf("dispatch failed: %v", err)
+	}
+	if len(w.Vehicles) != 1 {
+		t.Fatalf("expected the emergency vehicle to be dispatched")
+	}
+	if edited != 0 {
+		t.Errorf("expected no edited events for runtime changes, got %d", edited)
+	}
+}
@@ -9,21 +9,85 @@ type FeatureFlags struct {
     RightOfWaySystem bool `mapstructure:"RIGHT_OF_WAY_SYSTEM"`
 }
 
+type MetricsConfig struct {
+    Interval float64 `mapstructure:"INTERVAL"`
+}
+
+type TripsConfig struct {
+    Log string `mapstructure:"LOG"`
+}
+
+// ChartsConfig sets how many minutes of sim time the live charts keep.
+type ChartsConfig struct {
+    Window float64 `mapstructure:"WINDOW"`
+}
+
+// APIConfig enables the local HTTP API when Addr is set, e.g. "127.0.0.1:8080".
+type APIConfig struct {
+    Addr string `mapstructure:"ADDR"`
+}
+
+// ImagesConfig sets what the image export hotkey writes: Format is png or
+// svg, Scale is pixels per metre and Bounds is "minX,minY,maxX,maxY" in
+// metres, or empty for the whole network.
+type ImagesConfig struct {
+    Dir    string  `mapstructure:"DIR"`
+    Format string  `mapstructure:"FORMAT"`
+    Scale  float64 `mapstructure:"SCALE"`
+    Bounds string  `mapstructure:"BOUNDS"`
+    Legend bool    `mapstructure:"LEGEND"`
+}
+
+// AutosaveConfig sets how often, in seconds of real time, an edited network
+// is autosaved, and how many autosaves are kept. An interval of 0 turns
+// autosave off.
+type AutosaveConfig struct {
+    Interval float64 `mapstructure:"INTERVAL"`
+    Keep     int     `mapstructure:"KEEP"`
+}
+
 type Config struct {
-    FeatureFlags FeatureFlags `mapstructure:"featureFlags"`
+    FeatureFlags FeatureFlags   `mapstructure:"featureFlags"`
+    Metrics      MetricsConfig  `mapstructure:"metrics"`
+    Trips        TripsConfig    `mapstructure:"trips"`
+    Charts       ChartsConfig   `mapstructure:"charts"`
+    API          APIConfig      `mapstructure:"api"`
+    Images       ImagesConfig   `mapstructure:"images"`
+    Autosave     AutosaveConfig `mapstructure:"autosave"`
 }
 
+// Default matches the shipped config.yaml, for code that runs without it.
+func Default() *Config {
+    return &Config{
+        FeatureFlags: FeatureFlags{RightOfWaySystem: true},
+        Metrics:      MetricsConfig{Interval: 60},
+        Charts:       ChartsConfig{Window: 10},
+        Images:       ImagesConfig{Dir: "images", Format: "png", Scale: 1, Legend: true},
+        Autosave:     AutosaveConfig{Interval: 120, Keep: 5},
+    }
+}
+
+// DefaultPath is where LoadConfig looks, relative to the repository root.
+const DefaultPath = "internal/config/config.yaml"
+
 func LoadConfig() (*Config, error) {
-    viper.SetConfigFile("internal/config/config.yaml")
+    return LoadConfigFrom(DefaultPath)
+}
+
+// LoadConfigFrom reads a config file. Sections it leaves out keep the
+// values from Default.
+func LoadConfigFrom(path string) (*Config, error) {
+    v := viper.New()
+    v.SetConfigFile(path)
 
-    if err := viper.ReadInConfig(); err != nil {
+    if err := v.ReadInConfig(); err != nil {
         return nil, err
     }
 
-    var cfg Config
-    if err := viper.Unmarshal(&cfg); err != nil {
+    cfg := Default()
+    if err := v.Unmarshal(cfg); err != nil {
         return nil, err
     }
 
-    return &cfg, nil
+    return cfg, nil
 }
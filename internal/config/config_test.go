@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	data := "metrics:\n  INTERVAL: 30\napi:\n  ADDR: 127.0.0.1:9000\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfigFrom(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Metrics.Interval != 30 || cfg.API.Addr != "127.0.0.1:9000" {
+		t.Errorf("got metrics %+v and api %+v from the file", cfg.Metrics, cfg.API)
+	}
+	if !cfg.FeatureFlags.RightOfWaySystem || cfg.Images.Format != "png" {
+		t.Errorf("sections missing from the file should keep their defaults, got %+v", cfg)
+	}
+
+	if _, err := LoadConfigFrom(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
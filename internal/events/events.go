@@ -1,6 +1,9 @@
 package events
 
-import "traffic-sim/internal/road"
+import (
+	"encoding/json"
+	"traffic-sim/internal/road"
+)
 
 const (
 	EventRoadCreated          = "road.created"
@@ -12,6 +15,21 @@ const (
 	EventDespawnPointCreated  = "despawnpoint.created"
 	EventTrafficLightCreated  = "trafficlight.created"
 	EventRoadPropertiesUpdated = "road.properties.updated"
+	EventEmergencyDispatched   = "emergency.dispatched"
+	EventEmergencyArrived      = "emergency.arrived"
+	EventSpeedZoneCreated      = "speedzone.created"
+	EventSpeedZoneRemoved      = "speedzone.removed"
+	EventSpeedZoneUpdated      = "speedzone.updated"
+	EventIncidentCreated       = "incident.created"
+	EventIncidentCleared       = "incident.cleared"
+	EventIncidentStarted       = "incident.started"
+	EventIncidentEnded         = "incident.ended"
+	EventSnapshotTaken         = "snapshot.taken"
+	EventTripCompleted         = "trip.completed"
+	EventDetectorCreated       = "detector.created"
+	EventDetectorRemoved       = "detector.removed"
+	EventWorldEdited           = "world.edited"
+	EventWorldSaved            = "world.saved"
 )
 
 type RoadCreatedEvent struct {
@@ -48,6 +66,75 @@ type RoadPropertiesUpdatedEvent struct {
 	Width    float64
 }
 
+// WorldLoadedEvent carries the new world. SystemState and Paused are only
+// set when the save was a snapshot.
 type WorldLoadedEvent struct {
-	World any
-}
\ No newline at end of file
+	World       any
+	Path        string
+	SystemState map[string]json.RawMessage
+	Paused      bool
+}
+
+// WorldEditedEvent is emitted by commands.CommandExecutor after a command
+// has changed the world.
+type WorldEditedEvent struct {
+	Command any
+}
+
+// WorldSavedEvent is emitted when the world is saved to a file on request,
+// not by autosave.
+type WorldSavedEvent struct {
+	Path     string
+	Snapshot bool
+}
+
+type EmergencyDispatchedEvent struct {
+	VehicleID string
+	Origin    *road.SpawnPoint
+	Target    *road.DespawnPoint
+	Time      float64
+}
+
+type EmergencyArrivedEvent struct {
+	VehicleID    string
+	Origin       *road.SpawnPoint
+	Target       *road.DespawnPoint
+	ResponseTime float64
+}
+
+type SpeedZoneEvent struct {
+	Road *road.Road
+	Zone *road.SpeedZone
+}
+
+type DetectorEvent struct {
+	Road     *road.Road
+	Detector *road.Detector
+}
+
+type IncidentEvent struct {
+	Incident *road.Incident
+}
+
+type SnapshotTakenEvent struct {
+	Label    string
+	SimTime  float64
+	Snapshot any
+}
+
+// TripCompletedEvent describes a finished trip from spawn to despawn. Origin
+// and Destination are the spawn and despawn point IDs.
+type TripCompletedEvent struct {
+	VehicleID   string   `json:"vehicleId"`
+	Emergency   bool     `json:"emergency,omitempty"`
+	Origin      string   `json:"origin"`
+	Destination string   `json:"destination"`
+	SpawnTime   float64  `json:"spawnTime"`
+	ArrivalTime float64  `json:"arrivalTime"`
+	TravelTime  float64  `json:"travelTime"`
+	Route       []string `json:"route"`
+	Distance    float64  `json:"distance"`
+	Stops       int      `json:"stops"`
+	StoppedTime float64  `json:"stoppedTime"`
+	MaxSpeed    float64  `json:"maxSpeed"`
+}
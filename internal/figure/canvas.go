@@ -0,0 +1,200 @@
+package figure
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+type point struct{ x, y float64 }
+
+// canvas is what a figure is drawn on, in pixels with y pointing down.
+// Colours are not premultiplied.
+type canvas interface {
+	polygon(pts []point, c color.RGBA)
+	circle(x, y, r float64, c color.RGBA)
+	ring(x, y, r, width float64, c color.RGBA)
+	// line strokes a polyline with flat ends and round joins.
+	line(pts []point, width float64, c color.RGBA)
+	// text writes one line of small monospaced text with its baseline at y.
+	text(x, y float64, s string, c color.RGBA)
+}
+
+// Width of a character of text, for laying out the legend.
+const charWidth = 7
+
+// circleSegments is how many sides a circle has in the raster.
+const circleSegments = 32
+
+type raster struct {
+	img *image.RGBA
+	z   *vector.Rasterizer
+}
+
+func newRaster(width, height int, background color.RGBA) *raster {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = background.R, background.G, background.B, background.A
+	}
+	return &raster{img: img, z: vector.NewRasterizer(width, height)}
+}
+
+// paint fills the path built so far and starts a new one. Overlapping
+// parts of a path are painted once as long as they wind the same way.
+func (r *raster) paint(c color.RGBA) {
+	r.z.Draw(r.img, r.img.Bounds(), image.NewUniform(color.NRGBA(c)), image.Point{})
+	size := r.img.Bounds().Size()
+	r.z.Reset(size.X, size.Y)
+}
+
+func (r *raster) path(pts []point) {
+	r.z.MoveTo(float32(pts[0].x), float32(pts[0].y))
+	for _, p := range pts[1:] {
+		r.z.LineTo(float32(p.x), float32(p.y))
+	}
+	r.z.ClosePath()
+}
+
+// circlePath adds a circle winding the same way as the quads of line, or
+// the other way when reverse is set.
+func (r *raster) circlePath(x, y, rad float64, reverse bool) {
+	pts := make([]point, circleSegments)
+	for i := range pts {
+		a := -2 * math.Pi * float64(i) / circleSegments
+		if reverse {
+			a = -a
+		}
+		pts[i] = point{x + rad*math.Cos(a), y + rad*math.Sin(a)}
+	}
+	r.path(pts)
+}
+
+func (r *raster) polygon(pts []point, c color.RGBA) {
+	if len(pts) < 3 {
+		return
+	}
+	r.path(pts)
+	r.paint(c)
+}
+
+func (r *raster) circle(x, y, rad float64, c color.RGBA) {
+	r.circlePath(x, y, rad, false)
+	r.paint(c)
+}
+
+func (r *raster) ring(x, y, rad, width float64, c color.RGBA) {
+	r.circlePath(x, y, rad+width/2, false)
+	r.circlePath(x, y, max(0, rad-width/2), true)
+	r.paint(c)
+}
+
+func (r *raster) line(pts []point, width float64, c color.RGBA) {
+	hw := width / 2
+	drawn := false
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		dx, dy := b.x-a.x, b.y-a.y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*hw, dx/l*hw
+		r.path([]point{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}})
+		if i < len(pts)-1 {
+			r.circlePath(b.x, b.y, hw, false)
+		}
+		drawn = true
+	}
+	if drawn {
+		r.paint(c)
+	}
+}
+
+func (r *raster) text(x, y float64, s string, c color.RGBA) {
+	d := font.Drawer{
+		Dst:  r.img,
+		Src:  image.NewUniform(color.NRGBA(c)),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(int(math.Round(x)), int(math.Round(y))),
+	}
+	d.DrawString(s)
+}
+
+// svg collects the elements of an SVG document.
+type svg struct {
+	b strings.Builder
+}
+
+func newSVG(width, height int, background color.RGBA) *svg {
+	s := &svg{}
+	fmt.Fprintf(&s.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(&s.b, `<rect width="%d" height="%d" %s/>`+"\n", width, height, paint("fill", background))
+	return s
+}
+
+func (s *svg) WriteTo(out io.Writer) (int64, error) {
+	n, err := io.WriteString(out, s.b.String()+"</svg>\n")
+	return int64(n), err
+}
+
+func (s *svg) polygon(pts []point, c color.RGBA) {
+	if len(pts) < 3 {
+		return
+	}
+	fmt.Fprintf(&s.b, `<polygon points="%s" %s/>`+"\n", coords(pts), paint("fill", c))
+}
+
+func (s *svg) circle(x, y, r float64, c color.RGBA) {
+	fmt.Fprintf(&s.b, `<circle cx="%s" cy="%s" r="%s" %s/>`+"\n", num(x), num(y), num(r), paint("fill", c))
+}
+
+func (s *svg) ring(x, y, r, width float64, c color.RGBA) {
+	fmt.Fprintf(&s.b, `<circle cx="%s" cy="%s" r="%s" fill="none" stroke-width="%s" %s/>`+"\n",
+		num(x), num(y), num(r), num(width), paint("stroke", c))
+}
+
+func (s *svg) line(pts []point, width float64, c color.RGBA) {
+	if len(pts) < 2 {
+		return
+	}
+	fmt.Fprintf(&s.b, `<polyline points="%s" fill="none" stroke-width="%s" stroke-linejoin="round" %s/>`+"\n",
+		coords(pts), num(width), paint("stroke", c))
+}
+
+func (s *svg) text(x, y float64, str string, c color.RGBA) {
+	fmt.Fprintf(&s.b, `<text x="%s" y="%s" font-family="monospace" font-size="12" %s>%s</text>`+"\n",
+		num(x), num(y), paint("fill", c), html.EscapeString(str))
+}
+
+// paint is a fill or stroke attribute for a colour, with its opacity when
+// it is not opaque.
+func paint(attr string, c color.RGBA) string {
+	s := fmt.Sprintf(`%s="#%02x%02x%02x"`, attr, c.R, c.G, c.B)
+	if c.A < 255 {
+		s += fmt.Sprintf(` %s-opacity="%s"`, attr, strconv.FormatFloat(float64(c.A)/255, 'f', 2, 64))
+	}
+	return s
+}
+
+func coords(pts []point) string {
+	parts := make([]string, len(pts))
+	for i, p := range pts {
+		parts[i] = num(p.x) + "," + num(p.y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// num writes a pixel coordinate to a tenth of a pixel.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10+0, 'f', -1, 64)
+}
@@ -0,0 +1,349 @@
+package figure
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"traffic-sim/internal/heatmap"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+// The window's colours.
+var (
+	colorBackground = color.RGBA{20, 20, 30, 255}
+	colorRoadBase   = color.RGBA{45, 45, 50, 255}
+	colorRoadShadow = color.RGBA{0, 0, 0, 80}
+	colorLegend     = color.RGBA{40, 40, 50, 230}
+	colorText       = color.RGBA{255, 255, 255, 255}
+	colorHint       = color.RGBA{160, 160, 170, 255}
+)
+
+const (
+	shadowOffset  = 3.0
+	curveSegments = 32
+)
+
+type scene struct {
+	c     canvas
+	w     *world.World
+	b     Bounds
+	scale float64
+	heat  *heatmap.Heatmap
+}
+
+func draw(c canvas, w *world.World, opts Options, b Bounds, scale float64, height int) {
+	heat := heatmap.New()
+	heat.RoadMode, heat.VehicleMode = opts.Roads, opts.Vehicles
+	heat.Prepare(w, opts.Metrics)
+
+	s := &scene{c: c, w: w, b: b, scale: scale, heat: heat}
+	s.roads()
+	s.markers()
+	s.vehicles()
+	s.trafficLights()
+	if opts.Legend {
+		s.legend(height)
+	}
+}
+
+// pt converts a world position to pixels.
+func (s *scene) pt(x, y float64) point {
+	return point{(x - s.b.MinX) * s.scale, (y - s.b.MinY) * s.scale}
+}
+
+// axis is the line vehicles follow along a road, in pixels.
+func (s *scene) axis(rd *road.Road) []point {
+	return s.along(rd, 0, rd.Length)
+}
+
+// along follows a road between two distances, in steps of at most 5m on
+// curved roads.
+func (s *scene) along(rd *road.Road, start, end float64) []point {
+	steps := 1
+	if rd.Curve != nil {
+		steps = max(1, min(curveSegments, int(math.Ceil((end-start)/5))))
+	}
+	pts := make([]point, 0, steps+1)
+	for i := 0; i <= steps; i++ {
+		x, y := rd.PosAt(start + (end-start)*float64(i)/float64(steps))
+		pts = append(pts, s.pt(x, y))
+	}
+	return pts
+}
+
+func (s *scene) roads() {
+	off := shadowOffset * s.scale
+	for _, rd := range s.w.Roads {
+		pts := s.axis(rd)
+		for i := range pts {
+			pts[i].x += off
+			pts[i].y += off
+		}
+		s.c.line(pts, rd.Width*s.scale, colorRoadShadow)
+	}
+
+	for _, rd := range s.w.Roads {
+		clr := colorRoadBase
+		if c, ok := s.heat.RoadColor(rd); ok {
+			clr = c
+		}
+		s.c.line(s.axis(rd), rd.Width*s.scale, clr)
+	}
+
+	radius := make(map[*road.Node]float64)
+	for _, rd := range s.w.Roads {
+		radius[rd.From] = max(radius[rd.From], rd.Width)
+		radius[rd.To] = max(radius[rd.To], rd.Width)
+	}
+	for _, n := range s.w.Nodes {
+		if r, ok := radius[n]; ok {
+			p := s.pt(n.X, n.Y)
+			s.c.circle(p.x, p.y, r*s.scale, colorRoadBase)
+		}
+	}
+}
+
+func (s *scene) markers() {
+	for _, rd := range s.w.Roads {
+		for _, z := range rd.SpeedZones {
+			band, sign := color.RGBA{255, 170, 40, 140}, color.RGBA{255, 255, 255, 255}
+			if !z.IsActive(s.w.SimTime) {
+				band, sign = color.RGBA{120, 120, 130, 90}, color.RGBA{160, 160, 170, 255}
+			}
+			s.band(rd, z.Start, z.End, rd.Width*0.35, band)
+			x, y := rd.PosAt(z.Start)
+			p := s.pt(x, y)
+			s.c.circle(p.x, p.y, 9*s.scale, sign)
+			s.c.ring(p.x, p.y, 9*s.scale, 2*s.scale, color.RGBA{220, 40, 40, 255})
+		}
+	}
+
+	for _, inc := range s.w.Incidents {
+		band := color.RGBA{255, 140, 30, 150}
+		if inc.IsClosure() {
+			band = color.RGBA{230, 40, 40, 170}
+		}
+		if !inc.Active {
+			band.A = 60
+		}
+		s.band(inc.Road, inc.Start, inc.EndDistance(), inc.Road.Width*0.8, band)
+
+		x, y := inc.Road.PosAt(inc.Start)
+		p, d := s.pt(x, y), 5*s.scale
+		sign := color.RGBA{255, 255, 255, band.A + 60}
+		s.c.line([]point{{p.x - d, p.y - d}, {p.x + d, p.y + d}}, 3*s.scale, sign)
+		s.c.line([]point{{p.x - d, p.y + d}, {p.x + d, p.y - d}}, 3*s.scale, sign)
+	}
+
+	for _, rd := range s.w.Roads {
+		for _, d := range rd.Detectors {
+			loop := color.RGBA{60, 200, 230, 150}
+			if d.Occupied() {
+				loop = color.RGBA{120, 255, 255, 230}
+			}
+			s.band(rd, d.Position, d.Position+d.Length, rd.Width*0.8, loop)
+		}
+	}
+
+	for _, sp := range s.w.SpawnPoints {
+		if sp.Enabled {
+			s.endpoint(sp.Node, sp.Road, true, color.RGBA{50, 255, 50, 200}, color.RGBA{100, 255, 100, 255}, color.RGBA{50, 255, 50, 255})
+		}
+	}
+	for _, dp := range s.w.DespawnPoints {
+		if dp.Enabled {
+			s.endpoint(dp.Node, dp.Road, false, color.RGBA{255, 50, 50, 200}, color.RGBA{255, 100, 100, 255}, color.RGBA{255, 50, 50, 255})
+		}
+	}
+}
+
+func (s *scene) band(rd *road.Road, start, end, width float64, c color.RGBA) {
+	if end > start {
+		s.c.line(s.along(rd, start, end), width*s.scale, c)
+	}
+}
+
+// endpoint draws a spawn or despawn marker: a disc on the node and an arrow
+// leaving it along the road, or arriving at it.
+func (s *scene) endpoint(n *road.Node, rd *road.Road, leaving bool, fill, edge, arrow color.RGBA) {
+	p := s.pt(n.X, n.Y)
+	s.c.circle(p.x, p.y, 10*s.scale, fill)
+	s.c.ring(p.x, p.y, 10*s.scale, 2*s.scale, edge)
+
+	dx, dy := rd.To.X-rd.From.X, rd.To.Y-rd.From.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+	dx, dy = dx/l, dy/l
+
+	tail, tip := p, point{p.x + dx*20*s.scale, p.y + dy*20*s.scale}
+	if !leaving {
+		tail, tip = point{p.x - dx*20*s.scale, p.y - dy*20*s.scale}, p
+	}
+	size, cos, sin := 8*s.scale, math.Cos(0.5), math.Sin(0.5)
+	left := point{tip.x - size*(dx*cos-dy*sin), tip.y - size*(dy*cos+dx*sin)}
+	right := point{tip.x - size*(dx*cos+dy*sin), tip.y - size*(dy*cos-dx*sin)}
+	s.c.line([]point{tail, tip}, 3*s.scale, arrow)
+	s.c.line([]point{left, tip, right}, 3*s.scale, arrow)
+}
+
+func (s *scene) trafficLights() {
+	for _, tl := range s.w.TrafficLights {
+		if !tl.Enabled {
+			continue
+		}
+		var lamp color.RGBA
+		switch tl.State {
+		case road.LightRed:
+			lamp = color.RGBA{255, 50, 50, 255}
+		case road.LightYellow:
+			lamp = color.RGBA{255, 255, 50, 255}
+		case road.LightGreen:
+			lamp = color.RGBA{50, 255, 50, 255}
+		}
+
+		// Controlled roads lead into the light's intersection.
+		for _, rd := range tl.ControlledRoads {
+			node := rd.To
+			dx, dy := rd.To.X-rd.From.X, rd.To.Y-rd.From.Y
+			l := math.Hypot(dx, dy)
+			if l == 0 {
+				continue
+			}
+			dx, dy = dx/l, dy/l
+			p := s.pt(node.X-dx*30-dy*12, node.Y-dy*30+dx*12)
+
+			hw, hh := 8*s.scale, 10*s.scale
+			box := []point{{p.x - hw, p.y - hh}, {p.x + hw, p.y - hh}, {p.x + hw, p.y + hh}, {p.x - hw, p.y + hh}}
+			s.c.polygon(box, color.RGBA{30, 30, 35, 255})
+			s.c.line(append(box, box[0]), s.scale, color.RGBA{40, 40, 40, 255})
+			s.c.circle(p.x, p.y, 5*s.scale, lamp)
+		}
+	}
+}
+
+func (s *scene) vehicles() {
+	for _, v := range s.w.Vehicles {
+		s.vehicle(v)
+	}
+}
+
+// vehicle draws a 5m by 10m body like the window does, turned to the
+// direction of travel, with its shadow and windshield.
+func (s *scene) vehicle(v *vehicle.Vehicle) {
+	pos := v.Position()
+	angle := v.GetAngle()
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	corners := func(shape [][2]float64, off float64) []point {
+		pts := make([]point, len(shape))
+		for i, c := range shape {
+			pts[i] = s.pt(pos.X+c[0]*cos-c[1]*sin+off, pos.Y+c[0]*sin+c[1]*cos+off)
+		}
+		return pts
+	}
+
+	hw, hh := 2.5, 5.0
+	body := [][2]float64{{-hw, -hh}, {hw, -hh}, {hw, hh}, {-hw, hh}}
+	s.c.polygon(corners(body, 2), color.RGBA{0, 0, 0, 102})
+
+	fill, edge := color.RGBA{230, 65, 65, 255}, color.RGBA{180, 50, 50, 255}
+	if c, ok := s.heat.VehicleColor(v); ok {
+		fill, edge = c, darken(darken(c))
+	} else if v.IsEmergency() {
+		fill, edge = color.RGBA{245, 245, 245, 255}, color.RGBA{200, 40, 40, 255}
+	} else if v.TargetDespawn != nil {
+		fill, edge = color.RGBA{90, 90, 230, 255}, color.RGBA{70, 70, 180, 255}
+	}
+	outline := corners(body, 0)
+	s.c.polygon(outline, fill)
+
+	windshield := [][2]float64{{-hw * 0.7, -hh}, {hw * 0.7, -hh}, {hw * 0.5, -hh * 0.5}, {-hw * 0.5, -hh * 0.5}}
+	s.c.polygon(corners(windshield, 0), color.RGBA{90, 115, 140, 180})
+	s.c.line(append(outline, outline[0]), s.scale, edge)
+}
+
+func darken(c color.RGBA) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * 0.78), uint8(float64(c.G) * 0.78), uint8(float64(c.B) * 0.78), c.A}
+}
+
+// legend draws, from the top, a row for each active overlay, a scale bar
+// and the simulation time. It is sized in pixels whatever the scale.
+func (s *scene) legend(height int) {
+	type row struct {
+		title string
+		scale [2]string
+	}
+	var rows []row
+	if s.heat.RoadMode != heatmap.RoadOverlayNone {
+		rows = append(rows, row{"Roads: " + s.heat.RoadMode.String(), s.heat.RoadScale()})
+	}
+	if s.heat.VehicleMode != heatmap.VehicleOverlayNone {
+		rows = append(rows, row{"Vehicles: " + s.heat.VehicleMode.String(), s.heat.VehicleScale()})
+	}
+
+	const (
+		width     = 300.0
+		rowHeight = 48.0
+		pad       = 12.0
+	)
+	boxHeight := rowHeight*float64(len(rows)) + 60
+	x, y := 15.0, float64(height)-boxHeight-15
+	s.c.polygon(rect(x, y, width, boxHeight), colorLegend)
+
+	for i, r := range rows {
+		rowY := y + 10 + rowHeight*float64(i)
+		s.c.text(x+pad, rowY+11, r.title, colorText)
+
+		barY := rowY + 20
+		if r.scale[0] == "" {
+			for j, c := range heatmap.DestinationPalette {
+				s.c.polygon(rect(x+pad+float64(j)*34, barY, 30, 10), c)
+			}
+			continue
+		}
+		steps := 40
+		step := (width - 2*pad) / float64(steps)
+		for j := 0; j < steps; j++ {
+			c := heatmap.Color(float64(j) / float64(steps-1))
+			s.c.polygon(rect(x+pad+float64(j)*step, barY, step+0.5, 10), c)
+		}
+		s.c.text(x+pad, barY+24, r.scale[0], colorHint)
+		s.c.text(x+width-pad-float64(len(r.scale[1])*charWidth), barY+24, r.scale[1], colorHint)
+	}
+
+	barY := y + rowHeight*float64(len(rows)) + 14
+	metres := niceLength(120 / s.scale)
+	length := metres * s.scale
+	s.c.polygon(rect(x+pad, barY, length, 4), colorText)
+	s.c.text(x+pad+length+8, barY+6, formatLength(metres), colorText)
+
+	status := fmt.Sprintf("t = %.0fs, %d vehicles", s.w.SimTime, len(s.w.Vehicles))
+	s.c.text(x+pad, barY+30, status, colorHint)
+}
+
+func rect(x, y, w, h float64) []point {
+	return []point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+}
+
+// niceLength is the largest of 1, 2 or 5 times a power of ten that is no
+// longer than limit.
+func niceLength(limit float64) float64 {
+	p := math.Pow(10, math.Floor(math.Log10(limit)))
+	for _, f := range []float64{5, 2, 1} {
+		if f*p <= limit {
+			return f * p
+		}
+	}
+	return p
+}
+
+func formatLength(metres float64) string {
+	if metres >= 1000 {
+		return fmt.Sprintf("%g km", metres/1000)
+	}
+	return fmt.Sprintf("%g m", metres)
+}
@@ -0,0 +1,174 @@
+// Package figure draws the world to a PNG or SVG file without a window,
+// for reports that need still images of the network and its traffic.
+//
+// Figures look like the window: the same colours, the same heatmap
+// overlays, and roads, markers and vehicles at their sizes in world
+// units, multiplied by the scale. The toolbar and editing overlays are
+// left out.
+package figure
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"traffic-sim/internal/heatmap"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// margin is added around the network when the bounds are fitted; it
+	// leaves room for markers drawn beside the end nodes.
+	margin = 40.0
+
+	// maxPixels limits the size of a raster image.
+	maxPixels = 64 << 20
+)
+
+// Bounds is the area of the world a figure shows, in metres.
+type Bounds struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+func (b Bounds) Empty() bool {
+	return b.MaxX <= b.MinX || b.MaxY <= b.MinY
+}
+
+// ParseBounds reads bounds given as "minX,minY,maxX,maxY".
+func ParseBounds(s string) (Bounds, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Bounds{}, fmt.Errorf("invalid bounds %q, expected minX,minY,maxX,maxY", s)
+	}
+	var v [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Bounds{}, fmt.Errorf("invalid bounds %q, expected minX,minY,maxX,maxY", s)
+		}
+		v[i] = f
+	}
+	b := Bounds{MinX: v[0], MinY: v[1], MaxX: v[2], MaxY: v[3]}
+	if b.Empty() {
+		return Bounds{}, fmt.Errorf("invalid bounds %q: the maximum must be above the minimum", s)
+	}
+	return b, nil
+}
+
+// Fit returns the bounds of the network's nodes with a margin around them.
+func Fit(w *world.World) Bounds {
+	if len(w.Nodes) == 0 {
+		return Bounds{MinX: -margin, MinY: -margin, MaxX: margin, MaxY: margin}
+	}
+	b := Bounds{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, n := range w.Nodes {
+		b.MinX, b.MaxX = min(b.MinX, n.X), max(b.MaxX, n.X)
+		b.MinY, b.MaxY = min(b.MinY, n.Y), max(b.MaxY, n.Y)
+	}
+	b.MinX -= margin
+	b.MinY -= margin
+	b.MaxX += margin
+	b.MaxY += margin
+	return b
+}
+
+type Options struct {
+	// Bounds is the area drawn; the zero value fits the network.
+	Bounds Bounds
+	// Scale is in pixels per metre; zero means 1, as in the window.
+	Scale float64
+	// Legend adds a box in the bottom-left corner with the overlays'
+	// scales, a scale bar and the simulation time.
+	Legend bool
+
+	Roads    heatmap.RoadOverlay
+	Vehicles heatmap.VehicleOverlay
+	// Metrics supplies the last interval for the interval road overlays;
+	// without it those roads are drawn as having no data.
+	Metrics *metrics.Collector
+}
+
+// size returns the bounds and scale opts resolves to for w, and the size
+// of the image in pixels.
+func (o Options) size(w *world.World) (Bounds, float64, int, int, error) {
+	b := o.Bounds
+	if b.Empty() {
+		b = Fit(w)
+	}
+	scale := o.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	if scale < 0 {
+		return Bounds{}, 0, 0, 0, fmt.Errorf("invalid scale %g", scale)
+	}
+	width := max(1, int(math.Ceil((b.MaxX-b.MinX)*scale)))
+	height := max(1, int(math.Ceil((b.MaxY-b.MinY)*scale)))
+	if width*height > maxPixels {
+		return Bounds{}, 0, 0, 0, fmt.Errorf("a %dx%d image is too large; lower the scale or narrow the bounds", width, height)
+	}
+	return b, scale, width, height, nil
+}
+
+// Render draws the world into an image. The caller must hold w.Mu.
+func Render(w *world.World, opts Options) (*image.RGBA, error) {
+	b, scale, width, height, err := opts.size(w)
+	if err != nil {
+		return nil, err
+	}
+	r := newRaster(width, height, colorBackground)
+	draw(r, w, opts, b, scale, height)
+	return r.img, nil
+}
+
+// WritePNG renders the world and writes it as a PNG image. The caller must
+// hold w.Mu.
+func WritePNG(out io.Writer, w *world.World, opts Options) error {
+	img, err := Render(w, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(out, img)
+}
+
+// WriteSVG writes the world as an SVG document. The caller must hold w.Mu.
+func WriteSVG(out io.Writer, w *world.World, opts Options) error {
+	b, scale, width, height, err := opts.size(w)
+	if err != nil {
+		return err
+	}
+	s := newSVG(width, height, colorBackground)
+	draw(s, w, opts, b, scale, height)
+	_, err = s.WriteTo(out)
+	return err
+}
+
+// Write writes the world to path as SVG if it ends in .svg and as PNG
+// otherwise, creating its directory. The caller must hold w.Mu.
+func Write(path string, w *world.World, opts Options) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create image directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		err = WriteSVG(file, w, opts)
+	} else {
+		err = WritePNG(file, w, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write image: %w", err)
+	}
+	return file.Close()
+}
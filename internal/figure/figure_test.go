@@ -0,0 +1,111 @@
+package figure
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"traffic-sim/internal/heatmap"
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/world"
+)
+
+// newTestWorld is a oneway road 200m long from a to b.
+func newTestWorld(t *testing.T) *world.World {
+	t.Helper()
+	w := world.New()
+	b := interop.NewBuilder(w)
+	if _, err := b.AddNode("a", 0, 0); err != nil {
+		t.Fatalf("add node: %v", err)
+	}
+	if _, err := b.AddNode("b", 200, 0); err != nil {
+		t.Fatalf("add node: %v", err)
+	}
+	if _, err := b.AddRoad("a", "b", 15, 8, nil); err != nil {
+		t.Fatalf("add road: %v", err)
+	}
+	return w
+}
+
+func TestRender(t *testing.T) {
+	w := newTestWorld(t)
+	img, err := Render(w, Options{Scale: 2})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 560 || size.Y != 160 {
+		t.Fatalf("expected the network with a 40m margin at 2px/m, got %v", size)
+	}
+	if c := img.RGBAAt(5, 5); c != colorBackground {
+		t.Errorf("expected the background in the corner, got %v", c)
+	}
+	if c := img.RGBAAt(200, 80); c != colorRoadBase {
+		t.Errorf("expected the road in the middle, got %v", c)
+	}
+
+	// Empty roads are at free flow.
+	img, err = Render(w, Options{Scale: 2, Roads: heatmap.RoadOverlaySpeed})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if c, want := img.RGBAAt(200, 80), heatmap.Color(0); c != want {
+		t.Errorf("expected the road coloured %v, got %v", want, c)
+	}
+
+	img, err = Render(w, Options{Bounds: Bounds{MinX: 100, MinY: -10, MaxX: 120, MaxY: 10}, Scale: 1})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 20 || size.Y != 20 {
+		t.Errorf("expected a 20x20 image of the bounds, got %v", size)
+	}
+	if c := img.RGBAAt(10, 10); c != colorRoadBase {
+		t.Errorf("expected the road across the bounds, got %v", c)
+	}
+
+	if _, err := Render(w, Options{Scale: 1000}); err == nil {
+		t.Error("expected an error for an image that is too large")
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Scale: 2, Legend: true, Roads: heatmap.RoadOverlayDensity}
+	if err := WriteSVG(&buf, newTestWorld(t), opts); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`width="560" height="160"`,
+		`<polyline points="80,80 480,80" fill="none" stroke-width="16" stroke-linejoin="round" stroke="#3cbe46"/>`,
+		`>Roads: Density (live)</text>`,
+		`>50 m</text>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "</svg>\n") {
+		t.Error("expected the document to be closed")
+	}
+}
+
+func TestParseBounds(t *testing.T) {
+	b, err := ParseBounds("-10, 0,100,50.5")
+	if err != nil || b != (Bounds{MinX: -10, MaxX: 100, MaxY: 50.5}) {
+		t.Errorf("unexpected bounds %+v, %v", b, err)
+	}
+	for _, s := range []string{"1,2,3", "0,0,a,1", "10,0,0,10"} {
+		if _, err := ParseBounds(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestNiceLength(t *testing.T) {
+	for limit, want := range map[float64]float64{120: 100, 60: 50, 30: 20, 1.5: 1, 0.3: 0.2} {
+		if got := niceLength(limit); got != want {
+			t.Errorf("niceLength(%v): expected %v, got %v", limit, want, got)
+		}
+	}
+}
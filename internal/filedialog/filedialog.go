@@ -0,0 +1,108 @@
+// Package filedialog shows the native file dialogs the editor uses to pick
+// save files and recordings. It is kept apart from persistence so code that
+// only reads and writes files does not pull in the dialog toolkit.
+package filedialog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sqweek/dialog"
+)
+
+const SaveDir = "saves"
+
+// ErrUnavailable is returned, wrapped, when no native dialog can be shown,
+// so callers can fall back to picking a file in the window.
+var ErrUnavailable = errors.New("no native file dialog available")
+
+// Disabled makes every dialog fail with ErrUnavailable without trying.
+var Disabled bool
+
+func init() {
+	if err := os.MkdirAll(SaveDir, 0755); err != nil {
+		log.Printf("Warning: Could not create saves directory: %v", err)
+	}
+}
+
+// DefaultSavePath is a timestamped name for a new save in the saves
+// directory.
+func DefaultSavePath() string {
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	return filepath.Join(SaveDir, fmt.Sprintf("simulation_%s.json", timestamp))
+}
+
+// ChooseSaveFile asks where to save the simulation, suggesting a timestamped
+// name in the saves directory. It returns an empty path if the user cancels.
+func ChooseSaveFile() (string, error) {
+	if Disabled {
+		return "", ErrUnavailable
+	}
+	filename, err := dialog.File().
+		Title("Save Simulation").
+		Filter("JSON files", "json").
+		SetStartFile(DefaultSavePath()).
+		Save()
+
+	if err != nil {
+		if err == dialog.ErrCancelled {
+			log.Println("Save cancelled by user")
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	if filepath.Ext(filename) != ".json" {
+		filename += ".json"
+	}
+	return filename, nil
+}
+
+// ChooseLoadFile asks for a save file to load. It returns an empty path if
+// the user cancels.
+func ChooseLoadFile() (string, error) {
+	if Disabled {
+		return "", ErrUnavailable
+	}
+	filename, err := dialog.File().
+		Title("Load Simulation").
+		Filter("JSON files", "json").
+		SetStartDir(SaveDir).
+		Load()
+
+	if err != nil {
+		if err == dialog.ErrCancelled {
+			log.Println("Load cancelled by user")
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	return filename, nil
+}
+
+// ChooseFile shows an open dialog for files with the given extension. It
+// returns an empty path if the user cancels.
+func ChooseFile(title, filterName, ext, startDir string) (string, error) {
+	if Disabled {
+		return "", ErrUnavailable
+	}
+	filename, err := dialog.File().
+		Title(title).
+		Filter(filterName, ext).
+		SetStartDir(startDir).
+		Load()
+
+	if err != nil {
+		if err == dialog.ErrCancelled {
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,235 @@
+// Package heatmap colours roads and vehicles by a traffic metric. It is
+// shared by the window renderer and the image export, so it must not
+// depend on ebiten.
+package heatmap
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image/color"
+	"strings"
+
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+type RoadOverlay int
+
+const (
+	RoadOverlayNone RoadOverlay = iota
+	RoadOverlaySpeed
+	RoadOverlayAvgSpeed
+	RoadOverlayDensity
+	RoadOverlayVolume
+	roadOverlayCount
+)
+
+// roadOverlayNames are the names ParseRoadOverlay accepts, by overlay.
+var roadOverlayNames = []string{"none", "speed", "avgspeed", "density", "volume"}
+
+func (o RoadOverlay) String() string {
+	switch o {
+	case RoadOverlaySpeed:
+		return "Speed ratio (live)"
+	case RoadOverlayAvgSpeed:
+		return "Speed ratio (last interval)"
+	case RoadOverlayDensity:
+		return "Density (live)"
+	case RoadOverlayVolume:
+		return "Volume (last interval)"
+	}
+	return "None"
+}
+
+// ParseRoadOverlay reads an overlay by its short name: none, speed,
+// avgspeed, density or volume.
+func ParseRoadOverlay(s string) (RoadOverlay, error) {
+	for i, name := range roadOverlayNames {
+		if strings.EqualFold(s, name) {
+			return RoadOverlay(i), nil
+		}
+	}
+	return RoadOverlayNone, fmt.Errorf("unknown road overlay %q, expected one of %s", s, strings.Join(roadOverlayNames, ", "))
+}
+
+type VehicleOverlay int
+
+const (
+	VehicleOverlayNone VehicleOverlay = iota
+	VehicleOverlaySpeed
+	VehicleOverlayWaiting
+	VehicleOverlayDestination
+	vehicleOverlayCount
+)
+
+var vehicleOverlayNames = []string{"none", "speed", "waiting", "destination"}
+
+func (o VehicleOverlay) String() string {
+	switch o {
+	case VehicleOverlaySpeed:
+		return "Speed"
+	case VehicleOverlayWaiting:
+		return "Waiting time"
+	case VehicleOverlayDestination:
+		return "Destination"
+	}
+	return "None"
+}
+
+// ParseVehicleOverlay reads an overlay by its short name: none, speed,
+// waiting or destination.
+func ParseVehicleOverlay(s string) (VehicleOverlay, error) {
+	for i, name := range vehicleOverlayNames {
+		if strings.EqualFold(s, name) {
+			return VehicleOverlay(i), nil
+		}
+	}
+	return VehicleOverlayNone, fmt.Errorf("unknown vehicle overlay %q, expected one of %s", s, strings.Join(vehicleOverlayNames, ", "))
+}
+
+// Scale ends for the overlays; values beyond them get the end colour.
+const (
+	JamDensity     = 100.0
+	MaxVolume      = 1800.0
+	MaxWaitSeconds = 60.0
+)
+
+var (
+	ColorNoData = color.RGBA{70, 70, 80, 255}
+
+	DestinationPalette = []color.RGBA{
+		{230, 90, 70, 255}, {70, 160, 230, 255}, {240, 190, 60, 255}, {120, 200, 90, 255},
+		{190, 100, 220, 255}, {60, 200, 190, 255}, {240, 130, 180, 255}, {160, 160, 170, 255},
+	}
+)
+
+// Heatmap colours roads and vehicles by the selected overlays. Road values
+// are refreshed by Prepare.
+type Heatmap struct {
+	RoadMode    RoadOverlay
+	VehicleMode VehicleOverlay
+
+	live      map[string]metrics.RoadSnapshot
+	intervals map[string]metrics.RoadInterval
+}
+
+func New() *Heatmap {
+	return &Heatmap{}
+}
+
+func (h *Heatmap) CycleRoadMode() {
+	h.RoadMode = (h.RoadMode + 1) % roadOverlayCount
+}
+
+func (h *Heatmap) CycleVehicleMode() {
+	h.VehicleMode = (h.VehicleMode + 1) % vehicleOverlayCount
+}
+
+// Active reports whether either overlay is on.
+func (h *Heatmap) Active() bool {
+	return h.RoadMode != RoadOverlayNone || h.VehicleMode != VehicleOverlayNone
+}
+
+// Prepare gathers the values the road overlay needs. The caller must hold
+// w.Mu; collector may be nil when no aggregated metrics are available.
+func (h *Heatmap) Prepare(w *world.World, collector *metrics.Collector) {
+	h.live = nil
+	h.intervals = nil
+
+	switch h.RoadMode {
+	case RoadOverlaySpeed, RoadOverlayDensity:
+		h.live = metrics.LiveRoads(w)
+	case RoadOverlayAvgSpeed, RoadOverlayVolume:
+		if collector == nil {
+			return
+		}
+		h.intervals = make(map[string]metrics.RoadInterval)
+		for _, row := range collector.Latest() {
+			h.intervals[row.RoadID] = row
+		}
+	}
+}
+
+// RoadColor returns the overlay colour of a road, or false to keep the
+// normal road colour.
+func (h *Heatmap) RoadColor(rd *road.Road) (color.RGBA, bool) {
+	switch h.RoadMode {
+	case RoadOverlaySpeed:
+		return Color(1 - h.live[rd.ID].SpeedRatio), true
+	case RoadOverlayDensity:
+		return Color(h.live[rd.ID].Density / JamDensity), true
+	case RoadOverlayAvgSpeed:
+		row, ok := h.intervals[rd.ID]
+		if !ok || row.SpaceMeanSpeed == 0 || rd.MaxSpeed <= 0 {
+			return ColorNoData, true
+		}
+		return Color(1 - row.SpaceMeanSpeed/rd.MaxSpeed), true
+	case RoadOverlayVolume:
+		row, ok := h.intervals[rd.ID]
+		if !ok {
+			return ColorNoData, true
+		}
+		return Color(row.Flow / MaxVolume), true
+	}
+	return color.RGBA{}, false
+}
+
+// VehicleColor returns the overlay colour of a vehicle, or false to keep
+// its normal colours. Emergency vehicles always keep theirs.
+func (h *Heatmap) VehicleColor(v *vehicle.Vehicle) (color.RGBA, bool) {
+	if v.IsEmergency() {
+		return color.RGBA{}, false
+	}
+
+	switch h.VehicleMode {
+	case VehicleOverlaySpeed:
+		if v.Road == nil || v.Road.MaxSpeed <= 0 {
+			return ColorNoData, true
+		}
+		return Color(1 - v.Speed/v.Road.MaxSpeed), true
+	case VehicleOverlayWaiting:
+		return Color(v.Trip.StoppedTime / MaxWaitSeconds), true
+	case VehicleOverlayDestination:
+		if v.TargetDespawn == nil {
+			return ColorNoData, true
+		}
+		hash := fnv.New32a()
+		hash.Write([]byte(v.TargetDespawn.ID))
+		return DestinationPalette[hash.Sum32()%uint32(len(DestinationPalette))], true
+	}
+	return color.RGBA{}, false
+}
+
+// RoadScale labels the low and high ends of the road overlay's scale.
+func (h *Heatmap) RoadScale() [2]string {
+	switch h.RoadMode {
+	case RoadOverlayDensity:
+		return [2]string{"0 veh/km", fmt.Sprintf("%.0f+ veh/km", JamDensity)}
+	case RoadOverlayVolume:
+		return [2]string{"0 veh/h", fmt.Sprintf("%.0f+ veh/h", MaxVolume)}
+	}
+	return [2]string{"free flow", "stopped"}
+}
+
+// VehicleScale labels the ends of the vehicle overlay's scale. The
+// destination overlay has no scale and gets empty labels.
+func (h *Heatmap) VehicleScale() [2]string {
+	switch h.VehicleMode {
+	case VehicleOverlayWaiting:
+		return [2]string{"0s", fmt.Sprintf("%.0fs+", MaxWaitSeconds)}
+	case VehicleOverlayDestination:
+		return [2]string{"", ""}
+	}
+	return [2]string{"at limit", "stopped"}
+}
+
+// Color maps 0..1 onto green, yellow and red.
+func Color(t float64) color.RGBA {
+	t = max(0, min(t, 1))
+	if t < 0.5 {
+		return color.RGBA{uint8(60 + 380*t), 190, 70, 255}
+	}
+	return color.RGBA{250, uint8(190 - 300*(t-0.5)), 70, 255}
+}
@@ -0,0 +1,30 @@
+package heatmap
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseOverlays(t *testing.T) {
+	if o, err := ParseRoadOverlay("AvgSpeed"); err != nil || o != RoadOverlayAvgSpeed {
+		t.Errorf("expected the average speed overlay, got %v, %v", o, err)
+	}
+	if o, err := ParseVehicleOverlay("destination"); err != nil || o != VehicleOverlayDestination {
+		t.Errorf("expected the destination overlay, got %v, %v", o, err)
+	}
+	if _, err := ParseRoadOverlay("waiting"); err == nil {
+		t.Error("expected waiting to be rejected as a road overlay")
+	}
+}
+
+func TestColor(t *testing.T) {
+	for v, want := range map[float64]color.RGBA{
+		-1:  {60, 190, 70, 255},
+		0.5: {250, 190, 70, 255},
+		2:   {250, 40, 70, 255},
+	} {
+		if got := Color(v); got != want {
+			t.Errorf("Color(%v): expected %v, got %v", v, want, got)
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package input
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math"
+	"path/filepath"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 
 	"traffic-sim/internal/commands"
+	"traffic-sim/internal/filedialog"
 	"traffic-sim/internal/query"
+	"traffic-sim/internal/replay"
 	"traffic-sim/internal/road"
 	"traffic-sim/internal/sim"
 	"traffic-sim/internal/tools"
@@ -29,6 +36,10 @@ const (
 	ModeRoadProperties
 	ModeSpawnPointProperties
 	ModeRoadCurving
+	ModeEmergency
+	ModeSpeedZone
+	ModeIncident
+	ModeDetector
 )
 
 type InputHandler struct {
@@ -43,14 +54,41 @@ type InputHandler struct {
 	roadPropTool     *tools.RoadPropertiesTool
 	spawnPointPropTool *tools.SpawnPointPropertiesTool
 	roadCurveTool    *tools.RoadCurveTool
+	emergencyTool    *tools.EmergencyTool
+	speedZoneTool    *tools.SpeedZoneTool
+	incidentTool     *tools.IncidentTool
+	detectorTool     *tools.DetectorTool
 	currentTool      tools.Tool
 	currentDragTool  tools.DragTool
 	mouseX, mouseY   int
 	Simulator 	     *sim.Simulator
 	roadPropertiesPanel interface{ Contains(x, y int) bool } 
 	spawnPointPropertiesPanel interface{ Contains(x, y int) bool }
+	intersectionPanel interface{ Contains(x, y int) bool }
+	selectedIntersection *road.Node
+	chartPanel       interface{ Contains(x, y int) bool }
+	chartRoad        *road.Road
 	world            *world.World
 	executor         *commands.CommandExecutor
+	query            *query.WorldQuery
+
+	recorder       *replay.Recorder
+	OnReplayOpened func(player *replay.Player, path string)
+	// RepairOnLoad repairs damaged saves as they are loaded instead of
+	// refusing them.
+	RepairOnLoad bool
+
+	fileBrowser  FileBrowser
+	confirmPanel interface{ IsVisible() bool }
+}
+
+// FileBrowser picks a save file inside the window when no native dialog
+// is available.
+type FileBrowser interface {
+	// Browse lists the saves and calls onPick with the chosen one. newPath,
+	// when set, is offered as a new file.
+	Browse(title, newPath string, onPick func(path string))
+	IsVisible() bool
 }
 
 func NewInputHandler(w *world.World, s *sim.Simulator) *InputHandler {
@@ -71,9 +109,14 @@ func NewInputHandler(w *world.World, s *sim.Simulator) *InputHandler {
 		roadPropTool:       toolSet.RoadProperties,
 		spawnPointPropTool: toolSet.SpawnPointProperties,
 		roadCurveTool:      toolSet.RoadCurving,
+		emergencyTool:      toolSet.Emergency,
+		speedZoneTool:      toolSet.SpeedZone,
+		incidentTool:       toolSet.Incident,
+		detectorTool:       toolSet.Detector,
 		Simulator:          s,
 		world:              w,
 		executor:           executor,
+		query:              q,
 	}
 }
 
@@ -119,6 +162,14 @@ func (h *InputHandler) SetMode(mode Mode) {
 		h.currentTool = h.spawnPointPropTool
 	case ModeRoadCurving:
 		h.currentTool = h.roadCurveTool
+	case ModeEmergency:
+		h.currentTool = h.emergencyTool
+	case ModeSpeedZone:
+		h.currentTool = h.speedZoneTool
+	case ModeIncident:
+		h.currentTool = h.incidentTool
+	case ModeDetector:
+		h.currentTool = h.detectorTool
 	}
 	
 	h.mode = mode
@@ -172,8 +223,30 @@ func (h *InputHandler) RoadCurveTool() *tools.RoadCurveTool {
     return h.roadCurveTool
 }
 
+func (h *InputHandler) EmergencyTool() *tools.EmergencyTool {
+	return h.emergencyTool
+}
+
+func (h *InputHandler) SpeedZoneTool() *tools.SpeedZoneTool {
+	return h.speedZoneTool
+}
+
+func (h *InputHandler) IncidentTool() *tools.IncidentTool {
+	return h.incidentTool
+}
+
+func (h *InputHandler) DetectorTool() *tools.DetectorTool {
+	return h.detectorTool
+}
+
 func (h *InputHandler) Update() {
 	h.mouseX, h.mouseY = ebiten.CursorPosition()
+	if h.fileBrowser != nil && h.fileBrowser.IsVisible() {
+		return
+	}
+	if h.confirmPanel != nil && h.confirmPanel.IsVisible() {
+		return
+	}
 	
 	h.handleModeSwitch()
 	h.handleToolInput()
@@ -183,33 +256,203 @@ func (h *InputHandler) Update() {
 func (h *InputHandler) handleSaveLoad() {
 	if ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta) {
 		if inpututil.IsKeyJustPressed(ebiten.KeyS) {
-			h.handleSave()
+			if ebiten.IsKeyPressed(ebiten.KeyShift) {
+				h.SaveSnapshot()
+			} else {
+				h.handleSave()
+			}
 		}
 		
 		if inpututil.IsKeyJustPressed(ebiten.KeyO) {
 			h.handleLoad()
 		}
+
+		if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+			h.ToggleRecording()
+		}
+
+		if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+			h.OpenReplay()
+		}
+
+		if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+			h.ExportMetrics()
+		}
+	}
+}
+
+func (h *InputHandler) SetFileBrowser(browser FileBrowser) {
+	h.fileBrowser = browser
+}
+
+// chooseFile asks for a save file with the native dialog and passes the
+// path to use, or opens the file browser instead when there is no native
+// dialog. It does nothing if the user cancels.
+func (h *InputHandler) chooseFile(title string, saving bool, use func(path string)) {
+	choose := filedialog.ChooseLoadFile
+	if saving {
+		choose = filedialog.ChooseSaveFile
+	}
+	path, err := choose()
+	if errors.Is(err, filedialog.ErrUnavailable) && h.fileBrowser != nil {
+		if !filedialog.Disabled {
+			log.Printf("%v; using the built-in file browser", err)
+		}
+		newPath := ""
+		if saving {
+			newPath = filedialog.DefaultSavePath()
+		}
+		h.fileBrowser.Browse(title, newPath, use)
+		return
+	}
+	if err != nil {
+		log.Printf("%s failed: %v", title, err)
+		return
+	}
+	if path != "" {
+		use(path)
 	}
 }
 
 func (h *InputHandler) handleSave() {
-	cmd := &commands.SaveWorldCommand{}
-	if err := h.executor.Execute(cmd); err != nil {
-		log.Printf("Failed to save world: %v", err)
+	h.chooseFile("Save Simulation", true, func(path string) {
+		cmd := &commands.SaveWorldCommand{Path: path}
+		if err := h.executor.Execute(cmd); err != nil {
+			log.Printf("Failed to save world: %v", err)
+		}
+	})
+}
+
+// SaveSnapshot saves the network together with vehicles and system state so
+// the run can be resumed exactly.
+func (h *InputHandler) SaveSnapshot() {
+	h.chooseFile("Save Snapshot", true, func(path string) {
+		err := h.Simulator.WithSystemState(func(systemState map[string]json.RawMessage) error {
+			cmd := &commands.SaveSnapshotCommand{Path: path, SystemState: systemState, Paused: h.Simulator.IsPaused()}
+			return cmd.Execute(h.world)
+		})
+		if err != nil {
+			log.Printf("Failed to save snapshot: %v", err)
+		}
+	})
+}
+
+// ToggleRecording starts or stops recording vehicle trajectories into the
+// recordings directory.
+func (h *InputHandler) ToggleRecording() {
+	if h.recorder != nil {
+		h.stopRecording()
+		return
+	}
+
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	path := filepath.Join(replay.RecordingDir, fmt.Sprintf("recording_%s%s", timestamp, replay.FileExt))
+
+	recorder, err := replay.NewRecorder(path, replay.DefaultInterval)
+	if err != nil {
+		log.Printf("Failed to start recording: %v", err)
+		return
+	}
+
+	h.recorder = recorder
+	h.Simulator.AddSystem(recorder)
+	log.Printf("Recording to: %s", path)
+}
+
+func (h *InputHandler) IsRecording() bool {
+	return h.recorder != nil
+}
+
+func (h *InputHandler) stopRecording() {
+	if h.recorder == nil {
+		return
+	}
+
+	h.Simulator.RemoveSystem(h.recorder)
+	if err := h.recorder.Close(); err != nil {
+		log.Printf("Failed to finish recording: %v", err)
+	} else {
+		log.Printf("Recording saved to: %s (%d frames)", h.recorder.Path(), h.recorder.Frames())
+	}
+	h.recorder = nil
+}
+
+// ExportMetrics writes the closed metrics intervals of the current run to
+// the metrics directory as CSV, with detector readings in a second file.
+func (h *InputHandler) ExportMetrics() {
+	collector := h.Simulator.Metrics()
+	if len(collector.Intervals()) == 0 {
+		log.Printf("No metrics yet: the first %.0fs interval has not finished", collector.Interval())
+		return
+	}
+
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	path := filepath.Join("metrics", fmt.Sprintf("metrics_%s.csv", timestamp))
+	if err := collector.ExportCSV(path); err != nil {
+		log.Printf("Failed to export metrics: %v", err)
+		return
+	}
+	log.Printf("Metrics exported to: %s", path)
+
+	if len(collector.DetectorIntervals()) == 0 {
+		return
+	}
+	path = filepath.Join("metrics", fmt.Sprintf("detectors_%s.csv", timestamp))
+	if err := collector.ExportDetectorCSV(path); err != nil {
+		log.Printf("Failed to export detector data: %v", err)
+		return
+	}
+	log.Printf("Detector data exported to: %s", path)
+}
+
+// OpenReplay asks for a recording and hands the loaded player to
+// OnReplayOpened.
+func (h *InputHandler) OpenReplay() {
+	path, err := filedialog.ChooseFile("Open Recording", "Recordings", replay.FileExt[1:], replay.RecordingDir)
+	if err != nil {
+		log.Printf("Failed to open recording: %v", err)
+		return
+	}
+	if path == "" {
+		return
+	}
+
+	player, err := replay.Open(path)
+	if err != nil {
+		log.Printf("Failed to open recording: %v", err)
+		return
+	}
+
+	if h.currentTool != nil {
+		h.currentTool.Cancel()
+	}
+	if h.OnReplayOpened != nil {
+		h.OnReplayOpened(player, path)
 	}
 }
 
 func (h *InputHandler) handleLoad() {
-	cmd := &commands.LoadWorldCommand{}
+	h.chooseFile("Load Simulation", false, func(path string) {
+		h.LoadWorld(path)
+	})
+}
+
+// LoadWorld loads a save file in place of the current world.
+func (h *InputHandler) LoadWorld(path string) {
+	cmd := &commands.LoadWorldCommand{Path: path, Repair: h.RepairOnLoad}
 	if err := cmd.Execute(h.world); err != nil {
 		log.Printf("Failed to load world: %v", err)
 	}
 }
 
 func (h *InputHandler) ReplaceWorld(newWorld *world.World) {
+	h.stopRecording()
 	h.world = newWorld
 	h.executor = commands.NewCommandExecutor(newWorld)
 	q := query.NewWorldQuery(newWorld)
+	h.query = q
+	h.selectedIntersection = nil
+	h.chartRoad = nil
 	factory := tools.NewToolFactory(h.executor, q)
 	toolSet := factory.CreateAll()
 	
@@ -223,12 +466,16 @@ func (h *InputHandler) ReplaceWorld(newWorld *world.World) {
 	h.roadPropTool = toolSet.RoadProperties
 	h.spawnPointPropTool = toolSet.SpawnPointProperties
 	h.roadCurveTool = toolSet.RoadCurving
+	h.emergencyTool = toolSet.Emergency
+	h.speedZoneTool = toolSet.SpeedZone
+	h.incidentTool = toolSet.Incident
+	h.detectorTool = toolSet.Detector
 	
 	h.SetMode(ModeNormal)
 }
 
 func (h *InputHandler) handleModeSwitch() {
-	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) && !ebiten.IsKeyPressed(ebiten.KeyControl) && !ebiten.IsKeyPressed(ebiten.KeyMeta) {
 		if h.mode == ModeNormal {
 			h.mode = ModeRoadBuilding
 		} else {
@@ -237,7 +484,7 @@ func (h *InputHandler) handleModeSwitch() {
 		}
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) && !ebiten.IsKeyPressed(ebiten.KeyControl) && !ebiten.IsKeyPressed(ebiten.KeyMeta) {
 		if h.mode == ModeNormal {
 			h.mode = ModeNodeMoving
 		} else {
@@ -300,7 +547,7 @@ func (h *InputHandler) handleModeSwitch() {
 		}
 	}
 	
-	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) && !ebiten.IsKeyPressed(ebiten.KeyControl) && !ebiten.IsKeyPressed(ebiten.KeyMeta) {
 		if h.mode == ModeNormal {
 			h.mode = ModeRoadProperties
 		} else {
@@ -318,7 +565,47 @@ func (h *InputHandler) handleModeSwitch() {
 		}
 	}
 	
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		if h.mode == ModeNormal {
+			h.mode = ModeEmergency
+		} else {
+			h.mode = ModeNormal
+			h.emergencyTool.Cancel()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		if h.mode == ModeNormal {
+			h.mode = ModeSpeedZone
+		} else {
+			h.mode = ModeNormal
+			h.speedZoneTool.Cancel()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		if h.mode == ModeNormal {
+			h.mode = ModeIncident
+		} else {
+			h.mode = ModeNormal
+			h.incidentTool.Cancel()
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		if h.mode == ModeNormal {
+			h.mode = ModeDetector
+		} else {
+			h.mode = ModeNormal
+			h.detectorTool.Cancel()
+		}
+	}
+
 	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if h.mode == ModeNormal {
+			h.selectedIntersection = nil
+			h.chartRoad = nil
+		}
 		h.mode = ModeNormal
 		h.roadTool.Cancel()
 		h.moveTool.EndDrag()
@@ -329,6 +616,10 @@ func (h *InputHandler) handleModeSwitch() {
 		h.trafficLightTool.Cancel()
 		h.roadPropTool.Cancel()
 		h.spawnPointPropTool.Cancel()
+		h.emergencyTool.Cancel()
+		h.speedZoneTool.Cancel()
+		h.incidentTool.Cancel()
+		h.detectorTool.Cancel()
 	}
 	
 	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
@@ -343,6 +634,10 @@ func (h *InputHandler) handleModeSwitch() {
 		h.despawnTool.CycleRoad()
 	}
 
+	if h.mode == ModeIncident && inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		h.incidentTool.CycleKind()
+	}
+
 	if h.mode == ModeTrafficLight && inpututil.IsKeyJustPressed(ebiten.KeyTab) {
 		h.trafficLightTool.CycleRoad()
 	}
@@ -357,7 +652,13 @@ func (h *InputHandler) handleModeSwitch() {
 }
 
 func (h *InputHandler) handleToolInput() {
+	if h.chartPanel != nil && h.chartPanel.Contains(h.mouseX, h.mouseY) {
+		return
+	}
+
 	switch h.mode {
+	case ModeNormal:
+		h.handleNormalInput()
 	case ModeRoadBuilding:
 		h.handleRoadBuildingInput()
 	case ModeNodeMoving:
@@ -378,9 +679,67 @@ func (h *InputHandler) handleToolInput() {
 		h.handleSpawnPointPropertiesInput()
 	case ModeRoadCurving:
 		h.handleRoadCurvingInput()
+	case ModeEmergency:
+		h.handleEmergencyInput()
+	case ModeSpeedZone:
+		h.handleSpeedZoneInput()
+	case ModeIncident:
+		h.handleIncidentInput()
+	case ModeDetector:
+		h.handleDetectorInput()
+	}
+}
+
+// handleNormalInput selects the intersection whose report is shown when a
+// node is clicked outside of any tool, or the road plotted by the chart
+// panel when a road is clicked away from its nodes.
+func (h *InputHandler) handleNormalInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if h.intersectionPanel != nil && h.intersectionPanel.Contains(h.mouseX, h.mouseY) {
+			return
+		}
+		if node := h.query.FindNearestNode(float64(h.mouseX), float64(h.mouseY), 15.0); node != nil {
+			h.selectedIntersection = node
+		} else if rd, _, _ := h.query.FindNearestRoad(float64(h.mouseX), float64(h.mouseY), 10.0); rd != nil {
+			h.chartRoad = rd
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		h.selectedIntersection = nil
+		h.chartRoad = nil
 	}
 }
 
+// ChartRoad is the road whose flow and speed the chart panel plots.
+func (h *InputHandler) ChartRoad() *road.Road {
+	return h.chartRoad
+}
+
+func (h *InputHandler) ClearChartRoad() {
+	h.chartRoad = nil
+}
+
+func (h *InputHandler) SelectedIntersection() *road.Node {
+	return h.selectedIntersection
+}
+
+func (h *InputHandler) ClearSelectedIntersection() {
+	h.selectedIntersection = nil
+}
+
+// ExportIntersections writes the intersection report of the current run to
+// the metrics directory as CSV.
+func (h *InputHandler) ExportIntersections() {
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	path := filepath.Join("metrics", fmt.Sprintf("intersections_%s.csv", timestamp))
+	if err := h.Simulator.Intersections().ExportCSV(h.world, path); err != nil {
+		log.Printf("Failed to export intersection report: %v", err)
+		return
+	}
+	log.Printf("Intersection report exported to: %s", path)
+}
+
 func (h *InputHandler) handleRoadBuildingInput() {
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		h.roadTool.Click(float64(h.mouseX), float64(h.mouseY))
@@ -471,6 +830,18 @@ func (h *InputHandler) SetSpawnPointPropertiesPanel(panel interface{ Contains(x,
 	h.spawnPointPropertiesPanel = panel
 }
 
+func (h *InputHandler) SetIntersectionPanel(panel interface{ Contains(x, y int) bool }) {
+	h.intersectionPanel = panel
+}
+
+func (h *InputHandler) SetChartPanel(panel interface{ Contains(x, y int) bool }) {
+	h.chartPanel = panel
+}
+
+func (h *InputHandler) SetConfirmPanel(panel interface{ IsVisible() bool }) {
+	h.confirmPanel = panel
+}
+
 func (h *InputHandler) handleTrafficLightInput() {
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		mouseX := float64(h.mouseX)
@@ -504,6 +875,80 @@ func (h *InputHandler) handleRoadCurvingInput() {
 	}
 }
 
+func (h *InputHandler) handleEmergencyInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if err := h.emergencyTool.Click(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to dispatch emergency vehicle: %v", err)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		h.emergencyTool.Cancel()
+	}
+}
+
+func (h *InputHandler) handleSpeedZoneInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if err := h.speedZoneTool.Click(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to add speed zone: %v", err)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if h.speedZoneTool.GetSelectedRoad() != nil {
+			h.speedZoneTool.Cancel()
+		} else if err := h.speedZoneTool.RemoveAt(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to remove speed zone: %v", err)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		h.speedZoneTool.AdjustLimit(5)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		h.speedZoneTool.AdjustLimit(-5)
+	}
+}
+
+func (h *InputHandler) handleIncidentInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if err := h.incidentTool.Click(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to create incident: %v", err)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if h.incidentTool.GetSelectedRoad() != nil {
+			h.incidentTool.Cancel()
+		} else if err := h.incidentTool.ClearAt(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to clear incident: %v", err)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		h.incidentTool.AdjustDuration(60)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		h.incidentTool.AdjustDuration(-60)
+	}
+}
+
+func (h *InputHandler) handleDetectorInput() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if err := h.detectorTool.Click(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to add detector: %v", err)
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		if err := h.detectorTool.RemoveAt(float64(h.mouseX), float64(h.mouseY)); err != nil {
+			log.Printf("Failed to remove detector: %v", err)
+		}
+	}
+}
+
 func (h *InputHandler) isMouseNearRoad(mouseX, mouseY float64, rd *road.Road) bool {
 	x1, y1 := rd.From.X, rd.From.Y
 	x2, y2 := rd.To.X, rd.To.Y
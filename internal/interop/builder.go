@@ -0,0 +1,163 @@
+package interop
+
+import (
+	"fmt"
+	"math"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// Builder adds imported nodes and roads to a world through the same
+// commands as the editor, so intersections and reverse pairs are wired up
+// the usual way.
+type Builder struct {
+	World *world.World
+	exec  *commands.CommandExecutor
+	nodes map[string]*road.Node
+}
+
+func NewBuilder(w *world.World) *Builder {
+	b := &Builder{World: w, exec: commands.NewCommandExecutor(w), nodes: make(map[string]*road.Node)}
+	for _, n := range w.Nodes {
+		b.nodes[n.ID] = n
+	}
+	return b
+}
+
+func (b *Builder) Node(id string) *road.Node {
+	return b.nodes[id]
+}
+
+// AddNode adds a node, or returns the existing one with the same ID.
+func (b *Builder) AddNode(id string, x, y float64) (*road.Node, error) {
+	if n := b.nodes[id]; n != nil {
+		return n, nil
+	}
+	if err := b.exec.Execute(&commands.CreateNodeCommand{X: x, Y: y, NodeID: id}); err != nil {
+		return nil, err
+	}
+	n := b.World.Nodes[len(b.World.Nodes)-1]
+	b.nodes[id] = n
+	return n, nil
+}
+
+// AddRoad connects two existing nodes. Road IDs are always "<from>-<to>",
+// so a second road between the same nodes in the same direction is
+// rejected.
+func (b *Builder) AddRoad(from, to string, maxSpeed, width float64, curve *road.RoadCurve) (*road.Road, error) {
+	fromNode, toNode := b.nodes[from], b.nodes[to]
+	if fromNode == nil || toNode == nil {
+		return nil, fmt.Errorf("road %s-%s references a missing node", from, to)
+	}
+	id := fmt.Sprintf("%s-%s", from, to)
+	if b.World.FindRoad(id) != nil {
+		return nil, fmt.Errorf("road %s already exists", id)
+	}
+	if err := b.exec.Execute(&commands.CreateRoadCommand{From: fromNode, To: toNode, MaxSpeed: maxSpeed, Width: width}); err != nil {
+		return nil, err
+	}
+	rd := b.World.Roads[len(b.World.Roads)-1]
+	rd.Curve = curve
+	return rd, nil
+}
+
+// AddSpawnPoint releases vehicles at the start of a road with the editor's
+// default settings.
+func (b *Builder) AddSpawnPoint(id string, rd *road.Road) (*road.SpawnPoint, error) {
+	if err := b.exec.Execute(&commands.CreateSpawnPointCommand{SpawnID: id, Node: rd.From, Road: rd}); err != nil {
+		return nil, err
+	}
+	return b.World.SpawnPoints[len(b.World.SpawnPoints)-1], nil
+}
+
+func (b *Builder) AddDespawnPoint(id string, rd *road.Road) (*road.DespawnPoint, error) {
+	if err := b.exec.Execute(&commands.CreateDespawnPointCommand{DespawnID: id, Node: rd.To, Road: rd}); err != nil {
+		return nil, err
+	}
+	return b.World.DespawnPoints[len(b.World.DespawnPoints)-1], nil
+}
+
+// AddTrafficLight puts a light on roads arriving at a node. Its state and
+// timing are the command's defaults until the caller sets them.
+func (b *Builder) AddTrafficLight(id, nodeID string, roads []*road.Road) (*road.TrafficLight, error) {
+	node := b.nodes[nodeID]
+	if node == nil {
+		return nil, fmt.Errorf("traffic light %s references missing node %s", id, nodeID)
+	}
+	n := len(b.World.TrafficLights)
+	if err := b.exec.Execute(&commands.CreateTrafficLightCommand{LightID: id, Node: node, Roads: roads}); err != nil {
+		return nil, err
+	}
+	if len(b.World.TrafficLights) == n {
+		return nil, fmt.Errorf("node %s has no intersection for traffic light %s", nodeID, id)
+	}
+	return b.World.TrafficLights[n], nil
+}
+
+// FitCurve approximates a polyline from its first to its last point with
+// one cubic Bezier whose ends follow the first and last segments. It
+// returns nil when the points lie within tolerance of a straight line.
+func FitCurve(points []road.Point, tolerance float64) *road.RoadCurve {
+	if len(points) < 3 {
+		return nil
+	}
+	p0, p3 := points[0], points[len(points)-1]
+
+	straight := true
+	for _, p := range points[1 : len(points)-1] {
+		if distanceToLine(p, p0, p3) > tolerance {
+			straight = false
+			break
+		}
+	}
+	if straight {
+		return nil
+	}
+
+	length := 0.0
+	for i := 1; i < len(points); i++ {
+		length += math.Hypot(points[i].X-points[i-1].X, points[i].Y-points[i-1].Y)
+	}
+	d0 := unit(points[0], points[1])
+	d1 := unit(points[len(points)-2], p3)
+	return &road.RoadCurve{
+		ControlP1: road.Point{X: p0.X + d0.X*length/3, Y: p0.Y + d0.Y*length/3},
+		ControlP2: road.Point{X: p3.X - d1.X*length/3, Y: p3.Y - d1.Y*length/3},
+	}
+}
+
+// ReverseCurve is the same curve driven the other way.
+func ReverseCurve(c *road.RoadCurve) *road.RoadCurve {
+	if c == nil {
+		return nil
+	}
+	return &road.RoadCurve{ControlP1: c.ControlP2, ControlP2: c.ControlP1}
+}
+
+// Turn is the absolute change of heading in radians at b on the way from a
+// to c.
+func Turn(a, b, c road.Point) float64 {
+	h1 := math.Atan2(b.Y-a.Y, b.X-a.X)
+	h2 := math.Atan2(c.Y-b.Y, c.X-b.X)
+	return math.Abs(math.Remainder(h2-h1, 2*math.Pi))
+}
+
+func unit(a, b road.Point) road.Point {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return road.Point{}
+	}
+	return road.Point{X: dx / l, Y: dy / l}
+}
+
+func distanceToLine(p, a, b road.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	return math.Abs(dy*(p.X-a.X)-dx*(p.Y-a.Y)) / l
+}
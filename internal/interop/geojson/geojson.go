@@ -0,0 +1,186 @@
+// Package geojson writes networks and their results as GeoJSON for GIS
+// tools, and reads line drawings back as roads.
+//
+// The world has no geographic reference of its own, so both directions
+// take the latitude and longitude of the world origin and project around
+// it.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// Curved roads are drawn with this many segments.
+const curveSegments = 16
+
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a Point or a LineString; other types are read but ignored.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Options places the world on the globe: the world origin sits at Lat,
+// Lon.
+type Options struct {
+	Lat, Lon float64
+	// Metrics, when set, adds each road's totals over these intervals to
+	// its properties.
+	Metrics []metrics.RoadInterval
+}
+
+func (o Options) projection() interop.Projection {
+	return interop.NewProjection(o.Lat, o.Lon)
+}
+
+// Export describes the network as Point features for nodes and LineString
+// features for roads. Roads are drawn where vehicles drive them, so the
+// two directions of a two-way road lie side by side. The world must not be
+// changing while it is exported.
+func Export(w *world.World, opts Options) *FeatureCollection {
+	proj := opts.projection()
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+
+	for _, n := range w.Nodes {
+		fc.Features = append(fc.Features, &Feature{
+			Type:       "Feature",
+			Geometry:   geometry("Point", position(proj, n.X, n.Y)),
+			Properties: map[string]any{"kind": "node", "id": n.ID},
+		})
+	}
+
+	totals := make(map[string]metrics.RoadInterval)
+	for _, row := range metrics.Summarize(opts.Metrics) {
+		totals[row.RoadID] = row
+	}
+
+	for _, rd := range w.Roads {
+		segments := 1
+		if rd.Curve != nil {
+			segments = curveSegments
+		}
+		line := make([][2]float64, 0, segments+1)
+		for i := 0; i <= segments; i++ {
+			x, y := rd.PosAt(rd.Length * float64(i) / float64(segments))
+			line = append(line, position(proj, x, y))
+		}
+
+		props := map[string]any{
+			"kind":     "road",
+			"id":       rd.ID,
+			"from":     rd.From.ID,
+			"to":       rd.To.ID,
+			"maxSpeed": rd.MaxSpeed,
+			"width":    rd.Width,
+			"length":   round(rd.Length, 2),
+			"reverse":  nil,
+		}
+		if rd.ReverseRoad != nil {
+			props["reverse"] = rd.ReverseRoad.ID
+		}
+		if row, ok := totals[rd.ID]; ok {
+			props["entered"] = row.Entered
+			props["exited"] = row.Exited
+			props["flow"] = round(row.Flow, 3)
+			props["density"] = round(row.Density, 3)
+			props["spaceMeanSpeed"] = round(row.SpaceMeanSpeed, 3)
+			props["delay"] = round(row.Delay, 3)
+		}
+		fc.Features = append(fc.Features, &Feature{Type: "Feature", Geometry: geometry("LineString", line), Properties: props})
+	}
+	return fc
+}
+
+func Write(out io.Writer, fc *FeatureCollection) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fc)
+}
+
+func Read(r io.Reader) (*FeatureCollection, error) {
+	var fc FeatureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("failed to parse GeoJSON: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("expected a FeatureCollection, got %q", fc.Type)
+	}
+	return &fc, nil
+}
+
+// position is a GeoJSON position, longitude first, to about a centimetre.
+func position(proj interop.Projection, x, y float64) [2]float64 {
+	lat, lon := proj.Inverse(x, y)
+	return [2]float64{round(lon, 7), round(lat, 7)}
+}
+
+func geometry(kind string, coords any) *Geometry {
+	data, _ := json.Marshal(coords)
+	return &Geometry{Type: kind, Coordinates: data}
+}
+
+func round(v float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(v*scale) / scale
+}
+
+func point(proj interop.Projection, g *Geometry) (road.Point, error) {
+	var c []float64
+	if err := json.Unmarshal(g.Coordinates, &c); err != nil || len(c) < 2 {
+		return road.Point{}, fmt.Errorf("invalid point coordinates %s", g.Coordinates)
+	}
+	x, y := proj.Forward(c[1], c[0])
+	return road.Point{X: x, Y: y}, nil
+}
+
+// points reads a LineString's coordinates into world positions.
+func points(proj interop.Projection, g *Geometry) ([]road.Point, error) {
+	var coords [][]float64
+	if err := json.Unmarshal(g.Coordinates, &coords); err != nil {
+		return nil, fmt.Errorf("invalid coordinates: %w", err)
+	}
+	pts := make([]road.Point, 0, len(coords))
+	for _, c := range coords {
+		if len(c) < 2 {
+			return nil, fmt.Errorf("position %v has fewer than two values", c)
+		}
+		x, y := proj.Forward(c[1], c[0])
+		pts = append(pts, road.Point{X: x, Y: y})
+	}
+	return pts, nil
+}
+
+// ParseOrigin reads an origin given as "lat,lon".
+func ParseOrigin(s string) (lat, lon float64, err error) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if ok {
+		lat, err = strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	}
+	if ok && err == nil {
+		lon, err = strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	}
+	if !ok || err != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+		return 0, 0, fmt.Errorf("invalid origin %q, expected lat,lon", s)
+	}
+	return lat, lon, nil
+}
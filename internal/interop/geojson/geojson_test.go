@@ -0,0 +1,142 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+var testOrigin = Options{Lat: 52, Lon: 13}
+
+// newTestWorld is a two-way street from a to b and a curved oneway road
+// from b to c.
+func newTestWorld(t *testing.T) *world.World {
+	t.Helper()
+	w := world.New()
+	b := interop.NewBuilder(w)
+	for _, n := range []struct {
+		id   string
+		x, y float64
+	}{{"a", 0, 0}, {"b", 200, 0}, {"c", 400, -200}} {
+		if _, err := b.AddNode(n.id, n.x, n.y); err != nil {
+			t.Fatalf("add node: %v", err)
+		}
+	}
+	curve := &road.RoadCurve{ControlP1: road.Point{X: 300, Y: 0}, ControlP2: road.Point{X: 400, Y: -100}}
+	for _, r := range []struct {
+		from, to string
+		curve    *road.RoadCurve
+	}{{"a", "b", nil}, {"b", "a", nil}, {"b", "c", curve}} {
+		if _, err := b.AddRoad(r.from, r.to, 15, 8, r.curve); err != nil {
+			t.Fatalf("add road: %v", err)
+		}
+	}
+	return w
+}
+
+func TestExport(t *testing.T) {
+	opts := testOrigin
+	opts.Metrics = []metrics.RoadInterval{
+		{RoadID: "a-b", Start: 0, End: 60, Entered: 3, Exited: 2, Density: 5, SpaceMeanSpeed: 12},
+	}
+	fc := Export(newTestWorld(t), opts)
+	if len(fc.Features) != 6 {
+		t.Fatalf("expected 3 points and 3 lines, got %d features", len(fc.Features))
+	}
+
+	var pos [2]float64
+	if err := json.Unmarshal(fc.Features[1].Geometry.Coordinates, &pos); err != nil {
+		t.Fatalf("node b: %v", err)
+	}
+	if math.Abs(pos[0]-13.0029) > 1e-3 || math.Abs(pos[1]-52) > 1e-6 {
+		t.Errorf("expected b 200m east of the origin, got %v", pos)
+	}
+
+	ab := fc.Features[3]
+	if ab.Properties["id"] != "a-b" || ab.Properties["reverse"] != "b-a" || ab.Properties["maxSpeed"] != 15.0 {
+		t.Errorf("unexpected properties %v", ab.Properties)
+	}
+	if ab.Properties["exited"] != 2 || ab.Properties["flow"] != 120.0 {
+		t.Errorf("expected metrics on a-b, got %v", ab.Properties)
+	}
+	if bc := fc.Features[5]; bc.Properties["reverse"] != nil || bc.Properties["flow"] != nil {
+		t.Errorf("expected no reverse and no metrics on b-c, got %v", bc.Properties)
+	}
+
+	var line [][2]float64
+	if err := json.Unmarshal(fc.Features[5].Geometry.Coordinates, &line); err != nil {
+		t.Fatalf("road b-c: %v", err)
+	}
+	if len(line) != curveSegments+1 {
+		t.Errorf("expected the curve sampled in %d segments, got %d positions", curveSegments, len(line))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, Export(newTestWorld(t), testOrigin)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	fc, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	w := world.New()
+	report, err := Import(w, fc, ImportOptions{Lat: testOrigin.Lat, Lon: testOrigin.Lon})
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if report.Nodes != 3 || report.Roads != 3 || len(report.Warnings) != 0 {
+		t.Fatalf("expected 3 nodes and 3 roads without warnings, got %+v", report)
+	}
+
+	ab, ba := w.FindRoad("a-b"), w.FindRoad("b-a")
+	if ab == nil || ba == nil || ab.ReverseRoad != ba {
+		t.Fatal("expected a-b and b-a to be a reverse pair")
+	}
+	if ab.Curve != nil || ab.MaxSpeed != 15 || ab.Width != 8 {
+		t.Errorf("expected a straight road at 15 m/s and 8m wide, got %+v", ab)
+	}
+	bc := w.FindRoad("b-c")
+	if bc == nil || bc.Curve == nil {
+		t.Fatal("expected b-c to come back curved")
+	}
+	if x, y := bc.PosAt(bc.Length / 2); math.Hypot(x-337.5, y+62.5) > 5 {
+		t.Errorf("expected the curve's midpoint near (337.5, -62.5), got (%.1f, %.1f)", x, y)
+	}
+}
+
+func TestImportDrawing(t *testing.T) {
+	w := newTestWorld(t)
+	// A new street from 3m beside node c to a new end point, and a oneway
+	// road from there back to b.
+	drawing := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","properties":{"maxSpeed":10},
+		 "geometry":{"type":"LineString","coordinates":[[13.0059,52.00182],[13.0059,52.0036]]}},
+		{"type":"Feature","properties":{"oneway":true},
+		 "geometry":{"type":"LineString","coordinates":[[13.0059,52.0036],[13.0029,52]]}}]}`
+	fc, err := Read(bytes.NewBufferString(drawing))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	report, err := Import(w, fc, ImportOptions{Lat: 52, Lon: 13})
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if report.Nodes != 1 || report.Roads != 3 {
+		t.Fatalf("expected 1 new node and 3 new roads, got %+v", report)
+	}
+	if rd := w.FindRoad("c-g1"); rd == nil || rd.ReverseRoad == nil || rd.MaxSpeed != 10 {
+		t.Errorf("expected a two-way street from c at 10 m/s, got %+v", rd)
+	}
+	if w.FindRoad("g1-b") == nil || w.FindRoad("b-g1") != nil {
+		t.Error("expected a oneway road from the new node to b")
+	}
+}
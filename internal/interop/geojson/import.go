@@ -0,0 +1,202 @@
+package geojson
+
+import (
+	"fmt"
+	"math"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// The editor's defaults for roads drawn without them.
+	defaultMaxSpeed = 40.0
+	defaultWidth    = 8.0
+
+	defaultSnap    = 2.0
+	curveTolerance = 0.5
+)
+
+// ImportOptions places the drawing on the world like Options does for
+// Export. A line's ends join the nearest node within Snap metres plus
+// half the road's width; zero means 2m.
+type ImportOptions struct {
+	Lat, Lon float64
+	Snap     float64
+}
+
+type Report struct {
+	Nodes    int
+	Roads    int
+	Skipped  int
+	Warnings []string
+}
+
+func (r *Report) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Import adds the Points and LineStrings of a feature collection to a
+// world, which may be empty or an existing network to extend.
+//
+// Points become nodes, named by their "id" property when they have one; a
+// point whose id is already in the world is taken to be that node.
+// Each LineString becomes a road from its first to its last position,
+// curved to follow the positions in between, with "maxSpeed" and "width"
+// taken from its properties. A line is a two-way road unless its "oneway"
+// property is true; lines written by Export carry a "reverse" property
+// and are imported as the single direction they describe, moved back
+// from their side of the road onto its axis.
+func Import(w *world.World, fc *FeatureCollection, opts ImportOptions) (*Report, error) {
+	if opts.Snap <= 0 {
+		opts.Snap = defaultSnap
+	}
+	proj := interop.NewProjection(opts.Lat, opts.Lon)
+	b := interop.NewBuilder(w)
+	report := &Report{}
+	nodes, roads := len(w.Nodes), len(w.Roads)
+
+	imp := &importer{b: b, report: report, snap: opts.Snap}
+	for i, f := range fc.Features {
+		if f.Geometry == nil || f.Geometry.Type != "Point" {
+			continue
+		}
+		p, err := point(proj, f.Geometry)
+		if err != nil {
+			report.warn("feature %d: %v", i, err)
+			report.Skipped++
+			continue
+		}
+		if kind, ok := f.Properties["kind"].(string); ok && kind != "node" {
+			continue
+		}
+		id, _ := f.Properties["id"].(string)
+		if id != "" && b.Node(id) != nil {
+			continue
+		}
+		if id == "" {
+			id = imp.nextID()
+		}
+		if _, err := b.AddNode(id, p.X, p.Y); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, f := range fc.Features {
+		if f.Geometry == nil || f.Geometry.Type == "Point" {
+			continue
+		}
+		if f.Geometry.Type != "LineString" {
+			report.warn("feature %d: %s geometry is not supported", i, f.Geometry.Type)
+			report.Skipped++
+			continue
+		}
+		pts, err := points(proj, f.Geometry)
+		if err == nil && len(pts) < 2 {
+			err = fmt.Errorf("a line needs at least two positions")
+		}
+		if err != nil {
+			report.warn("feature %d: %v", i, err)
+			report.Skipped++
+			continue
+		}
+		if err := imp.line(i, f.Properties, pts); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Nodes = len(w.Nodes) - nodes
+	report.Roads = len(w.Roads) - roads
+	return report, nil
+}
+
+type importer struct {
+	b       *interop.Builder
+	report  *Report
+	snap    float64
+	counter int
+}
+
+func (imp *importer) nextID() string {
+	for {
+		imp.counter++
+		id := fmt.Sprintf("g%d", imp.counter)
+		if imp.b.Node(id) == nil {
+			return id
+		}
+	}
+}
+
+func (imp *importer) line(index int, props map[string]any, pts []road.Point) error {
+	speed := number(props, "maxSpeed", defaultMaxSpeed)
+	width := number(props, "width", defaultWidth)
+	reverse, exported := props["reverse"]
+	oneway, _ := props["oneway"].(bool)
+
+	from, err := imp.end(props, "from", pts[0], width)
+	if err != nil {
+		return err
+	}
+	to, err := imp.end(props, "to", pts[len(pts)-1], width)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		imp.report.warn("feature %d: both ends join node %s", index, from.ID)
+		imp.report.Skipped++
+		return nil
+	}
+
+	curve := interop.FitCurve(pts, curveTolerance)
+	if exported && reverse != nil && curve != nil {
+		// Undo the offset that puts each direction on its own side.
+		dx, dy := to.X-from.X, to.Y-from.Y
+		if l := math.Hypot(dx, dy); l > 0 {
+			ox, oy := dy/l*width/2, -dx/l*width/2
+			curve.ControlP1.X += ox
+			curve.ControlP1.Y += oy
+			curve.ControlP2.X += ox
+			curve.ControlP2.Y += oy
+		}
+	}
+
+	if _, err := imp.b.AddRoad(from.ID, to.ID, speed, width, curve); err != nil {
+		imp.report.warn("feature %d: %v", index, err)
+	}
+	if !exported && !oneway {
+		if _, err := imp.b.AddRoad(to.ID, from.ID, speed, width, interop.ReverseCurve(curve)); err != nil {
+			imp.report.warn("feature %d: %v", index, err)
+		}
+	}
+	return nil
+}
+
+// end finds the node a line starts or ends at: the one named by its "from"
+// or "to" property, else the nearest within reach, else a new one.
+func (imp *importer) end(props map[string]any, key string, p road.Point, width float64) (*road.Node, error) {
+	if id, ok := props[key].(string); ok {
+		if n := imp.b.Node(id); n != nil {
+			return n, nil
+		}
+	}
+
+	var nearest *road.Node
+	best := imp.snap + width/2
+	for _, n := range imp.b.World.Nodes {
+		if d := math.Hypot(n.X-p.X, n.Y-p.Y); d <= best {
+			nearest, best = n, d
+		}
+	}
+	if nearest != nil {
+		return nearest, nil
+	}
+	return imp.b.AddNode(imp.nextID(), p.X, p.Y)
+}
+
+func number(props map[string]any, key string, def float64) float64 {
+	if v, ok := props[key].(float64); ok && v > 0 {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,338 @@
+// Package osm imports road networks from OpenStreetMap XML extracts.
+package osm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// LaneWidth is the width of one lane in metres; roads are as wide as
+	// their lanes in one direction.
+	LaneWidth = 3.5
+
+	// Shape nodes are folded into one curve while the way turns less than
+	// maxTurn in total; beyond that the way is split at a shape node.
+	maxTurn = math.Pi / 2
+	// Shape nodes closer than this to the straight line are dropped.
+	straightTolerance = 1.0
+)
+
+// Default speeds in km/h and lanes per direction for the highway types that
+// are imported. Anything else tagged highway (footways, cycleways, tracks,
+// ...) is skipped.
+var highways = map[string]struct {
+	speed float64
+	lanes int
+}{
+	"motorway":       {110, 2},
+	"motorway_link":  {60, 1},
+	"trunk":          {90, 2},
+	"trunk_link":     {50, 1},
+	"primary":        {70, 1},
+	"primary_link":   {50, 1},
+	"secondary":      {60, 1},
+	"secondary_link": {40, 1},
+	"tertiary":       {50, 1},
+	"tertiary_link":  {40, 1},
+	"unclassified":   {50, 1},
+	"residential":    {30, 1},
+	"living_street":  {10, 1},
+	"service":        {20, 1},
+	"road":           {50, 1},
+}
+
+type osmFile struct {
+	Bounds *struct {
+		MinLat float64 `xml:"minlat,attr"`
+		MinLon float64 `xml:"minlon,attr"`
+		MaxLat float64 `xml:"maxlat,attr"`
+		MaxLon float64 `xml:"maxlon,attr"`
+	} `xml:"bounds"`
+	Nodes []osmNode `xml:"node"`
+	Ways  []osmWay  `xml:"way"`
+}
+
+type osmNode struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type osmWay struct {
+	ID   int64 `xml:"id,attr"`
+	Refs []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []struct {
+		K string `xml:"k,attr"`
+		V string `xml:"v,attr"`
+	} `xml:"tag"`
+}
+
+func (w *osmWay) tag(k string) string {
+	for _, t := range w.Tags {
+		if t.K == k {
+			return t.V
+		}
+	}
+	return ""
+}
+
+// Report summarises an import.
+type Report struct {
+	Nodes       int
+	Roads       int
+	SkippedWays int
+	Warnings    []string
+}
+
+// way is a highway ready to be turned into roads.
+type way struct {
+	id       int64
+	refs     []int64
+	forward  bool
+	backward bool
+	speed    float64
+	width    float64
+}
+
+// Import reads an .osm extract and builds a world from its drivable
+// highways. Coordinates are projected around the centre of the extract's
+// bounds, or of its nodes when there are none.
+func Import(r io.Reader) (*world.World, *Report, error) {
+	var file osmFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OSM file: %w", err)
+	}
+
+	coords := make(map[int64]osmNode, len(file.Nodes))
+	for _, n := range file.Nodes {
+		coords[n.ID] = n
+	}
+
+	report := &Report{}
+	var ways []way
+	uses := make(map[int64]int)
+	for i := range file.Ways {
+		w, ok := parseWay(&file.Ways[i], coords, report)
+		if !ok {
+			report.SkippedWays++
+			continue
+		}
+		ways = append(ways, w)
+		for j, ref := range w.refs {
+			uses[ref]++
+			// Way ends always become nodes; counting them twice marks them.
+			if j == 0 || j == len(w.refs)-1 {
+				uses[ref]++
+			}
+		}
+	}
+
+	proj := projection(&file, ways, coords)
+	point := func(ref int64) road.Point {
+		n := coords[ref]
+		x, y := proj.Forward(n.Lat, n.Lon)
+		return road.Point{X: x, Y: y}
+	}
+
+	w := world.New()
+	b := interop.NewBuilder(w)
+	for _, wy := range ways {
+		for _, piece := range split(wy.refs, uses, point) {
+			from, to := nodeID(piece[0]), nodeID(piece[len(piece)-1])
+			if from == to {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("way %d: skipped a piece that starts and ends at node %s", wy.id, from))
+				continue
+			}
+
+			points := make([]road.Point, len(piece))
+			for i, ref := range piece {
+				points[i] = point(ref)
+			}
+			for _, ref := range []int64{piece[0], piece[len(piece)-1]} {
+				p := point(ref)
+				if _, err := b.AddNode(nodeID(ref), p.X, p.Y); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			curve := interop.FitCurve(points, straightTolerance)
+			if wy.forward {
+				addRoad(b, report, wy.id, from, to, wy, curve)
+			}
+			if wy.backward {
+				addRoad(b, report, wy.id, to, from, wy, interop.ReverseCurve(curve))
+			}
+		}
+	}
+
+	report.Nodes = len(w.Nodes)
+	report.Roads = len(w.Roads)
+	return w, report, nil
+}
+
+func addRoad(b *interop.Builder, report *Report, wayID int64, from, to string, wy way, curve *road.RoadCurve) {
+	if _, err := b.AddRoad(from, to, wy.speed, wy.width, curve); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("way %d: %v", wayID, err))
+	}
+}
+
+func nodeID(ref int64) string {
+	return fmt.Sprintf("osm%d", ref)
+}
+
+// parseWay reads the tags of a way, or reports false if it is not a
+// drivable highway or has too few known nodes.
+func parseWay(ow *osmWay, coords map[int64]osmNode, report *Report) (way, bool) {
+	highway := ow.tag("highway")
+	defaults, ok := highways[highway]
+	if !ok {
+		return way{}, false
+	}
+
+	w := way{id: ow.ID, forward: true, backward: true}
+	for _, nd := range ow.Refs {
+		if _, ok := coords[nd.Ref]; !ok {
+			continue
+		}
+		if len(w.refs) > 0 && w.refs[len(w.refs)-1] == nd.Ref {
+			continue
+		}
+		w.refs = append(w.refs, nd.Ref)
+	}
+	if len(w.refs) < 2 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("way %d: fewer than two nodes in the extract", ow.ID))
+		return way{}, false
+	}
+
+	roundabout := ow.tag("junction") == "roundabout" || ow.tag("junction") == "circular"
+	switch ow.tag("oneway") {
+	case "yes", "true", "1":
+		w.backward = false
+	case "-1", "reverse":
+		w.forward = false
+	case "no", "false", "0":
+	default:
+		if roundabout || highway == "motorway" || highway == "motorway_link" {
+			w.backward = false
+		}
+	}
+
+	kmh := defaults.speed
+	if v, ok := parseSpeed(ow.tag("maxspeed")); ok {
+		kmh = v
+	} else if v := ow.tag("maxspeed"); v != "" {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("way %d: unknown maxspeed %q, using %g km/h", ow.ID, v, kmh))
+	}
+	w.speed = kmh / 3.6
+
+	lanes := defaults.lanes
+	if n, err := strconv.Atoi(strings.TrimSpace(ow.tag("lanes"))); err == nil && n > 0 {
+		lanes = n
+		if w.forward && w.backward {
+			lanes = max(1, n/2)
+		}
+	}
+	w.width = float64(lanes) * LaneWidth
+
+	return w, true
+}
+
+// parseSpeed reads a maxspeed value in km/h or mph, e.g. "50", "50 km/h"
+// or "30 mph".
+func parseSpeed(v string) (float64, bool) {
+	v = strings.TrimSpace(v)
+	factor := 1.0
+	switch {
+	case strings.HasSuffix(v, "mph"):
+		v = strings.TrimSpace(strings.TrimSuffix(v, "mph"))
+		factor = 1.609344
+	case strings.HasSuffix(v, "km/h"):
+		v = strings.TrimSpace(strings.TrimSuffix(v, "km/h"))
+	case strings.HasSuffix(v, "kmh"):
+		v = strings.TrimSpace(strings.TrimSuffix(v, "kmh"))
+	}
+	speed, err := strconv.ParseFloat(v, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed * factor, true
+}
+
+// split cuts a way at its junctions, that is nodes shared with other ways or
+// where ways end, and where its shape turns too far for one curve. A closed
+// way with no junction besides its start is cut into three so no road runs
+// from a node to itself.
+func split(refs []int64, uses map[int64]int, point func(int64) road.Point) [][]int64 {
+	cut := make([]bool, len(refs))
+	cut[0], cut[len(refs)-1] = true, true
+	for i := 1; i < len(refs)-1; i++ {
+		cut[i] = uses[refs[i]] > 1
+	}
+
+	if refs[0] == refs[len(refs)-1] {
+		inner := 0
+		for i := 1; i < len(refs)-1; i++ {
+			if cut[i] {
+				inner++
+			}
+		}
+		if inner < 2 && len(refs) >= 4 {
+			cut[len(refs)/3] = true
+			cut[2*len(refs)/3] = true
+		}
+	}
+
+	turn := 0.0
+	for i := 1; i < len(refs)-1; i++ {
+		if cut[i] {
+			turn = 0
+			continue
+		}
+		turn += interop.Turn(point(refs[i-1]), point(refs[i]), point(refs[i+1]))
+		if turn > maxTurn {
+			cut[i] = true
+			turn = 0
+		}
+	}
+
+	var pieces [][]int64
+	start := 0
+	for i := 1; i < len(refs); i++ {
+		if cut[i] {
+			pieces = append(pieces, refs[start:i+1])
+			start = i
+		}
+	}
+	return pieces
+}
+
+func projection(file *osmFile, ways []way, coords map[int64]osmNode) interop.Projection {
+	if bb := file.Bounds; bb != nil && bb.MaxLat > bb.MinLat {
+		return interop.NewProjection((bb.MinLat+bb.MaxLat)/2, (bb.MinLon+bb.MaxLon)/2)
+	}
+
+	minLat, minLon := math.Inf(1), math.Inf(1)
+	maxLat, maxLon := math.Inf(-1), math.Inf(-1)
+	for _, w := range ways {
+		for _, ref := range w.refs {
+			n := coords[ref]
+			minLat, maxLat = min(minLat, n.Lat), max(maxLat, n.Lat)
+			minLon, maxLon = min(minLon, n.Lon), max(maxLon, n.Lon)
+		}
+	}
+	if len(ways) == 0 {
+		return interop.NewProjection(0, 0)
+	}
+	return interop.NewProjection((minLat+maxLat)/2, (minLon+maxLon)/2)
+}
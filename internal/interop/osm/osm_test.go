@@ -0,0 +1,132 @@
+package osm
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// A bent two-way street from 1 to 2, a oneway primary from 2 to 3, a
+// hexagonal roundabout through 3, a service road only open towards 1 and a
+// footway that must be ignored.
+const testOSM = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+  <bounds minlat="51.999" minlon="13.000" maxlat="52.001" maxlon="13.005"/>
+  <node id="1" lat="52.000" lon="13.000"/>
+  <node id="10" lat="52.0003" lon="13.0007"/>
+  <node id="11" lat="52.0003" lon="13.0013"/>
+  <node id="2" lat="52.000" lon="13.002"/>
+  <node id="3" lat="52.000" lon="13.004"/>
+  <node id="4" lat="52.000156" lon="13.004146"/>
+  <node id="5" lat="52.000156" lon="13.004438"/>
+  <node id="6" lat="52.000" lon="13.004584"/>
+  <node id="7" lat="51.999844" lon="13.004438"/>
+  <node id="8" lat="51.999844" lon="13.004146"/>
+  <node id="12" lat="51.999" lon="13.000"/>
+  <way id="100">
+    <nd ref="1"/><nd ref="10"/><nd ref="11"/><nd ref="2"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <way id="101">
+    <nd ref="2"/><nd ref="3"/>
+    <tag k="highway" v="primary"/>
+    <tag k="oneway" v="yes"/>
+    <tag k="maxspeed" v="30 mph"/>
+    <tag k="lanes" v="2"/>
+  </way>
+  <way id="102">
+    <nd ref="3"/><nd ref="4"/><nd ref="5"/><nd ref="6"/><nd ref="7"/><nd ref="8"/><nd ref="3"/>
+    <tag k="highway" v="tertiary"/>
+    <tag k="junction" v="roundabout"/>
+  </way>
+  <way id="103">
+    <nd ref="12"/><nd ref="1"/>
+    <tag k="highway" v="service"/>
+    <tag k="oneway" v="-1"/>
+  </way>
+  <way id="104">
+    <nd ref="1"/><nd ref="3"/>
+    <tag k="highway" v="footway"/>
+  </way>
+</osm>`
+
+func TestImport(t *testing.T) {
+	w, report, err := Import(strings.NewReader(testOSM))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if report.SkippedWays != 1 {
+		t.Errorf("expected the footway to be skipped, got %d skipped ways", report.SkippedWays)
+	}
+	if len(w.Roads) != 7 {
+		ids := make([]string, len(w.Roads))
+		for i, rd := range w.Roads {
+			ids[i] = rd.ID
+		}
+		t.Fatalf("expected 7 roads, got %d: %v", len(w.Roads), ids)
+	}
+	if len(w.Nodes) != 6 {
+		t.Errorf("expected shape nodes to be collapsed into 6 nodes, got %d", len(w.Nodes))
+	}
+
+	street, back := w.FindRoad("osm1-osm2"), w.FindRoad("osm2-osm1")
+	if street == nil || back == nil {
+		t.Fatal("expected the residential street in both directions")
+	}
+	if street.ReverseRoad != back || back.ReverseRoad != street {
+		t.Error("expected the two directions to be paired")
+	}
+	if math.Abs(street.MaxSpeed-30/3.6) > 1e-9 || street.Width != LaneWidth {
+		t.Errorf("expected residential defaults, got speed %v width %v", street.MaxSpeed, street.Width)
+	}
+	if street.Curve == nil {
+		t.Fatal("expected the bent street to be curved")
+	}
+	if back.Curve == nil || back.Curve.ControlP1 != street.Curve.ControlP2 {
+		t.Errorf("expected the reverse road to follow the same curve backwards")
+	}
+
+	primary := w.FindRoad("osm2-osm3")
+	if primary == nil || w.FindRoad("osm3-osm2") != nil {
+		t.Fatal("expected the oneway primary in one direction only")
+	}
+	if math.Abs(primary.MaxSpeed-30*1.609344/3.6) > 1e-9 {
+		t.Errorf("expected 30 mph, got %v m/s", primary.MaxSpeed)
+	}
+	if primary.Width != 2*LaneWidth {
+		t.Errorf("expected two lanes on a oneway road, got width %v", primary.Width)
+	}
+
+	for _, id := range []string{"osm3-osm5", "osm5-osm7", "osm7-osm3"} {
+		if w.FindRoad(id) == nil {
+			t.Errorf("expected roundabout road %s", id)
+		}
+	}
+	if w.FindRoad("osm5-osm3") != nil {
+		t.Error("expected the roundabout to be oneway")
+	}
+
+	if w.FindRoad("osm1-osm12") == nil || w.FindRoad("osm12-osm1") != nil {
+		t.Error("expected oneway=-1 to run against the way's direction")
+	}
+}
+
+func TestParseSpeed(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"50", 50, true},
+		{"50 km/h", 50, true},
+		{"20 mph", 20 * 1.609344, true},
+		{"signals", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseSpeed(tt.in)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("parseSpeed(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
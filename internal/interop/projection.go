@@ -0,0 +1,32 @@
+// Package interop holds what the network importers and exporters share: the
+// projection between WGS84 coordinates and the world plane, and a builder
+// that assembles a world through the editor's commands.
+package interop
+
+import "math"
+
+const earthRadius = 6378137.0
+
+// Projection is an equirectangular projection around an origin, accurate to
+// well under a percent across a city. World units are metres with x east
+// and y south, so north is up on screen.
+type Projection struct {
+	Lat0, Lon0 float64
+	cosLat0    float64
+}
+
+func NewProjection(lat0, lon0 float64) Projection {
+	return Projection{Lat0: lat0, Lon0: lon0, cosLat0: math.Cos(lat0 * math.Pi / 180)}
+}
+
+func (p Projection) Forward(lat, lon float64) (x, y float64) {
+	x = (lon - p.Lon0) * math.Pi / 180 * earthRadius * p.cosLat0
+	y = -(lat - p.Lat0) * math.Pi / 180 * earthRadius
+	return x, y
+}
+
+func (p Projection) Inverse(x, y float64) (lat, lon float64) {
+	lat = p.Lat0 - y/earthRadius*180/math.Pi
+	lon = p.Lon0 + x/(earthRadius*p.cosLat0)*180/math.Pi
+	return lat, lon
+}
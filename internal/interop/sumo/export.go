@@ -0,0 +1,362 @@
+package sumo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// DefaultDuration is how long exported flows run, in seconds.
+	DefaultDuration = 3600.0
+
+	vehicleLength = 4.5
+	// Curved roads are written as a polyline of this many segments.
+	shapeSegments = 16
+)
+
+// ExportOptions configures Export. A zero Duration means DefaultDuration.
+type ExportOptions struct {
+	Duration float64
+}
+
+// Report summarises an export or import.
+type Report struct {
+	Nodes         int
+	Roads         int
+	Lights        int
+	SpawnPoints   int
+	DespawnPoints int
+	Flows         int
+	Warnings      []string
+}
+
+func (r *Report) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Export converts a world to plain-XML files. The world must not be
+// changing while it is exported.
+//
+// Each intersection with enabled lights becomes a static program that
+// replays the lights' fixed-time cycles from their current state. Each
+// spawn point becomes one flow per despawn point it can reach, splitting
+// its rate evenly across every enabled despawn point as the spawn system
+// does.
+func Export(w *world.World, opts ExportOptions) (*Files, *Report) {
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultDuration
+	}
+	f := &Files{}
+	report := &Report{}
+
+	lightsAt := make(map[string][]*road.TrafficLight)
+	for _, tl := range w.TrafficLights {
+		if !tl.Enabled {
+			report.warn("traffic light %s is disabled and was not exported", tl.ID)
+			continue
+		}
+		if tl.Intersection != nil {
+			lightsAt[tl.Intersection.ID] = append(lightsAt[tl.Intersection.ID], tl)
+		}
+	}
+
+	for _, n := range w.Nodes {
+		node := nodeXML{ID: n.ID, X: round(n.X), Y: round(-n.Y), Type: "priority"}
+		if len(lightsAt[n.ID]) > 0 {
+			node.Type = "traffic_light"
+			node.TL = n.ID
+		}
+		f.nodes.Nodes = append(f.nodes.Nodes, node)
+	}
+
+	for _, rd := range w.Roads {
+		f.edges.Edges = append(f.edges.Edges, edgeXML{
+			ID:       rd.ID,
+			From:     rd.From.ID,
+			To:       rd.To.ID,
+			NumLanes: 1,
+			Speed:    round(rd.MaxSpeed),
+			Width:    round(rd.Width),
+			Shape:    shape(rd),
+		})
+	}
+
+	for _, n := range w.Nodes {
+		in := w.IntersectionsByNode[n.ID]
+		if in == nil {
+			continue
+		}
+		links := turns(in)
+		for _, l := range links {
+			f.connections.Connections = append(f.connections.Connections, connectionXML{From: l[0].ID, To: l[1].ID})
+		}
+		if lights := lightsAt[n.ID]; len(lights) > 0 {
+			exportLights(f, report, n.ID, lights, links)
+		}
+	}
+
+	exportRoutes(f, report, w, opts.Duration)
+
+	report.Nodes = len(f.nodes.Nodes)
+	report.Roads = len(f.edges.Edges)
+	report.Lights = len(f.lights.Logics)
+	report.Flows = len(f.routes.Flows)
+	return f, report
+}
+
+// turns lists the moves through an intersection as (incoming, outgoing)
+// pairs: every one except turning back onto the reverse road.
+func turns(in *road.Intersection) [][2]*road.Road {
+	var links [][2]*road.Road
+	for _, from := range in.Incoming {
+		for _, to := range in.Outgoing {
+			if to.From == from.To && to.To == from.From {
+				continue
+			}
+			links = append(links, [2]*road.Road{from, to})
+		}
+	}
+	return links
+}
+
+// shape samples a curved road along its axis, without the offset that
+// separates a two-way pair; SUMO spreads the lanes of each direction
+// itself.
+func shape(rd *road.Road) string {
+	if rd.Curve == nil {
+		return ""
+	}
+	p0 := road.Point{X: rd.From.X + rd.StartOffset.X, Y: rd.From.Y + rd.StartOffset.Y}
+	p3 := road.Point{X: rd.To.X + rd.EndOffset.X, Y: rd.To.Y + rd.EndOffset.Y}
+	p1, p2 := rd.Curve.ControlP1, rd.Curve.ControlP2
+
+	coords := make([]string, 0, shapeSegments+1)
+	for i := 0; i <= shapeSegments; i++ {
+		t := float64(i) / shapeSegments
+		u := 1 - t
+		x := u*u*u*p0.X + 3*u*u*t*p1.X + 3*u*t*t*p2.X + t*t*t*p3.X
+		y := u*u*u*p0.Y + 3*u*u*t*p1.Y + 3*u*t*t*p2.Y + t*t*t*p3.Y
+		coords = append(coords, formatFloat(x)+","+formatFloat(-y))
+	}
+	return strings.Join(coords, " ")
+}
+
+// segment is one state of a light's cycle.
+type segment struct {
+	state      road.LightState
+	afterGreen bool
+	duration   float64
+}
+
+// cycle lists a light's states for one full cycle starting now. Lights
+// run green, yellow, red, yellow, so the yellow before green is exported
+// as SUMO's red-yellow.
+func cycle(tl *road.TrafficLight) []segment {
+	durations := map[road.LightState]float64{
+		road.LightGreen:  tl.GreenTime,
+		road.LightYellow: tl.YellowTime,
+		road.LightRed:    tl.RedTime,
+	}
+	state, afterGreen := tl.State, tl.PrevState == road.LightGreen
+	first := segment{state: state, afterGreen: afterGreen, duration: max(0, durations[state]-tl.Timer)}
+
+	segments := []segment{first}
+	for range 3 {
+		switch state {
+		case road.LightGreen:
+			state, afterGreen = road.LightYellow, true
+		case road.LightRed:
+			state, afterGreen = road.LightYellow, false
+		default:
+			if afterGreen {
+				state = road.LightRed
+			} else {
+				state = road.LightGreen
+			}
+		}
+		segments = append(segments, segment{state: state, afterGreen: afterGreen, duration: durations[state]})
+	}
+	// Close the cycle with what is left of the state it started in.
+	segments = append(segments, segment{state: first.state, afterGreen: first.afterGreen, duration: durations[first.state] - first.duration})
+	return segments
+}
+
+func cycleLength(segments []segment) float64 {
+	total := 0.0
+	for _, s := range segments {
+		total += s.duration
+	}
+	return total
+}
+
+func stateAt(segments []segment, t float64) segment {
+	t = math.Mod(t, cycleLength(segments))
+	for _, s := range segments {
+		if t < s.duration {
+			return s
+		}
+		t -= s.duration
+	}
+	return segments[len(segments)-1]
+}
+
+func signal(s segment) byte {
+	switch {
+	case s.state == road.LightGreen:
+		return 'G'
+	case s.state == road.LightRed:
+		return 'r'
+	case s.afterGreen:
+		return 'y'
+	default:
+		return 'u'
+	}
+}
+
+func exportLights(f *Files, report *Report, nodeID string, lights []*road.TrafficLight, links [][2]*road.Road) {
+	cycles := make(map[*road.TrafficLight][]segment, len(lights))
+	length, mixed := 0.0, false
+	for _, tl := range lights {
+		c := cycle(tl)
+		if l := cycleLength(c); l > 0 {
+			cycles[tl] = c
+			mixed = mixed || (length > 0 && math.Abs(l-length) > 1e-6)
+			length = max(length, l)
+		}
+	}
+	if length == 0 {
+		report.warn("lights at %s have no timing and were not exported", nodeID)
+		return
+	}
+	if mixed {
+		report.warn("lights at %s have different cycle lengths; the program only covers the longest, %gs", nodeID, length)
+	}
+
+	controller := make(map[*road.Road]*road.TrafficLight)
+	for _, tl := range lights {
+		for _, rd := range tl.ControlledRoads {
+			if controller[rd] == nil {
+				controller[rd] = tl
+			}
+		}
+	}
+
+	// The program changes whenever any light does.
+	changes := []float64{0}
+	for _, c := range cycles {
+		for start := 0.0; start < length; {
+			for _, s := range c {
+				start += s.duration
+				if start < length {
+					changes = append(changes, start)
+				}
+			}
+		}
+	}
+	sort.Float64s(changes)
+
+	logic := tlLogicXML{ID: nodeID, Type: "static", ProgramID: "0"}
+	for i, t := range changes {
+		end := length
+		if i+1 < len(changes) {
+			end = changes[i+1]
+		}
+		if end-t < 1e-6 {
+			continue
+		}
+		state := make([]byte, len(links))
+		for j, l := range links {
+			tl := controller[l[0]]
+			if c := cycles[tl]; c != nil {
+				state[j] = signal(stateAt(c, t))
+			} else {
+				state[j] = 'O'
+			}
+		}
+		logic.Phases = append(logic.Phases, phaseXML{Duration: round(end - t), State: string(state)})
+	}
+	f.lights.Logics = append(f.lights.Logics, logic)
+
+	for i, l := range links {
+		f.lights.Connections = append(f.lights.Connections, tlConnectionXML{From: l[0].ID, To: l[1].ID, TL: nodeID, LinkIndex: i})
+	}
+}
+
+func exportRoutes(f *Files, report *Report, w *world.World, duration float64) {
+	var despawns []*road.DespawnPoint
+	for _, dp := range w.DespawnPoints {
+		if dp.Enabled {
+			despawns = append(despawns, dp)
+		}
+	}
+
+	for _, sp := range w.SpawnPoints {
+		if !sp.Enabled || sp.Interval <= 0 {
+			continue
+		}
+		if len(despawns) == 0 {
+			report.warn("spawn point %s has no enabled despawn point to drive to", sp.ID)
+			continue
+		}
+		speed := min((sp.MinSpeed+sp.MaxSpeed)/2, sp.Road.MaxSpeed)
+		for _, dp := range despawns {
+			if !reachable(w, sp.Road, dp.Road) {
+				report.warn("despawn point %s cannot be reached from spawn point %s; its share of the demand was dropped", dp.ID, sp.ID)
+				continue
+			}
+			f.routes.Flows = append(f.routes.Flows, flowXML{
+				ID:          sp.ID + "." + dp.ID,
+				Type:        "car",
+				End:         duration,
+				Period:      round(sp.Interval * float64(len(despawns))),
+				From:        sp.Road.ID,
+				To:          dp.Road.ID,
+				DepartSpeed: formatFloat(max(0, speed)),
+			})
+		}
+	}
+	if len(f.routes.Flows) > 0 {
+		f.routes.VTypes = []vTypeXML{{ID: "car", Length: vehicleLength}}
+	}
+}
+
+// reachable reports whether a vehicle on from can drive onto to without
+// U-turns.
+func reachable(w *world.World, from, to *road.Road) bool {
+	seen := map[*road.Road]bool{from: true}
+	queue := []*road.Road{from}
+	for len(queue) > 0 {
+		rd := queue[0]
+		queue = queue[1:]
+		if rd == to {
+			return true
+		}
+		in := w.IntersectionsByNode[rd.To.ID]
+		if in == nil {
+			continue
+		}
+		for _, next := range in.Outgoing {
+			if seen[next] || (next.From == rd.To && next.To == rd.From) {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return false
+}
+
+// round keeps centimetres, and turns -0 into 0.
+func round(v float64) float64 {
+	return math.Round(v*100)/100 + 0
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(round(v), 'f', -1, 64)
+}
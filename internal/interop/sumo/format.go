@@ -0,0 +1,194 @@
+// Package sumo converts networks to and from SUMO's plain-XML format, the
+// node, edge, connection and traffic light files that netconvert builds a
+// .net.xml from, plus a route file with the spawn points' demand.
+//
+// SUMO's y axis points north while the world's points south, so y is
+// negated both ways. Every road is one lane as wide as the road, since
+// vehicles here drive in single file.
+package sumo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// File suffixes appended to the prefix given to Write and ReadFiles.
+const (
+	NodesSuffix       = ".nod.xml"
+	EdgesSuffix       = ".edg.xml"
+	ConnectionsSuffix = ".con.xml"
+	LightsSuffix      = ".tll.xml"
+	RoutesSuffix      = ".rou.xml"
+)
+
+// Files holds a network in plain-XML form, one field per file.
+type Files struct {
+	nodes       nodesXML
+	edges       edgesXML
+	connections connectionsXML
+	lights      tlLogicsXML
+	routes      routesXML
+}
+
+type nodesXML struct {
+	XMLName xml.Name  `xml:"nodes"`
+	Nodes   []nodeXML `xml:"node"`
+}
+
+type nodeXML struct {
+	ID   string  `xml:"id,attr"`
+	X    float64 `xml:"x,attr"`
+	Y    float64 `xml:"y,attr"`
+	Type string  `xml:"type,attr,omitempty"`
+	TL   string  `xml:"tl,attr,omitempty"`
+}
+
+type edgesXML struct {
+	XMLName xml.Name  `xml:"edges"`
+	Edges   []edgeXML `xml:"edge"`
+}
+
+type edgeXML struct {
+	ID       string  `xml:"id,attr"`
+	From     string  `xml:"from,attr"`
+	To       string  `xml:"to,attr"`
+	NumLanes int     `xml:"numLanes,attr,omitempty"`
+	Speed    float64 `xml:"speed,attr,omitempty"`
+	Width    float64 `xml:"width,attr,omitempty"`
+	Shape    string  `xml:"shape,attr,omitempty"`
+}
+
+type connectionsXML struct {
+	XMLName     xml.Name        `xml:"connections"`
+	Connections []connectionXML `xml:"connection"`
+}
+
+type connectionXML struct {
+	From     string `xml:"from,attr"`
+	To       string `xml:"to,attr"`
+	FromLane int    `xml:"fromLane,attr"`
+	ToLane   int    `xml:"toLane,attr"`
+}
+
+// tlLogicsXML is a .tll.xml file: the programs, and the connections they
+// control with each one's index into the phase state strings.
+type tlLogicsXML struct {
+	XMLName     xml.Name          `xml:"tlLogics"`
+	Logics      []tlLogicXML      `xml:"tlLogic"`
+	Connections []tlConnectionXML `xml:"connection"`
+}
+
+type tlLogicXML struct {
+	ID        string     `xml:"id,attr"`
+	Type      string     `xml:"type,attr"`
+	ProgramID string     `xml:"programID,attr"`
+	Offset    float64    `xml:"offset,attr"`
+	Phases    []phaseXML `xml:"phase"`
+}
+
+type phaseXML struct {
+	Duration float64 `xml:"duration,attr"`
+	State    string  `xml:"state,attr"`
+}
+
+type tlConnectionXML struct {
+	From      string `xml:"from,attr"`
+	To        string `xml:"to,attr"`
+	FromLane  int    `xml:"fromLane,attr"`
+	ToLane    int    `xml:"toLane,attr"`
+	TL        string `xml:"tl,attr"`
+	LinkIndex int    `xml:"linkIndex,attr"`
+}
+
+type routesXML struct {
+	XMLName xml.Name   `xml:"routes"`
+	VTypes  []vTypeXML `xml:"vType"`
+	Flows   []flowXML  `xml:"flow"`
+}
+
+type vTypeXML struct {
+	ID     string  `xml:"id,attr"`
+	Length float64 `xml:"length,attr"`
+}
+
+type flowXML struct {
+	ID          string  `xml:"id,attr"`
+	Type        string  `xml:"type,attr,omitempty"`
+	Begin       float64 `xml:"begin,attr"`
+	End         float64 `xml:"end,attr"`
+	Period      float64 `xml:"period,attr,omitempty"`
+	VehsPerHour float64 `xml:"vehsPerHour,attr,omitempty"`
+	From        string  `xml:"from,attr"`
+	To          string  `xml:"to,attr"`
+	DepartSpeed string  `xml:"departSpeed,attr,omitempty"`
+}
+
+// Write saves the files as <prefix>.nod.xml, <prefix>.edg.xml and so on.
+// The traffic light and route files are only written when they have
+// content.
+func (f *Files) Write(prefix string) error {
+	if err := writeXML(prefix+NodesSuffix, f.nodes); err != nil {
+		return err
+	}
+	if err := writeXML(prefix+EdgesSuffix, f.edges); err != nil {
+		return err
+	}
+	if err := writeXML(prefix+ConnectionsSuffix, f.connections); err != nil {
+		return err
+	}
+	if len(f.lights.Logics) > 0 {
+		if err := writeXML(prefix+LightsSuffix, f.lights); err != nil {
+			return err
+		}
+	}
+	if len(f.routes.Flows) > 0 {
+		if err := writeXML(prefix+RoutesSuffix, f.routes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFiles loads the files written for a prefix. The node and edge files
+// are required; the others are read when they exist. Connections are not
+// read back, since vehicles here may take every turn but a U-turn.
+func ReadFiles(prefix string) (*Files, error) {
+	f := &Files{}
+	if err := readXML(prefix+NodesSuffix, &f.nodes); err != nil {
+		return nil, err
+	}
+	if err := readXML(prefix+EdgesSuffix, &f.edges); err != nil {
+		return nil, err
+	}
+	if err := readXML(prefix+LightsSuffix, &f.lights); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := readXML(prefix+RoutesSuffix, &f.routes); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return f, nil
+}
+
+func writeXML(path string, v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+	return os.WriteFile(path, data, 0644)
+}
+
+func readXML(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package sumo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// SUMO's defaults for edges that leave them out.
+	defaultSpeed     = 13.89
+	defaultLaneWidth = 3.2
+
+	curveTolerance = 0.5
+)
+
+// Import builds a world from plain-XML files. Roads are named
+// "<from>-<to>" whatever their edge ID was, so parallel edges between the
+// same nodes collapse into the first. A road is as wide as all its lanes.
+//
+// Each traffic light program becomes one fixed-time light per group of
+// incoming edges that share a signal, with the green, yellow and red times
+// read off the program. Flows become spawn points on their first edge and
+// despawn points on their last.
+func Import(f *Files) (*world.World, *Report, error) {
+	w := world.New()
+	b := interop.NewBuilder(w)
+	report := &Report{}
+
+	for _, n := range f.nodes.Nodes {
+		if b.Node(n.ID) != nil {
+			report.warn("duplicate node %s skipped", n.ID)
+			continue
+		}
+		if _, err := b.AddNode(n.ID, n.X, -n.Y); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	edges := make(map[string]*road.Road)
+	for _, e := range f.edges.Edges {
+		from, to := b.Node(e.From), b.Node(e.To)
+		if from == nil || to == nil {
+			report.warn("edge %s references a missing node and was skipped", e.ID)
+			continue
+		}
+		speed := e.Speed
+		if speed <= 0 {
+			speed = defaultSpeed
+		}
+		laneWidth := e.Width
+		if laneWidth <= 0 {
+			laneWidth = defaultLaneWidth
+		}
+		curve, err := edgeCurve(e.Shape, from, to)
+		if err != nil {
+			report.warn("edge %s: %v; imported as a straight road", e.ID, err)
+		}
+		rd, err := b.AddRoad(e.From, e.To, speed, laneWidth*float64(max(1, e.NumLanes)), curve)
+		if err != nil {
+			report.warn("edge %s: %v", e.ID, err)
+			continue
+		}
+		edges[e.ID] = rd
+	}
+
+	lightNodes := make(map[string]string)
+	for _, n := range f.nodes.Nodes {
+		if n.TL != "" {
+			lightNodes[n.TL] = n.ID
+		} else if strings.Contains(n.Type, "traffic_light") {
+			lightNodes[n.ID] = n.ID
+		}
+	}
+	seen := make(map[string]bool)
+	for _, logic := range f.lights.Logics {
+		if seen[logic.ID] {
+			report.warn("traffic light %s: only the first program was imported", logic.ID)
+			continue
+		}
+		seen[logic.ID] = true
+		if err := importLogic(b, report, logic, lightNodes, f.lights.Connections, edges); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := importRoutes(b, report, f.routes.Flows, edges); err != nil {
+		return nil, nil, err
+	}
+
+	report.Nodes = len(w.Nodes)
+	report.Roads = len(w.Roads)
+	report.Lights = len(w.TrafficLights)
+	report.SpawnPoints = len(w.SpawnPoints)
+	report.DespawnPoints = len(w.DespawnPoints)
+	report.Flows = len(f.routes.Flows)
+	return w, report, nil
+}
+
+// edgeCurve fits a curve to an edge's shape, which may or may not repeat
+// the node positions at its ends.
+func edgeCurve(shape string, from, to *road.Node) (*road.RoadCurve, error) {
+	if shape == "" {
+		return nil, nil
+	}
+	points := []road.Point{{X: from.X, Y: from.Y}}
+	for _, pair := range strings.Fields(shape) {
+		xs, ys, ok := strings.Cut(pair, ",")
+		if !ok {
+			return nil, fmt.Errorf("invalid shape point %q", pair)
+		}
+		x, err := strconv.ParseFloat(xs, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shape point %q", pair)
+		}
+		y, err := strconv.ParseFloat(ys, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shape point %q", pair)
+		}
+		points = appendPoint(points, road.Point{X: x, Y: -y})
+	}
+	end := road.Point{X: to.X, Y: to.Y}
+	if last := points[len(points)-1]; math.Hypot(last.X-end.X, last.Y-end.Y) < curveTolerance {
+		points = points[:len(points)-1]
+	}
+	points = append(points, end)
+	return interop.FitCurve(points, curveTolerance), nil
+}
+
+func appendPoint(points []road.Point, p road.Point) []road.Point {
+	last := points[len(points)-1]
+	if math.Hypot(p.X-last.X, p.Y-last.Y) < curveTolerance {
+		return points
+	}
+	return append(points, p)
+}
+
+func importLogic(b *interop.Builder, report *Report, logic tlLogicXML, lightNodes map[string]string, connections []tlConnectionXML, edges map[string]*road.Road) error {
+	nodeID := lightNodes[logic.ID]
+	if nodeID == "" {
+		nodeID = logic.ID
+	}
+	if b.Node(nodeID) == nil {
+		report.warn("traffic light %s controls no known node and was skipped", logic.ID)
+		return nil
+	}
+	if len(logic.Phases) == 0 {
+		report.warn("traffic light %s has no phases and was skipped", logic.ID)
+		return nil
+	}
+	if logic.Offset != 0 {
+		report.warn("traffic light %s: offset %gs ignored", logic.ID, logic.Offset)
+	}
+
+	// Each incoming edge takes the signal of its first link.
+	var order []*road.Road
+	linkOf := make(map[*road.Road]int)
+	for _, c := range connections {
+		if c.TL != logic.ID {
+			continue
+		}
+		rd := edges[c.From]
+		if rd == nil {
+			continue
+		}
+		if first, ok := linkOf[rd]; !ok {
+			linkOf[rd] = c.LinkIndex
+			order = append(order, rd)
+		} else if column(logic, first) != column(logic, c.LinkIndex) {
+			report.warn("traffic light %s: edge %s has separate signals per turn; using link %d", logic.ID, c.From, first)
+		}
+	}
+
+	groups := make(map[string][]*road.Road)
+	var columns []string
+	for _, rd := range order {
+		col := column(logic, linkOf[rd])
+		if strings.Contains(col, "?") {
+			report.warn("traffic light %s: link %d is missing from its phases", logic.ID, linkOf[rd])
+			continue
+		}
+		if groups[col] == nil {
+			columns = append(columns, col)
+		}
+		groups[col] = append(groups[col], rd)
+	}
+
+	for _, col := range columns {
+		timing, ok := lightTiming(logic, col)
+		if !ok {
+			report.warn("traffic light %s: signal %q never changes and was not imported", logic.ID, col)
+			continue
+		}
+		id := fmt.Sprintf("tl%d", len(b.World.TrafficLights)+1)
+		tl, err := b.AddTrafficLight(id, nodeID, groups[col])
+		if err != nil {
+			return err
+		}
+		tl.GreenTime, tl.YellowTime, tl.RedTime = timing.green, timing.yellow, timing.red
+		tl.State, tl.PrevState, tl.Timer = timing.state, timing.prev, timing.elapsed
+	}
+	return nil
+}
+
+// column is one link's signal through the phases, one class per phase: G
+// for green, y for yellow after green, u for yellow after red, r for red
+// and O for no signal.
+func column(logic tlLogicXML, link int) string {
+	col := make([]byte, len(logic.Phases))
+	for i, p := range logic.Phases {
+		if link < 0 || link >= len(p.State) {
+			col[i] = '?'
+			continue
+		}
+		switch p.State[link] {
+		case 'G', 'g':
+			col[i] = 'G'
+		case 'y':
+			col[i] = 'y'
+		case 'u':
+			col[i] = 'u'
+		case 'O', 'o':
+			col[i] = 'O'
+		default:
+			col[i] = 'r'
+		}
+	}
+	return string(col)
+}
+
+type timing struct {
+	green, yellow, red float64
+	state, prev        road.LightState
+	elapsed            float64
+}
+
+// lightTiming turns a signal column into a fixed-time cycle. A light here
+// shows yellow twice per cycle, so the yellow time is that of the first
+// yellow after green and red takes the rest of the cycle.
+func lightTiming(logic tlLogicXML, col string) (timing, bool) {
+	if strings.Trim(col, string(col[0])) == "" {
+		return timing{}, false
+	}
+
+	var t timing
+	total := 0.0
+	for i, p := range logic.Phases {
+		total += p.Duration
+		if col[i] == 'G' {
+			t.green += p.Duration
+		}
+	}
+	if i := strings.Index(col, "y"); i >= 0 {
+		for j := i; col[j%len(col)] == 'y'; j++ {
+			t.yellow += logic.Phases[j%len(col)].Duration
+		}
+	}
+	t.red = max(0, total-t.green-2*t.yellow)
+
+	switch col[0] {
+	case 'G':
+		t.state = road.LightGreen
+	case 'y':
+		t.state, t.prev = road.LightYellow, road.LightGreen
+	case 'u':
+		t.state, t.prev = road.LightYellow, road.LightRed
+	default:
+		t.state = road.LightRed
+	}
+	// Time already spent in the starting state, counting back from the end
+	// of the cycle.
+	for i := len(col) - 1; i > 0 && col[i] == col[0]; i-- {
+		t.elapsed += logic.Phases[i].Duration
+	}
+	return t, true
+}
+
+func importRoutes(b *interop.Builder, report *Report, flows []flowXML, edges map[string]*road.Road) error {
+	type demand struct {
+		road  *road.Road
+		rate  float64
+		speed float64
+	}
+	var spawns []*demand
+	byRoad := make(map[*road.Road]*demand)
+	var despawns []*road.Road
+	despawnAt := make(map[*road.Road]bool)
+
+	for _, fl := range flows {
+		from, to := edges[fl.From], edges[fl.To]
+		if from == nil || to == nil {
+			report.warn("flow %s references a missing edge and was skipped", fl.ID)
+			continue
+		}
+		var rate float64
+		switch {
+		case fl.Period > 0:
+			rate = 1 / fl.Period
+		case fl.VehsPerHour > 0:
+			rate = fl.VehsPerHour / 3600
+		default:
+			report.warn("flow %s has no period or vehsPerHour and was skipped", fl.ID)
+			continue
+		}
+
+		d := byRoad[from]
+		if d == nil {
+			d = &demand{road: from}
+			byRoad[from] = d
+			spawns = append(spawns, d)
+		}
+		d.rate += rate
+		if speed, err := strconv.ParseFloat(fl.DepartSpeed, 64); err == nil && speed > 0 {
+			d.speed = speed
+		}
+		if !despawnAt[to] {
+			despawnAt[to] = true
+			despawns = append(despawns, to)
+		}
+	}
+
+	for i, d := range spawns {
+		sp, err := b.AddSpawnPoint(fmt.Sprintf("sp%d", i+1), d.road)
+		if err != nil {
+			return err
+		}
+		sp.Interval = 1 / d.rate
+		if d.speed > 0 {
+			sp.MinSpeed, sp.MaxSpeed = d.speed, d.speed
+		}
+	}
+	for i, rd := range despawns {
+		if _, err := b.AddDespawnPoint(fmt.Sprintf("dp%d", i+1), rd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package sumo
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"traffic-sim/internal/interop"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// newTestWorld is a two-way street from a to b, a curved oneway road from b
+// to c, and a side street from d into b. Lights at b alternate between the
+// street and the side street. Vehicles enter on the street and the side
+// street and leave on b-c or back along the street, which the street's own
+// traffic cannot reach without a U-turn.
+func newTestWorld(t *testing.T) *world.World {
+	t.Helper()
+	w := world.New()
+	b := interop.NewBuilder(w)
+	for _, n := range []struct {
+		id   string
+		x, y float64
+	}{{"a", 0, 0}, {"b", 200, 0}, {"c", 400, -200}, {"d", 200, 200}} {
+		if _, err := b.AddNode(n.id, n.x, n.y); err != nil {
+			t.Fatalf("add node: %v", err)
+		}
+	}
+	roads := make(map[string]*road.Road)
+	for _, r := range []struct {
+		from, to string
+		curve    *road.RoadCurve
+	}{
+		{"a", "b", nil},
+		{"b", "a", nil},
+		{"b", "c", &road.RoadCurve{ControlP1: road.Point{X: 300, Y: 0}, ControlP2: road.Point{X: 400, Y: -100}}},
+		{"d", "b", nil},
+	} {
+		rd, err := b.AddRoad(r.from, r.to, 15, 7, r.curve)
+		if err != nil {
+			t.Fatalf("add road: %v", err)
+		}
+		roads[rd.ID] = rd
+	}
+
+	if _, err := b.AddTrafficLight("tl1", "b", []*road.Road{roads["a-b"]}); err != nil {
+		t.Fatalf("add light: %v", err)
+	}
+	if _, err := b.AddTrafficLight("tl2", "b", []*road.Road{roads["d-b"]}); err != nil {
+		t.Fatalf("add light: %v", err)
+	}
+
+	for i, id := range []string{"a-b", "d-b"} {
+		sp, err := b.AddSpawnPoint(fmt.Sprintf("sp%d", i+1), roads[id])
+		if err != nil {
+			t.Fatalf("add spawn point: %v", err)
+		}
+		sp.Interval, sp.MinSpeed, sp.MaxSpeed = 4, 10, 14
+	}
+	if _, err := b.AddDespawnPoint("dp1", roads["b-c"]); err != nil {
+		t.Fatalf("add despawn point: %v", err)
+	}
+	if _, err := b.AddDespawnPoint("dp2", roads["b-a"]); err != nil {
+		t.Fatalf("add despawn point: %v", err)
+	}
+	return w
+}
+
+func TestExport(t *testing.T) {
+	f, report := Export(newTestWorld(t), ExportOptions{})
+
+	if len(f.nodes.Nodes) != 4 || len(f.edges.Edges) != 4 {
+		t.Fatalf("expected 4 nodes and 4 edges, got %d and %d", len(f.nodes.Nodes), len(f.edges.Edges))
+	}
+	for _, n := range f.nodes.Nodes {
+		if n.ID == "b" && (n.Type != "traffic_light" || n.TL != "b") {
+			t.Errorf("expected b to be a traffic light node, got %+v", n)
+		}
+		if n.ID == "c" && n.Y != 200 {
+			t.Errorf("expected y to be flipped, got %v", n.Y)
+		}
+	}
+	for _, e := range f.edges.Edges {
+		if (e.ID == "b-c") != (e.Shape != "") {
+			t.Errorf("expected only the curved road to have a shape, %s has %q", e.ID, e.Shape)
+		}
+	}
+
+	// a-b and d-b each lead on to b-c and the other incoming road's reverse,
+	// less the U-turn: a-b to b-c, d-b to b-a and d-b to b-c.
+	if len(f.connections.Connections) != 3 {
+		t.Errorf("expected 3 connections, got %+v", f.connections.Connections)
+	}
+
+	if len(f.lights.Logics) != 1 {
+		t.Fatalf("expected one program, got %d", len(f.lights.Logics))
+	}
+	// Links: a-b to b-c, then d-b to b-a and b-c. The side street's light
+	// is green while the street's is red.
+	want := []phaseXML{{8, "Grr"}, {2, "yuu"}, {8, "rGG"}, {2, "uyy"}}
+	got := f.lights.Logics[0].Phases
+	if len(got) != len(want) {
+		t.Fatalf("expected phases %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("phase %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	wantFlows := []string{"sp1.dp1", "sp2.dp1", "sp2.dp2"}
+	if len(f.routes.Flows) != len(wantFlows) {
+		t.Fatalf("expected flows %v, got %+v", wantFlows, f.routes.Flows)
+	}
+	for i, fl := range f.routes.Flows {
+		if fl.ID != wantFlows[i] || fl.Period != 8 || fl.DepartSpeed != "12" {
+			t.Errorf("unexpected flow %+v", fl)
+		}
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("expected a warning about sp1's unreachable despawn point, got %v", report.Warnings)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	f, _ := Export(newTestWorld(t), ExportOptions{})
+	prefix := filepath.Join(t.TempDir(), "net")
+	if err := f.Write(prefix); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	read, err := ReadFiles(prefix)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	w, report, err := Import(read)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("expected no import warnings, got %v", report.Warnings)
+	}
+
+	ab, ba := w.FindRoad("a-b"), w.FindRoad("b-a")
+	if ab == nil || ba == nil || ab.ReverseRoad != ba {
+		t.Fatal("expected a-b and b-a to be a reverse pair")
+	}
+	if ab.MaxSpeed != 15 || ab.Width != 7 {
+		t.Errorf("expected speed 15 and width 7, got %v and %v", ab.MaxSpeed, ab.Width)
+	}
+	bc := w.FindRoad("b-c")
+	if bc == nil || bc.Curve == nil {
+		t.Fatal("expected b-c to come back curved")
+	}
+	if x, y := bc.PosAt(bc.Length / 2); math.Hypot(x-337.5, y+62.5) > 5 {
+		t.Errorf("expected the curve's midpoint near (337.5, -62.5), got (%.1f, %.1f)", x, y)
+	}
+
+	if len(w.TrafficLights) != 2 {
+		t.Fatalf("expected 2 lights, got %d", len(w.TrafficLights))
+	}
+	for _, tl := range w.TrafficLights {
+		if tl.GreenTime != 8 || tl.YellowTime != 2 || tl.RedTime != 8 {
+			t.Errorf("light %s: expected 8/2/8, got %v/%v/%v", tl.ID, tl.GreenTime, tl.YellowTime, tl.RedTime)
+		}
+		green := tl.ControlledRoads[0] == ab
+		if (tl.State == road.LightGreen) != green {
+			t.Errorf("light on %s starts %v", tl.ControlledRoads[0].ID, tl.State)
+		}
+	}
+
+	if len(w.SpawnPoints) != 2 || len(w.DespawnPoints) != 2 {
+		t.Fatalf("expected 2 spawn and 2 despawn points, got %d and %d", len(w.SpawnPoints), len(w.DespawnPoints))
+	}
+	// The street's spawn point lost the half of its demand SUMO cannot route.
+	if sp := w.SpawnPoints[0]; sp.Road != ab || sp.Interval != 8 || sp.MinSpeed != 12 {
+		t.Errorf("unexpected spawn point %+v", sp)
+	}
+	if sp := w.SpawnPoints[1]; sp.Road.ID != "d-b" || sp.Interval != 4 {
+		t.Errorf("unexpected spawn point %+v", sp)
+	}
+}
@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"sync"
+	"traffic-sim/internal/world"
+)
+
+// DefaultInterval is the aggregation period in sim seconds.
+const DefaultInterval = 60.0
+
+// RoadInterval holds what was measured on one road during one interval.
+// Distances are in metres and speeds in metres per second.
+type RoadInterval struct {
+	RoadID         string  `json:"roadId"`
+	Start          float64 `json:"start"`
+	End            float64 `json:"end"`
+	Entered        int     `json:"entered"`
+	Exited         int     `json:"exited"`
+	Flow           float64 `json:"flow"`
+	Density        float64 `json:"density"`
+	SpaceMeanSpeed float64 `json:"spaceMeanSpeed"`
+	Delay          float64 `json:"delay"`
+}
+
+type roadAccumulator struct {
+	entered     int
+	exited      int
+	vehicleTime float64
+	distance    float64
+	delay       float64
+}
+
+// Collector is a system that aggregates per-road flow, density, space-mean
+// speed and delay over fixed intervals of sim time. It is safe to query
+// while the simulation runs.
+type Collector struct {
+	mu sync.RWMutex
+
+	interval      float64
+	started       bool
+	intervalStart float64
+
+	current   map[string]*roadAccumulator
+	lastRoad  map[string]string
+	intervals []RoadInterval
+
+	detectorBase      map[string]detectorTotals
+	detectorIntervals []DetectorInterval
+}
+
+func NewCollector(interval float64) *Collector {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Collector{
+		interval: interval,
+		current:  make(map[string]*roadAccumulator),
+		lastRoad: make(map[string]string),
+
+		detectorBase: make(map[string]detectorTotals),
+	}
+}
+
+func (c *Collector) Interval() float64 {
+	return c.interval
+}
+
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.started = false
+	c.current = make(map[string]*roadAccumulator)
+	c.lastRoad = make(map[string]string)
+	c.intervals = nil
+	c.detectorBase = make(map[string]detectorTotals)
+	c.detectorIntervals = nil
+}
+
+func (c *Collector) Update(w *world.World, dt float64) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started {
+		c.started = true
+		c.intervalStart = w.SimTime - dt
+		for _, v := range w.Vehicles {
+			c.lastRoad[v.ID] = v.Road.ID
+		}
+		for _, rd := range w.Roads {
+			for _, d := range rd.Detectors {
+				c.detectorBase[d.ID] = totalsOf(d)
+			}
+		}
+		return
+	}
+
+	present := make(map[string]bool, len(w.Vehicles))
+	for _, v := range w.Vehicles {
+		present[v.ID] = true
+
+		roadID := v.Road.ID
+		if prev, seen := c.lastRoad[v.ID]; !seen || prev != roadID {
+			if seen {
+				c.acc(prev).exited++
+			}
+			c.acc(roadID).entered++
+			c.lastRoad[v.ID] = roadID
+		}
+
+		if v.InTransition {
+			continue
+		}
+
+		acc := c.acc(roadID)
+		acc.vehicleTime += dt
+		acc.distance += v.Speed * dt
+		if v.Road.MaxSpeed > 0 {
+			if lost := dt - v.Speed*dt/v.Road.MaxSpeed; lost > 0 {
+				acc.delay += lost
+			}
+		}
+	}
+
+	for id, roadID := range c.lastRoad {
+		if !present[id] {
+			c.acc(roadID).exited++
+			delete(c.lastRoad, id)
+		}
+	}
+
+	if w.SimTime-c.intervalStart >= c.interval {
+		c.closeInterval(w, w.SimTime)
+	}
+}
+
+// Flush closes the interval in progress, e.g. at the end of a run, so its
+// partial results show up in Intervals and exports.
+func (c *Collector) Flush(w *world.World) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started && w.SimTime > c.intervalStart {
+		c.closeInterval(w, w.SimTime)
+	}
+}
+
+func (c *Collector) acc(roadID string) *roadAccumulator {
+	acc := c.current[roadID]
+	if acc == nil {
+		acc = &roadAccumulator{}
+		c.current[roadID] = acc
+	}
+	return acc
+}
+
+func (c *Collector) closeInterval(w *world.World, end float64) {
+	duration := end - c.intervalStart
+
+	for _, rd := range w.Roads {
+		acc := c.current[rd.ID]
+		if acc == nil {
+			acc = &roadAccumulator{}
+		}
+
+		row := RoadInterval{
+			RoadID:  rd.ID,
+			Start:   c.intervalStart,
+			End:     end,
+			Entered: acc.entered,
+			Exited:  acc.exited,
+			Flow:    float64(acc.exited) * 3600 / duration,
+			Delay:   acc.delay,
+		}
+		if rd.Length > 0 {
+			row.Density = acc.vehicleTime / duration / (rd.Length / 1000)
+		}
+		if acc.vehicleTime > 0 {
+			row.SpaceMeanSpeed = acc.distance / acc.vehicleTime
+		}
+
+		c.intervals = append(c.intervals, row)
+		c.closeDetectors(rd, end)
+	}
+
+	c.current = make(map[string]*roadAccumulator)
+	c.intervalStart = end
+}
+
+// Intervals returns every closed interval for every road, oldest first.
+func (c *Collector) Intervals() []RoadInterval {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]RoadInterval(nil), c.intervals...)
+}
+
+func (c *Collector) ForRoad(roadID string) []RoadInterval {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var rows []RoadInterval
+	for _, row := range c.intervals {
+		if row.RoadID == roadID {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Latest returns the rows of the most recently closed interval.
+func (c *Collector) Latest() []RoadInterval {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.intervals) == 0 {
+		return nil
+	}
+
+	end := c.intervals[len(c.intervals)-1].End
+	i := len(c.intervals)
+	for i > 0 && c.intervals[i-1].End == end {
+		i--
+	}
+	return append([]RoadInterval(nil), c.intervals[i:]...)
+}
+
+// Summarize folds the intervals of each road into one row spanning all of
+// them, in the order roads first appear. Density and speed are averaged
+// over time and over vehicle-time respectively; counts and delay add up.
+func Summarize(rows []RoadInterval) []RoadInterval {
+	var order []string
+	byRoad := make(map[string]*RoadInterval)
+	speedWeight := make(map[string]float64)
+
+	for _, row := range rows {
+		sum := byRoad[row.RoadID]
+		if sum == nil {
+			sum = &RoadInterval{RoadID: row.RoadID, Start: row.Start, End: row.End}
+			byRoad[row.RoadID] = sum
+			order = append(order, row.RoadID)
+		}
+		duration := row.End - row.Start
+		sum.Start = min(sum.Start, row.Start)
+		sum.End = max(sum.End, row.End)
+		sum.Entered += row.Entered
+		sum.Exited += row.Exited
+		sum.Delay += row.Delay
+		// Density times duration is proportional to vehicle-time.
+		sum.Density += row.Density * duration
+		sum.SpaceMeanSpeed += row.SpaceMeanSpeed * row.Density * duration
+		speedWeight[row.RoadID] += row.Density * duration
+	}
+
+	summary := make([]RoadInterval, len(order))
+	for i, id := range order {
+		sum := byRoad[id]
+		if duration := sum.End - sum.Start; duration > 0 {
+			sum.Flow = float64(sum.Exited) * 3600 / duration
+			sum.Density /= duration
+		}
+		if w := speedWeight[id]; w > 0 {
+			sum.SpaceMeanSpeed /= w
+		}
+		summary[i] = *sum
+	}
+	return summary
+}
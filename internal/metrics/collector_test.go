@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+func step(c *Collector, w *world.World, dt float64) {
+	w.SimTime += dt
+	c.Update(w, dt)
+}
+
+func TestCollectorAggregatesPerRoad(t *testing.T) {
+	w := world.New()
+	n1 := &road.Node{ID: "n1", X: 0, Y: 0}
+	n2 := &road.Node{ID: "n2", X: 500, Y: 0}
+	n3 := &road.Node{ID: "n3", X: 1000, Y: 0}
+	r1 := road.NewRoad("r1", n1, n2, 20)
+	r2 := road.NewRoad("r2", n2, n3, 20)
+	w.Roads = append(w.Roads, r1, r2)
+
+	c := NewCollector(10)
+	step(c, w, 0.5)
+
+	v := &vehicle.Vehicle{ID: "v1", Road: r1, Speed: 10}
+	w.Vehicles = append(w.Vehicles, v)
+	for i := 0; i < 10; i++ {
+		step(c, w, 0.5)
+	}
+
+	v.Road = r2
+	for i := 0; i < 9; i++ {
+		step(c, w, 0.5)
+	}
+
+	rows := c.Latest()
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per road, got %d", len(rows))
+	}
+
+	first := c.ForRoad("r1")[0]
+	if first.Entered != 1 || first.Exited != 1 {
+		t.Errorf("r1: expected 1 in and 1 out, got %d/%d", first.Entered, first.Exited)
+	}
+	if math.Abs(first.SpaceMeanSpeed-10) > 1e-9 {
+		t.Errorf("r1: expected space-mean speed 10, got %f", first.SpaceMeanSpeed)
+	}
+	// 5 vehicle-seconds over a 10s interval on a 0.5km road.
+	if math.Abs(first.Density-1) > 1e-9 {
+		t.Errorf("r1: expected density 1 veh/km, got %f", first.Density)
+	}
+	// Half of free-flow speed loses half of every second.
+	if math.Abs(first.Delay-2.5) > 1e-9 {
+		t.Errorf("r1: expected 2.5s delay, got %f", first.Delay)
+	}
+
+	second := c.ForRoad("r2")[0]
+	if second.Entered != 1 || second.Exited != 0 {
+		t.Errorf("r2: expected 1 in and 0 out, got %d/%d", second.Entered, second.Exited)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, c.Intervals()); err != nil {
+		t.Fatalf("csv export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "road_id,") {
+		t.Errorf("unexpected csv output:\n%s", buf.String())
+	}
+}
+
+func TestCollectorCountsDespawnAsExit(t *testing.T) {
+	w := world.New()
+	r1 := road.NewRoad("r1", &road.Node{ID: "n1"}, &road.Node{ID: "n2", X: 100}, 20)
+	w.Roads = append(w.Roads, r1)
+	w.Vehicles = append(w.Vehicles, &vehicle.Vehicle{ID: "v1", Road: r1, Speed: 20})
+
+	c := NewCollector(60)
+	step(c, w, 1)
+	step(c, w, 1)
+	w.Vehicles = nil
+	step(c, w, 1)
+	c.Flush(w)
+
+	rows := c.Intervals()
+	if len(rows) != 1 || rows[0].Entered != 0 || rows[0].Exited != 1 {
+		t.Fatalf("expected a single exit and no entry for a vehicle present at start, got %+v", rows)
+	}
+	if rows[0].End != 3 || rows[0].Start != 0 {
+		t.Errorf("expected flushed interval 0-3, got %.1f-%.1f", rows[0].Start, rows[0].End)
+	}
+}
+
+func TestLiveRoads(t *testing.T) {
+	w := world.New()
+	r1 := road.NewRoad("r1", &road.Node{ID: "n1"}, &road.Node{ID: "n2", X: 500}, 20)
+	r2 := road.NewRoad("r2", &road.Node{ID: "n2", X: 500}, &road.Node{ID: "n3", X: 1000}, 20)
+	w.Roads = append(w.Roads, r1, r2)
+	w.Vehicles = append(w.Vehicles,
+		&vehicle.Vehicle{ID: "v1", Road: r1, Speed: 10},
+		&vehicle.Vehicle{ID: "v2", Road: r1, Speed: 0},
+		&vehicle.Vehicle{ID: "v3", Road: r2, Speed: 20, InTransition: true},
+	)
+
+	live := LiveRoads(w)
+	if s := live["r1"]; s.Vehicles != 2 || s.SpeedRatio != 0.25 || s.Density != 4 {
+		t.Errorf("r1: unexpected snapshot %+v", s)
+	}
+	if s := live["r2"]; s.Vehicles != 0 || s.SpeedRatio != 1 {
+		t.Errorf("r2: expected empty road at free flow, got %+v", s)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	rows := []RoadInterval{
+		{RoadID: "r1", Start: 0, End: 60, Entered: 4, Exited: 3, Density: 10, SpaceMeanSpeed: 10, Delay: 5},
+		{RoadID: "r2", Start: 0, End: 60},
+		{RoadID: "r1", Start: 60, End: 120, Entered: 2, Exited: 3, Density: 30, SpaceMeanSpeed: 2, Delay: 20},
+		{RoadID: "r2", Start: 60, End: 120},
+	}
+	summary := Summarize(rows)
+	if len(summary) != 2 || summary[0].RoadID != "r1" || summary[1].RoadID != "r2" {
+		t.Fatalf("expected one row per road in order, got %+v", summary)
+	}
+
+	r1 := summary[0]
+	if r1.Start != 0 || r1.End != 120 || r1.Entered != 6 || r1.Exited != 6 || r1.Delay != 25 {
+		t.Errorf("unexpected totals %+v", r1)
+	}
+	if r1.Flow != 180 || r1.Density != 20 {
+		t.Errorf("expected flow 180 and density 20, got %v and %v", r1.Flow, r1.Density)
+	}
+	// Three times as much vehicle-time was spent at 2 m/s as at 10 m/s.
+	if math.Abs(r1.SpaceMeanSpeed-4) > 1e-9 {
+		t.Errorf("expected space-mean speed 4, got %v", r1.SpaceMeanSpeed)
+	}
+	if summary[1].SpaceMeanSpeed != 0 || summary[1].Flow != 0 {
+		t.Errorf("expected an empty road to stay empty, got %+v", summary[1])
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"traffic-sim/internal/road"
+)
+
+// DetectorInterval is one aggregation period of a loop detector, in the
+// form field detectors report it. Occupancy is the share of the period the
+// loop was covered, from 0 to 1, and SpotSpeed the mean speed of the
+// vehicles counted, in metres per second.
+type DetectorInterval struct {
+	DetectorID string  `json:"detectorId"`
+	RoadID     string  `json:"roadId"`
+	Position   float64 `json:"position"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Count      int     `json:"count"`
+	Flow       float64 `json:"flow"`
+	Occupancy  float64 `json:"occupancy"`
+	SpotSpeed  float64 `json:"spotSpeed"`
+}
+
+// detectorTotals are a detector's running totals at the start of the
+// current interval.
+type detectorTotals struct {
+	count        int
+	occupiedTime float64
+	speedSum     float64
+}
+
+func totalsOf(d *road.Detector) detectorTotals {
+	return detectorTotals{count: d.Count, occupiedTime: d.OccupiedTime, speedSum: d.SpeedSum}
+}
+
+func (c *Collector) closeDetectors(rd *road.Road, end float64) {
+	duration := end - c.intervalStart
+
+	for _, d := range rd.Detectors {
+		base := c.detectorBase[d.ID]
+		now := totalsOf(d)
+		c.detectorBase[d.ID] = now
+
+		row := DetectorInterval{
+			DetectorID: d.ID,
+			RoadID:     rd.ID,
+			Position:   d.Position,
+			Start:      c.intervalStart,
+			End:        end,
+			Count:      now.count - base.count,
+			Flow:       float64(now.count-base.count) * 3600 / duration,
+			Occupancy:  (now.occupiedTime - base.occupiedTime) / duration,
+		}
+		if row.Count > 0 {
+			row.SpotSpeed = (now.speedSum - base.speedSum) / float64(row.Count)
+		}
+
+		c.detectorIntervals = append(c.detectorIntervals, row)
+	}
+}
+
+// DetectorIntervals returns every closed detector interval, oldest first.
+func (c *Collector) DetectorIntervals() []DetectorInterval {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]DetectorInterval(nil), c.detectorIntervals...)
+}
+
+func (c *Collector) ForDetector(detectorID string) []DetectorInterval {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var rows []DetectorInterval
+	for _, row := range c.detectorIntervals {
+		if row.DetectorID == detectorID {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+var detectorCSVHeader = []string{
+	"detector_id", "road_id", "position", "start", "end",
+	"count", "flow_veh_h", "occupancy", "spot_speed",
+}
+
+func WriteDetectorCSV(out io.Writer, rows []DetectorInterval) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(detectorCSVHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.DetectorID,
+			row.RoadID,
+			formatFloat(row.Position),
+			formatFloat(row.Start),
+			formatFloat(row.End),
+			strconv.Itoa(row.Count),
+			formatFloat(row.Flow),
+			formatFloat(row.Occupancy),
+			formatFloat(row.SpotSpeed),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportDetectorCSV writes all closed detector intervals to path, creating
+// its directory.
+func (c *Collector) ExportDetectorCSV(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create detector file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteDetectorCSV(file, c.DetectorIntervals()); err != nil {
+		return fmt.Errorf("failed to write detector data: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/systems"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+func TestDetectorIntervalsCountPassages(t *testing.T) {
+	w := world.New()
+	r1 := road.NewRoad("r1", &road.Node{ID: "n1"}, &road.Node{ID: "n2", X: 400}, 20)
+	d := road.NewDetector("det1", 100)
+	r1.AddDetector(d)
+	w.Roads = append(w.Roads, r1)
+
+	ds := systems.NewDetectorSystem()
+	c := NewCollector(10)
+	tick := func(dt float64) {
+		w.SimTime += dt
+		for _, v := range w.Vehicles {
+			v.Distance += v.Speed * dt
+		}
+		ds.Update(w, dt)
+		c.Update(w, dt)
+	}
+
+	tick(0.5)
+	w.Vehicles = append(w.Vehicles,
+		&vehicle.Vehicle{ID: "v1", Road: r1, Distance: 80, Speed: 10},
+		&vehicle.Vehicle{ID: "v2", Road: r1, Distance: 40, Speed: 20},
+	)
+	for i := 0; i < 19; i++ {
+		tick(0.5)
+	}
+
+	rows := c.ForDetector("det1")
+	if len(rows) != 1 {
+		t.Fatalf("expected one closed interval, got %d", len(rows))
+	}
+	row := rows[0]
+	if row.Count != 2 {
+		t.Errorf("expected 2 passages, got %d", row.Count)
+	}
+	if math.Abs(row.SpotSpeed-15) > 1e-9 {
+		t.Errorf("expected spot speed 15, got %f", row.SpotSpeed)
+	}
+	// v1 is over the loop for two half-second ticks and v2 for one.
+	if math.Abs(row.Occupancy-0.15) > 1e-9 {
+		t.Errorf("expected occupancy 0.15, got %f", row.Occupancy)
+	}
+	if d.Count != 2 || d.Occupied() {
+		t.Errorf("expected idle detector with 2 passages, got count %d occupied %v", d.Count, d.Occupied())
+	}
+
+	for i := 0; i < 20; i++ {
+		tick(0.5)
+	}
+	if rows := c.ForDetector("det1"); len(rows) != 2 || rows[1].Count != 0 {
+		t.Errorf("expected an empty second interval, got %+v", rows)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDetectorCSV(&buf, c.DetectorIntervals()); err != nil {
+		t.Fatalf("csv export failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "detector_id,") {
+		t.Errorf("unexpected csv output:\n%s", buf.String())
+	}
+}
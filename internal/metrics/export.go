@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var csvHeader = []string{
+	"road_id", "start", "end", "entered", "exited",
+	"flow_veh_h", "density_veh_km", "space_mean_speed", "delay_s",
+}
+
+func WriteCSV(out io.Writer, rows []RoadInterval) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.RoadID,
+			formatFloat(row.Start),
+			formatFloat(row.End),
+			strconv.Itoa(row.Entered),
+			strconv.Itoa(row.Exited),
+			formatFloat(row.Flow),
+			formatFloat(row.Density),
+			formatFloat(row.SpaceMeanSpeed),
+			formatFloat(row.Delay),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportCSV writes all closed intervals to path, creating its directory.
+func (c *Collector) ExportCSV(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteCSV(file, c.Intervals()); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return file.Close()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
@@ -0,0 +1,341 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// Control delay thresholds in seconds per vehicle for levels of service A to
+// E, as in the Highway Capacity Manual. Anything above the last is F.
+var (
+	signalizedLOS   = []float64{10, 20, 35, 55, 80}
+	unsignalizedLOS = []float64{10, 15, 25, 35, 50}
+)
+
+// LevelOfService maps an average control delay to an HCM letter grade.
+func LevelOfService(delay float64, signalized bool) string {
+	thresholds := unsignalizedLOS
+	if signalized {
+		thresholds = signalizedLOS
+	}
+	for i, limit := range thresholds {
+		if delay <= limit {
+			return string(rune('A' + i))
+		}
+	}
+	return "F"
+}
+
+// ApproachReport describes one incoming road of an intersection. Vehicles
+// counts those that have crossed the stop line; delays are in seconds per
+// vehicle and queues in vehicles.
+type ApproachReport struct {
+	RoadID       string  `json:"roadId"`
+	Vehicles     int     `json:"vehicles"`
+	AvgDelay     float64 `json:"avgDelay"`
+	MaxQueue     int     `json:"maxQueue"`
+	AvgQueue     float64 `json:"avgQueue"`
+	StoppedShare float64 `json:"stoppedShare"`
+	LOS          string  `json:"los"`
+}
+
+type IntersectionReport struct {
+	NodeID       string           `json:"nodeId"`
+	Signalized   bool             `json:"signalized"`
+	Start        float64          `json:"start"`
+	End          float64          `json:"end"`
+	Vehicles     int              `json:"vehicles"`
+	AvgDelay     float64          `json:"avgDelay"`
+	MaxQueue     int              `json:"maxQueue"`
+	AvgQueue     float64          `json:"avgQueue"`
+	StoppedShare float64          `json:"stoppedShare"`
+	LOS          string           `json:"los"`
+	Approaches   []ApproachReport `json:"approaches"`
+}
+
+type approachAccumulator struct {
+	vehicles  int
+	stopped   int
+	delay     float64
+	queueTime float64
+	maxQueue  int
+}
+
+type approachVehicle struct {
+	roadID  string
+	delay   float64
+	stopped bool
+}
+
+// IntersectionMonitor is a system that measures control delay and queues on
+// the last stretch of every road before the node it leads to. A vehicle's
+// delay is counted from when it enters that stretch until it crosses the
+// stop line.
+type IntersectionMonitor struct {
+	mu sync.RWMutex
+
+	approachDist float64
+	queueSpeed   float64
+
+	started bool
+	start   float64
+	end     float64
+
+	approaches map[string]*approachAccumulator
+	tracked    map[string]*approachVehicle
+}
+
+func NewIntersectionMonitor() *IntersectionMonitor {
+	return &IntersectionMonitor{
+		approachDist: 100.0,
+		queueSpeed:   2.0,
+		approaches:   make(map[string]*approachAccumulator),
+		tracked:      make(map[string]*approachVehicle),
+	}
+}
+
+func (m *IntersectionMonitor) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.started = false
+	m.approaches = make(map[string]*approachAccumulator)
+	m.tracked = make(map[string]*approachVehicle)
+}
+
+func (m *IntersectionMonitor) Update(w *world.World, dt float64) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.started {
+		m.started = true
+		m.start = w.SimTime - dt
+	}
+	m.end = w.SimTime
+
+	queues := make(map[string]int)
+	present := make(map[string]bool, len(w.Vehicles))
+
+	for _, v := range w.Vehicles {
+		present[v.ID] = true
+		tv := m.tracked[v.ID]
+
+		if v.InTransition || v.Road.Length-v.Distance > m.approachDist {
+			if tv != nil {
+				m.finish(v.ID, tv)
+			}
+			continue
+		}
+
+		if tv != nil && tv.roadID != v.Road.ID {
+			m.finish(v.ID, tv)
+			tv = nil
+		}
+		if tv == nil {
+			tv = &approachVehicle{roadID: v.Road.ID}
+			m.tracked[v.ID] = tv
+		}
+
+		if v.Road.MaxSpeed > 0 {
+			if lost := dt - v.Speed*dt/v.Road.MaxSpeed; lost > 0 {
+				tv.delay += lost
+			}
+		}
+		if v.Speed < m.queueSpeed {
+			tv.stopped = true
+			queues[v.Road.ID]++
+		}
+	}
+
+	for id, tv := range m.tracked {
+		if !present[id] {
+			m.finish(id, tv)
+		}
+	}
+
+	for roadID, queue := range queues {
+		acc := m.acc(roadID)
+		acc.queueTime += float64(queue) * dt
+		acc.maxQueue = max(acc.maxQueue, queue)
+	}
+}
+
+func (m *IntersectionMonitor) finish(vehicleID string, tv *approachVehicle) {
+	acc := m.acc(tv.roadID)
+	acc.vehicles++
+	acc.delay += tv.delay
+	if tv.stopped {
+		acc.stopped++
+	}
+	delete(m.tracked, vehicleID)
+}
+
+func (m *IntersectionMonitor) acc(roadID string) *approachAccumulator {
+	acc := m.approaches[roadID]
+	if acc == nil {
+		acc = &approachAccumulator{}
+		m.approaches[roadID] = acc
+	}
+	return acc
+}
+
+// Report summarises the intersection at nodeID over the whole run so far. It
+// returns nil if the node has no intersection.
+func (m *IntersectionMonitor) Report(w *world.World, nodeID string) *IntersectionReport {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	intersection := w.GetIntersection(nodeID)
+	if intersection == nil {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.report(w, intersection)
+}
+
+// Reports summarises every intersection with incoming roads, ordered by node.
+func (m *IntersectionMonitor) Reports(w *world.World) []IntersectionReport {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reports []IntersectionReport
+	for _, intersection := range w.Intersections {
+		if len(intersection.Incoming) > 0 {
+			reports = append(reports, *m.report(w, intersection))
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].NodeID < reports[j].NodeID })
+	return reports
+}
+
+func (m *IntersectionMonitor) report(w *world.World, intersection *road.Intersection) *IntersectionReport {
+	r := &IntersectionReport{
+		NodeID:     intersection.ID,
+		Signalized: isSignalized(w, intersection),
+		Start:      m.start,
+		End:        m.end,
+	}
+	duration := m.end - m.start
+
+	var delay float64
+	var stopped int
+	for _, rd := range intersection.Incoming {
+		acc := m.approaches[rd.ID]
+		if acc == nil {
+			acc = &approachAccumulator{}
+		}
+
+		a := ApproachReport{
+			RoadID:   rd.ID,
+			Vehicles: acc.vehicles,
+			MaxQueue: acc.maxQueue,
+		}
+		if acc.vehicles > 0 {
+			a.AvgDelay = acc.delay / float64(acc.vehicles)
+			a.StoppedShare = float64(acc.stopped) / float64(acc.vehicles)
+		}
+		if duration > 0 {
+			a.AvgQueue = acc.queueTime / duration
+		}
+		a.LOS = LevelOfService(a.AvgDelay, r.Signalized)
+		r.Approaches = append(r.Approaches, a)
+
+		r.Vehicles += acc.vehicles
+		r.MaxQueue = max(r.MaxQueue, acc.maxQueue)
+		r.AvgQueue += a.AvgQueue
+		delay += acc.delay
+		stopped += acc.stopped
+	}
+
+	if r.Vehicles > 0 {
+		r.AvgDelay = delay / float64(r.Vehicles)
+		r.StoppedShare = float64(stopped) / float64(r.Vehicles)
+	}
+	r.LOS = LevelOfService(r.AvgDelay, r.Signalized)
+	return r
+}
+
+func isSignalized(w *world.World, intersection *road.Intersection) bool {
+	for _, light := range w.TrafficLights {
+		if light.Intersection == intersection && light.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+var intersectionCSVHeader = []string{
+	"node_id", "approach", "signalized", "start", "end", "vehicles",
+	"avg_delay_s", "max_queue", "avg_queue", "stopped_share", "los",
+}
+
+// WriteIntersectionCSV writes one row per approach followed by a row for the
+// whole intersection, whose approach column is "all".
+func WriteIntersectionCSV(out io.Writer, reports []IntersectionReport) error {
+	cw := csv.NewWriter(out)
+	if err := cw.Write(intersectionCSVHeader); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		signalized := strconv.FormatBool(r.Signalized)
+		for _, a := range r.Approaches {
+			record := []string{
+				r.NodeID, a.RoadID, signalized, formatFloat(r.Start), formatFloat(r.End),
+				strconv.Itoa(a.Vehicles), formatFloat(a.AvgDelay), strconv.Itoa(a.MaxQueue),
+				formatFloat(a.AvgQueue), formatFloat(a.StoppedShare), a.LOS,
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+
+		record := []string{
+			r.NodeID, "all", signalized, formatFloat(r.Start), formatFloat(r.End),
+			strconv.Itoa(r.Vehicles), formatFloat(r.AvgDelay), strconv.Itoa(r.MaxQueue),
+			formatFloat(r.AvgQueue), formatFloat(r.StoppedShare), r.LOS,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportCSV writes the report of every intersection to path, creating its
+// directory.
+func (m *IntersectionMonitor) ExportCSV(w *world.World, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create intersection report: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteIntersectionCSV(file, m.Reports(w)); err != nil {
+		return fmt.Errorf("failed to write intersection report: %w", err)
+	}
+	return file.Close()
+}
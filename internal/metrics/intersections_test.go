@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+func TestLevelOfService(t *testing.T) {
+	cases := []struct {
+		delay      float64
+		signalized bool
+		want       string
+	}{
+		{5, true, "A"},
+		{10, true, "A"},
+		{30, true, "C"},
+		{60, true, "E"},
+		{81, true, "F"},
+		{12, false, "B"},
+		{30, false, "D"},
+		{51, false, "F"},
+	}
+
+	for _, c := range cases {
+		if got := LevelOfService(c.delay, c.signalized); got != c.want {
+			t.Errorf("LOS(%.0f, signalized=%v): expected %s, got %s", c.delay, c.signalized, c.want, got)
+		}
+	}
+}
+
+func TestIntersectionMonitorMeasuresApproach(t *testing.T) {
+	w := world.New()
+	n1 := &road.Node{ID: "n1"}
+	n2 := &road.Node{ID: "n2", X: 200}
+	n3 := &road.Node{ID: "n3", X: 400}
+	r1 := road.NewRoad("r1", n1, n2, 10)
+	r2 := road.NewRoad("r2", n2, n3, 10)
+	w.Nodes = append(w.Nodes, n1, n2, n3)
+	w.Roads = append(w.Roads, r1, r2)
+	for _, n := range w.Nodes {
+		w.CreateIntersection(n.ID)
+	}
+	w.GetIntersection("n2").AddIncoming(r1)
+	w.GetIntersection("n2").AddOutgoing(r2)
+
+	m := NewIntersectionMonitor()
+	tick := func() {
+		w.SimTime += 1
+		m.Update(w, 1)
+	}
+
+	// v1 waits at the stop line for 10s, v2 queues behind it.
+	v1 := &vehicle.Vehicle{ID: "v1", Road: r1, Distance: 195, Speed: 0}
+	v2 := &vehicle.Vehicle{ID: "v2", Road: r1, Distance: 185, Speed: 0}
+	w.Vehicles = append(w.Vehicles, v1, v2)
+	for i := 0; i < 10; i++ {
+		tick()
+	}
+
+	v1.Road, v1.Distance, v1.Speed = r2, 0, 10
+	v2.Speed = 10
+	tick()
+	v2.Road, v2.Distance = r2, 0
+	for i := 0; i < 9; i++ {
+		tick()
+	}
+
+	report := m.Report(w, "n2")
+	if report == nil || len(report.Approaches) != 1 {
+		t.Fatalf("expected a report with one approach, got %+v", report)
+	}
+	if report.Vehicles != 2 {
+		t.Errorf("expected 2 vehicles through, got %d", report.Vehicles)
+	}
+	if math.Abs(report.AvgDelay-10) > 1e-9 {
+		t.Errorf("expected 10s average delay, got %f", report.AvgDelay)
+	}
+	if report.MaxQueue != 2 {
+		t.Errorf("expected max queue 2, got %d", report.MaxQueue)
+	}
+	// 20 vehicle-seconds of queue over a 20s observation.
+	if math.Abs(report.AvgQueue-1) > 1e-9 {
+		t.Errorf("expected average queue 1, got %f", report.AvgQueue)
+	}
+	if report.StoppedShare != 1 {
+		t.Errorf("expected every vehicle to have stopped, got %f", report.StoppedShare)
+	}
+	if report.Signalized || report.LOS != "A" {
+		t.Errorf("expected unsignalized LOS A, got signalized=%v LOS %s", report.Signalized, report.LOS)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIntersectionCSV(&buf, m.Reports(w)); err != nil {
+		t.Fatalf("csv export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "n2,all,false") {
+		t.Errorf("expected a total row for n2, got:\n%s", buf.String())
+	}
+}
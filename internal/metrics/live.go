@@ -0,0 +1,42 @@
+package metrics
+
+import "traffic-sim/internal/world"
+
+// RoadSnapshot is the instantaneous state of one road. SpeedRatio is the
+// mean speed over the road's MaxSpeed, or 1 when the road is empty.
+type RoadSnapshot struct {
+	Vehicles   int
+	MeanSpeed  float64
+	SpeedRatio float64
+	Density    float64
+}
+
+// LiveRoads measures every road at the current tick. Vehicles in transition
+// between roads are left out. The caller must hold w.Mu.
+func LiveRoads(w *world.World) map[string]RoadSnapshot {
+	speedSum := make(map[string]float64, len(w.Roads))
+	counts := make(map[string]int, len(w.Roads))
+	for _, v := range w.Vehicles {
+		if v.InTransition {
+			continue
+		}
+		speedSum[v.Road.ID] += v.Speed
+		counts[v.Road.ID]++
+	}
+
+	live := make(map[string]RoadSnapshot, len(w.Roads))
+	for _, rd := range w.Roads {
+		s := RoadSnapshot{Vehicles: counts[rd.ID], SpeedRatio: 1}
+		if s.Vehicles > 0 {
+			s.MeanSpeed = speedSum[rd.ID] / float64(s.Vehicles)
+			if rd.MaxSpeed > 0 {
+				s.SpeedRatio = min(s.MeanSpeed/rd.MaxSpeed, 1)
+			}
+		}
+		if rd.Length > 0 {
+			s.Density = float64(s.Vehicles) / (rd.Length / 1000)
+		}
+		live[rd.ID] = s
+	}
+	return live
+}
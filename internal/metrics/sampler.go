@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"sync"
+	"traffic-sim/internal/world"
+)
+
+const (
+	// DefaultSamplePeriod is how often the sampler records a point, in sim
+	// seconds.
+	DefaultSamplePeriod = 1.0
+	// DefaultSampleWindow is how much sim time the sampler keeps.
+	DefaultSampleWindow = 600.0
+
+	// flowWindow is the span road flow is averaged over, so a single
+	// vehicle does not show up as a spike.
+	flowWindow = 60.0
+	waitSpeed  = 2.0
+)
+
+// Sample is the network state at one point in sim time. Rates are in
+// vehicles per minute and speeds in metres per second.
+type Sample struct {
+	Time        float64 `json:"time"`
+	Vehicles    int     `json:"vehicles"`
+	SpawnRate   float64 `json:"spawnRate"`
+	DespawnRate float64 `json:"despawnRate"`
+	MeanSpeed   float64 `json:"meanSpeed"`
+	Waiting     int     `json:"waiting"`
+}
+
+// RoadPoint is one road at one point in sim time. Flow is in vehicles per
+// hour over the preceding minute; Speed is zero when the road was empty.
+type RoadPoint struct {
+	Time     float64 `json:"time"`
+	Vehicles int     `json:"vehicles"`
+	Flow     float64 `json:"flow"`
+	Speed    float64 `json:"speed"`
+}
+
+type roadSample struct {
+	exits    int
+	vehicles int
+	speed    float64
+}
+
+// Sampler is a system that keeps a rolling window of network-wide and
+// per-road time series for live charts.
+type Sampler struct {
+	mu sync.RWMutex
+
+	period float64
+	window float64
+
+	started    bool
+	lastSample float64
+
+	lastRoad  map[string]string
+	spawned   int
+	despawned int
+	exits     map[string]int
+
+	times   []float64
+	samples []Sample
+	roads   map[string][]roadSample
+}
+
+func NewSampler(period, window float64) *Sampler {
+	if period <= 0 {
+		period = DefaultSamplePeriod
+	}
+	if window <= 0 {
+		window = DefaultSampleWindow
+	}
+	s := &Sampler{period: period, window: window}
+	s.Reset()
+	return s
+}
+
+func (s *Sampler) Window() float64 {
+	return s.window
+}
+
+func (s *Sampler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.started = false
+	s.lastRoad = make(map[string]string)
+	s.spawned = 0
+	s.despawned = 0
+	s.exits = make(map[string]int)
+	s.times = nil
+	s.samples = nil
+	s.roads = make(map[string][]roadSample)
+}
+
+func (s *Sampler) Update(w *world.World, dt float64) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		s.started = true
+		s.lastSample = w.SimTime
+		for _, v := range w.Vehicles {
+			s.lastRoad[v.ID] = v.Road.ID
+		}
+		return
+	}
+
+	present := make(map[string]bool, len(w.Vehicles))
+	for _, v := range w.Vehicles {
+		present[v.ID] = true
+		prev, seen := s.lastRoad[v.ID]
+		if !seen {
+			s.spawned++
+		} else if prev != v.Road.ID {
+			s.exits[prev]++
+		}
+		s.lastRoad[v.ID] = v.Road.ID
+	}
+	for id, roadID := range s.lastRoad {
+		if !present[id] {
+			s.despawned++
+			s.exits[roadID]++
+			delete(s.lastRoad, id)
+		}
+	}
+
+	if w.SimTime-s.lastSample >= s.period {
+		s.record(w)
+	}
+}
+
+func (s *Sampler) record(w *world.World) {
+	elapsed := w.SimTime - s.lastSample
+	sample := Sample{
+		Time:        w.SimTime,
+		Vehicles:    len(w.Vehicles),
+		SpawnRate:   float64(s.spawned) * 60 / elapsed,
+		DespawnRate: float64(s.despawned) * 60 / elapsed,
+	}
+
+	speedSum := make(map[string]float64)
+	counts := make(map[string]int)
+	moving := 0
+	for _, v := range w.Vehicles {
+		if v.Speed < waitSpeed {
+			sample.Waiting++
+		}
+		if v.InTransition {
+			continue
+		}
+		sample.MeanSpeed += v.Speed
+		moving++
+		speedSum[v.Road.ID] += v.Speed
+		counts[v.Road.ID]++
+	}
+	if moving > 0 {
+		sample.MeanSpeed /= float64(moving)
+	}
+
+	s.times = append(s.times, w.SimTime)
+	s.samples = append(s.samples, sample)
+	for _, rd := range w.Roads {
+		point := roadSample{exits: s.exits[rd.ID], vehicles: counts[rd.ID]}
+		if point.vehicles > 0 {
+			point.speed = speedSum[rd.ID] / float64(point.vehicles)
+		}
+		// Roads created mid-window are padded so every series lines up
+		// with s.times.
+		series := s.roads[rd.ID]
+		for len(series) < len(s.times)-1 {
+			series = append(series, roadSample{})
+		}
+		s.roads[rd.ID] = append(series, point)
+	}
+
+	s.lastSample = w.SimTime
+	s.spawned = 0
+	s.despawned = 0
+	s.exits = make(map[string]int)
+	s.trim(w.SimTime - s.window)
+}
+
+func (s *Sampler) trim(cutoff float64) {
+	drop := 0
+	for drop < len(s.times) && s.times[drop] < cutoff {
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+
+	s.times = s.times[drop:]
+	s.samples = s.samples[drop:]
+	for id, series := range s.roads {
+		if len(series) <= drop {
+			delete(s.roads, id)
+			continue
+		}
+		s.roads[id] = series[drop:]
+	}
+}
+
+// Samples returns the network series, oldest first.
+func (s *Sampler) Samples() []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Sample(nil), s.samples...)
+}
+
+// Road returns the series of one road, oldest first, or nil if the road has
+// not been sampled.
+func (s *Sampler) Road(roadID string) []RoadPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series := s.roads[roadID]
+	if len(series) == 0 {
+		return nil
+	}
+
+	offset := len(s.times) - len(series)
+	points := make([]RoadPoint, len(series))
+	exits := 0
+	first := 0
+	for i, rs := range series {
+		t := s.times[offset+i]
+		exits += rs.exits
+		for first < i && s.times[offset+first] <= t-flowWindow {
+			exits -= series[first].exits
+			first++
+		}
+
+		span := flowWindow
+		if i == first {
+			span = s.period
+		} else if covered := t - s.times[offset+first] + s.period; covered < span {
+			span = covered
+		}
+
+		points[i] = RoadPoint{
+			Time:     t,
+			Vehicles: rs.vehicles,
+			Flow:     float64(exits) * 3600 / span,
+			Speed:    rs.speed,
+		}
+	}
+	return points
+}
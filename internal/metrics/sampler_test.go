@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+func TestSamplerSeries(t *testing.T) {
+	w := world.New()
+	r1 := road.NewRoad("r1", &road.Node{ID: "n1"}, &road.Node{ID: "n2", X: 100}, 20)
+	r2 := road.NewRoad("r2", r1.To, &road.Node{ID: "n3", X: 200}, 20)
+	w.Roads = append(w.Roads, r1, r2)
+
+	s := NewSampler(1, 5)
+	tick := func() {
+		w.SimTime += 0.5
+		s.Update(w, 0.5)
+	}
+	tick()
+
+	v1 := &vehicle.Vehicle{ID: "v1", Road: r1, Speed: 10}
+	v2 := &vehicle.Vehicle{ID: "v2", Road: r1, Speed: 1}
+	w.Vehicles = append(w.Vehicles, v1, v2)
+	tick()
+	tick()
+
+	samples := s.Samples()
+	if len(samples) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(samples))
+	}
+	first := samples[0]
+	if first.Vehicles != 2 || first.Waiting != 1 {
+		t.Errorf("expected 2 vehicles and 1 waiting, got %d/%d", first.Vehicles, first.Waiting)
+	}
+	if math.Abs(first.SpawnRate-120) > 1e-9 {
+		t.Errorf("expected spawn rate 120 veh/min, got %f", first.SpawnRate)
+	}
+	if math.Abs(first.MeanSpeed-5.5) > 1e-9 {
+		t.Errorf("expected mean speed 5.5, got %f", first.MeanSpeed)
+	}
+
+	v1.Road = r2
+	w.Vehicles = w.Vehicles[:1]
+	tick()
+	tick()
+
+	second := s.Samples()[1]
+	if math.Abs(second.DespawnRate-60) > 1e-9 {
+		t.Errorf("expected despawn rate 60 veh/min, got %f", second.DespawnRate)
+	}
+
+	points := s.Road("r1")
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points for r1, got %d", len(points))
+	}
+	// Two exits over the two seconds sampled so far.
+	if math.Abs(points[1].Flow-3600) > 1e-9 {
+		t.Errorf("expected r1 flow 3600 veh/h, got %f", points[1].Flow)
+	}
+	if points[1].Vehicles != 0 {
+		t.Errorf("expected r1 to be empty, got %d vehicles", points[1].Vehicles)
+	}
+	if r2Points := s.Road("r2"); r2Points[1].Speed != 10 {
+		t.Errorf("expected r2 speed 10, got %f", r2Points[1].Speed)
+	}
+
+	for w.SimTime < 20 {
+		tick()
+	}
+	samples = s.Samples()
+	if len(samples) > 6 || samples[0].Time < samples[len(samples)-1].Time-5 {
+		t.Errorf("expected samples trimmed to a 5s window, got %d starting at %f", len(samples), samples[0].Time)
+	}
+	if len(s.Road("r1")) != len(samples) {
+		t.Errorf("expected road series to line up with network series")
+	}
+
+	s.Reset()
+	if len(s.Samples()) != 0 || s.Road("r1") != nil {
+		t.Errorf("expected reset to clear all series")
+	}
+}
@@ -7,9 +7,14 @@ import (
 )
 
 func DeserializeWorld(saveData *SaveFormat) (*world.World, error) {
+	// Files are migrated as they are read; anything else must already be
+	// current.
 	if saveData.Version != CurrentVersion {
 		return nil, fmt.Errorf("incompatible save version: %s (expected %s)", saveData.Version, CurrentVersion)
 	}
+	if report := Validate(saveData); report.HasErrors() {
+		return nil, &ValidationError{Report: report}
+	}
 
 	w := world.New()
 
@@ -40,6 +45,29 @@ func DeserializeWorld(saveData *SaveFormat) (*world.World, error) {
 		rd.EndOffset = road.Point{X: roadData.EndOffsetX, Y: roadData.EndOffsetY}
 		rd.UpdateLength()
 
+		if roadData.Curve != nil {
+			rd.Curve = &road.RoadCurve{
+				ControlP1: road.Point{X: roadData.Curve.Control1X, Y: roadData.Curve.Control1Y},
+				ControlP2: road.Point{X: roadData.Curve.Control2X, Y: roadData.Curve.Control2Y},
+			}
+		}
+
+		for _, zoneData := range roadData.SpeedZones {
+			zone := road.NewSpeedZone(zoneData.ID, zoneData.Start, zoneData.End, zoneData.Limit)
+			zone.ActiveFrom = zoneData.ActiveFrom
+			zone.ActiveTo = zoneData.ActiveTo
+			zone.Period = zoneData.Period
+			rd.AddSpeedZone(zone)
+		}
+
+		for _, detectorData := range roadData.Detectors {
+			detector := road.NewDetector(detectorData.ID, detectorData.Position)
+			if detectorData.Length > 0 {
+				detector.Length = detectorData.Length
+			}
+			rd.AddDetector(detector)
+		}
+
 		w.Roads = append(w.Roads, rd)
 		roadMap[rd.ID] = rd
 
@@ -128,5 +156,34 @@ func DeserializeWorld(saveData *SaveFormat) (*world.World, error) {
 		w.TrafficLights = append(w.TrafficLights, light)
 	}
 
+	for _, incData := range saveData.Incidents {
+		rd, exists := roadMap[incData.RoadID]
+		if !exists {
+			return nil, fmt.Errorf("incident %s references non-existent road %s", incData.ID, incData.RoadID)
+		}
+
+		kind, err := road.ParseIncidentKind(incData.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("incident %s: %w", incData.ID, err)
+		}
+
+		inc := road.NewIncident(incData.ID, kind, rd)
+		inc.Start = incData.Start
+		inc.End = incData.End
+		inc.From = incData.From
+		inc.Until = incData.Until
+		if incData.SpeedFactor > 0 {
+			inc.SpeedFactor = incData.SpeedFactor
+		}
+
+		w.Incidents = append(w.Incidents, inc)
+	}
+
+	if saveData.State != nil {
+		if err := restoreState(w, saveData.State); err != nil {
+			return nil, err
+		}
+	}
+
 	return w, nil
 }
\ No newline at end of file
@@ -0,0 +1,152 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"traffic-sim/internal/world"
+)
+
+// SaveWorldTo writes the network of w to path, creating its directory. The
+// caller must hold w.Mu; it is not taken again, as a second read lock would
+// deadlock behind a waiting writer.
+func SaveWorldTo(path string, w *world.World) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create save directory: %w", err)
+		}
+	}
+	return WriteSaveFile(path, serializeWorld(w))
+}
+
+// LoadWorldFrom reads a save file and builds its world. The save data is
+// returned as well for the snapshot state it may carry. Warnings about the
+// save are logged; a save with errors is not loaded.
+func LoadWorldFrom(path string) (*world.World, *SaveFormat, error) {
+	return loadWorldFrom(path, false)
+}
+
+// LoadRepairedWorldFrom is LoadWorldFrom for saves that may be damaged: it
+// first repairs what Repair can, logging each fix. The file is left as it
+// is.
+func LoadRepairedWorldFrom(path string) (*world.World, *SaveFormat, error) {
+	return loadWorldFrom(path, true)
+}
+
+func loadWorldFrom(path string, repair bool) (*world.World, *SaveFormat, error) {
+	saveData, err := ReadSaveFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	check := Validate
+	if repair {
+		check = Repair
+	}
+	for _, p := range check(saveData).Problems {
+		switch {
+		case repair && p.Repairable:
+			log.Printf("Repaired %s: %s: %s", path, p.Object, p.Message)
+		case p.Severity == SeverityWarning:
+			log.Printf("Warning: %s: %s: %s", path, p.Object, p.Message)
+		}
+	}
+
+	w, err := DeserializeWorld(saveData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build world: %w", err)
+	}
+	return w, saveData, nil
+}
+
+type SaveFileInfo struct {
+	Path    string
+	Name    string
+	ModTime time.Time
+	Size    int64
+}
+
+// ListSaveFiles lists the .json files in dir, newest first. A missing
+// directory has no saves.
+func ListSaveFiles(dir string) ([]SaveFileInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saves: %w", err)
+	}
+
+	var files []SaveFileInfo
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, SaveFileInfo{
+			Path:    filepath.Join(dir, e.Name()),
+			Name:    e.Name(),
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		})
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].ModTime.After(files[j].ModTime)
+	})
+	return files, nil
+}
+
+func ReadSaveFile(filename string) (*SaveFormat, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	saveData, from, err := ParseSaveData(data)
+	if err != nil {
+		return nil, err
+	}
+	if from != CurrentVersion {
+		log.Printf("Upgraded %s from save version %s to %s", filename, from, CurrentVersion)
+	}
+
+	return saveData, nil
+}
+
+// ParseSaveData decodes a save, migrating it to CurrentVersion first. It
+// also returns the version the data was written with.
+func ParseSaveData(data []byte) (*SaveFormat, string, error) {
+	upgraded, from, err := Migrate(data)
+	if err != nil {
+		return nil, from, err
+	}
+
+	var saveData SaveFormat
+	if err := json.Unmarshal(upgraded, &saveData); err != nil {
+		return nil, from, fmt.Errorf("failed to parse save file: %w", err)
+	}
+
+	return &saveData, from, nil
+}
+
+func WriteSaveFile(filename string, saveData *SaveFormat) error {
+	data, err := json.MarshalIndent(saveData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal save data: %w", err)
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
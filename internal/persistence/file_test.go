@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveWorldToAndLoadWorldFrom(t *testing.T) {
+	w, err := DeserializeWorld(newTestSave())
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "net.json")
+	if err := SaveWorldTo(path, w); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded, saveData, err := LoadWorldFrom(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(loaded.Nodes) != len(w.Nodes) || len(loaded.Roads) != len(w.Roads) {
+		t.Errorf("loaded %d nodes and %d roads, want %d and %d", len(loaded.Nodes), len(loaded.Roads), len(w.Nodes), len(w.Roads))
+	}
+	if saveData.Version != CurrentVersion {
+		t.Errorf("version = %s, want %s", saveData.Version, CurrentVersion)
+	}
+
+	if _, _, err := LoadWorldFrom(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error loading a missing file")
+	}
+}
+
+func TestListSaveFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"old.json", "new.json", "notes.txt"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ListSaveFiles(dir)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != "new.json" || files[1].Name != "old.json" {
+		t.Errorf("got %+v, want new.json then old.json", files)
+	}
+
+	files, err = ListSaveFiles(filepath.Join(dir, "missing"))
+	if err != nil || files != nil {
+		t.Errorf("missing directory gave %v, %v; want nil, nil", files, err)
+	}
+}
+
+func TestSaveWorldToUnderReadLockWithWaitingWriter(t *testing.T) {
+	w, err := DeserializeWorld(newTestSave())
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+
+	w.Mu.RLock()
+	writerDone := make(chan struct{})
+	go func() {
+		w.Mu.Lock()
+		w.Mu.Unlock()
+		close(writerDone)
+	}()
+	// A new reader is refused once the writer is queued.
+	for w.Mu.TryRLock() {
+		w.Mu.RUnlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	saved := make(chan error, 1)
+	go func() {
+		saved <- SaveWorldTo(filepath.Join(t.TempDir(), "net.json"), w)
+	}()
+	select {
+	case err := <-saved:
+		if err != nil {
+			t.Errorf("save failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("save deadlocked behind the waiting writer")
+	}
+
+	w.Mu.RUnlock()
+	<-writerDone
+}
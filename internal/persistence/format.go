@@ -1,6 +1,6 @@
 package persistence
 
-const CurrentVersion = "1.0.0"
+const CurrentVersion = "1.1.0"
 
 type SaveFormat struct {
 	Version       string                 `json:"version"`
@@ -10,6 +10,8 @@ type SaveFormat struct {
 	SpawnPoints   []SpawnPointData       `json:"spawnPoints"`
 	DespawnPoints []DespawnPointData     `json:"despawnPoints"`
 	TrafficLights []TrafficLightData     `json:"trafficLights"`
+	Incidents     []IncidentData         `json:"incidents,omitempty"`
+	State         *SnapshotState         `json:"state,omitempty"`
 }
 
 type NodeData struct {
@@ -29,6 +31,35 @@ type RoadData struct {
 	StartOffsetY    float64 `json:"startOffsetY,omitempty"`
 	EndOffsetX      float64 `json:"endOffsetX,omitempty"`
 	EndOffsetY      float64 `json:"endOffsetY,omitempty"`
+	Curve           *RoadCurveData  `json:"curve,omitempty"`
+	SpeedZones      []SpeedZoneData `json:"speedZones,omitempty"`
+	Detectors       []DetectorData  `json:"detectors,omitempty"`
+}
+
+// RoadCurveData holds the inner control points of a road's cubic Bezier
+// curve in world coordinates; the outer ones are the road's offset end
+// points. Roads saved without it are straight.
+type RoadCurveData struct {
+	Control1X float64 `json:"control1X"`
+	Control1Y float64 `json:"control1Y"`
+	Control2X float64 `json:"control2X"`
+	Control2Y float64 `json:"control2Y"`
+}
+
+type SpeedZoneData struct {
+	ID         string  `json:"id"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Limit      float64 `json:"limit"`
+	ActiveFrom float64 `json:"activeFrom,omitempty"`
+	ActiveTo   float64 `json:"activeTo,omitempty"`
+	Period     float64 `json:"period,omitempty"`
+}
+
+type DetectorData struct {
+	ID       string  `json:"id"`
+	Position float64 `json:"position"`
+	Length   float64 `json:"length"`
 }
 
 type SpawnPointData struct {
@@ -59,4 +90,15 @@ type TrafficLightData struct {
 	YellowTime        float64  `json:"yellowTime"`
 	RedTime           float64  `json:"redTime"`
 	Enabled           bool     `json:"enabled"`
-}
\ No newline at end of file
+}
+
+type IncidentData struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	RoadID      string  `json:"roadId"`
+	Start       float64 `json:"start,omitempty"`
+	End         float64 `json:"end,omitempty"`
+	From        float64 `json:"from,omitempty"`
+	Until       float64 `json:"until,omitempty"`
+	SpeedFactor float64 `json:"speedFactor,omitempty"`
+}
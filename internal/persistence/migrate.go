@@ -0,0 +1,186 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNewerVersion is returned for saves written by a newer build than this
+// one.
+var ErrNewerVersion = errors.New("save file is newer than this version of the simulator")
+
+// Migration upgrades a save document from one format version to the next.
+// It works on the decoded JSON rather than SaveFormat, so it can reshape
+// fields the current structs no longer have. Numbers in the document are
+// json.Number, so 64-bit integers such as the snapshot seed keep every
+// digit.
+type Migration struct {
+	From  string
+	To    string
+	Apply func(doc map[string]any) error
+}
+
+// migrations is the chain of upgrade steps, oldest first. A format change
+// bumps CurrentVersion and appends a step from the previous version.
+var migrations = []Migration{
+	// 1.1.0 added optional road curves; older roads simply stay straight.
+	{From: "1.0.0", To: "1.1.0", Apply: func(doc map[string]any) error { return nil }},
+}
+
+func migrationFrom(version string) (Migration, bool) {
+	for _, m := range migrations {
+		if m.From == version {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Migrate upgrades an encoded save to CurrentVersion, one step at a time. It
+// returns the upgraded JSON and the version the file was written with; data
+// already at CurrentVersion is returned unchanged.
+func Migrate(data []byte) ([]byte, string, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse save file: %w", err)
+	}
+
+	version, _ := doc["version"].(string)
+	if version == "" {
+		return nil, "", fmt.Errorf("save file has no version")
+	}
+	if version == CurrentVersion {
+		return data, version, nil
+	}
+
+	cmp, err := compareVersions(version, CurrentVersion)
+	if err != nil {
+		return nil, version, err
+	}
+	if cmp > 0 {
+		return nil, version, fmt.Errorf("%w: version %s, supported up to %s", ErrNewerVersion, version, CurrentVersion)
+	}
+
+	current := version
+	for steps := 0; current != CurrentVersion; steps++ {
+		m, ok := migrationFrom(current)
+		if !ok || steps >= len(migrations) {
+			return nil, version, fmt.Errorf("no migration from save version %s to %s", current, CurrentVersion)
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate save from %s to %s: %w", m.From, m.To, err)
+		}
+		doc["version"] = m.To
+		current = m.To
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+	return upgraded, version, nil
+}
+
+// compareVersions orders two "major.minor.patch" versions.
+func compareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, fmt.Errorf("invalid save version %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid save version %q", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// UpgradeFile migrates a save file in place and returns the version it had.
+// Files already at CurrentVersion are left untouched. With backup set, the
+// original is kept next to it as <path>.bak.
+func UpgradeFile(path string, backup bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	saveData, from, err := ParseSaveData(data)
+	if err != nil || from == CurrentVersion {
+		return from, err
+	}
+
+	return from, replaceSaveFile(path, data, saveData, backup)
+}
+
+// RepairFile repairs a save file in place, upgrading it to the current
+// format as well. It reports every problem found; the file is only
+// rewritten if something was repaired.
+func RepairFile(path string, backup bool) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	saveData, _, err := ParseSaveData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report := Repair(saveData)
+	for _, p := range report.Problems {
+		if p.Repairable {
+			return report, replaceSaveFile(path, data, saveData, backup)
+		}
+	}
+	return report, nil
+}
+
+// replaceSaveFile writes saveData over the file at path, which held data,
+// keeping data as <path>.bak if backup is set.
+func replaceSaveFile(path string, data []byte, saveData *SaveFormat, backup bool) error {
+	if backup {
+		if err := os.WriteFile(path+".bak", data, 0644); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+
+	// Write next to the original and rename, so an interrupted rewrite never
+	// leaves a half-written save behind.
+	tmp := path + ".tmp"
+	if err := WriteSaveFile(tmp, saveData); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateRunsStepsInOrder(t *testing.T) {
+	saved := migrations
+	defer func() { migrations = saved }()
+	migrations = append([]Migration{
+		{From: "0.9.0", To: "1.0.0", Apply: func(doc map[string]any) error {
+			doc["roads"] = doc["edges"]
+			delete(doc, "edges")
+			return nil
+		}},
+	}, saved...)
+
+	old := `{"version":"0.9.0","nodes":[{"id":"n1","x":0,"y":0},{"id":"n2","x":50,"y":0}],
+		"edges":[{"id":"r1","fromNodeId":"n1","toNodeId":"n2","maxSpeed":40,"width":10}]}`
+	saveData, from, err := ParseSaveData([]byte(old))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if from != "0.9.0" || saveData.Version != CurrentVersion {
+		t.Errorf("expected 0.9.0 upgraded to %s, got %s from %s", CurrentVersion, saveData.Version, from)
+	}
+	if len(saveData.Roads) != 1 || saveData.Roads[0].ID != "r1" {
+		t.Errorf("expected the renamed roads to survive, got %+v", saveData.Roads)
+	}
+}
+
+func TestMigrateRejectsUnknownVersions(t *testing.T) {
+	_, _, err := ParseSaveData([]byte(`{"version":"9.0.0","nodes":[]}`))
+	if !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("expected a newer save to be rejected, got %v", err)
+	}
+	if _, _, err := ParseSaveData([]byte(`{"version":"0.1.0","nodes":[]}`)); err == nil {
+		t.Errorf("expected a version without a migration path to be rejected")
+	}
+	if _, _, err := ParseSaveData([]byte(`{"nodes":[]}`)); err == nil {
+		t.Errorf("expected a save without a version to be rejected")
+	}
+}
+
+func TestUpgradeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	old := `{"version":"1.0.0","nodes":[{"id":"n1","x":0,"y":0}],"roads":[]}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := UpgradeFile(path, true)
+	if err != nil || from != "1.0.0" {
+		t.Fatalf("expected an upgrade from 1.0.0, got %q, %v", from, err)
+	}
+	saveData, err := ReadSaveFile(path)
+	if err != nil || saveData.Version != CurrentVersion || len(saveData.Nodes) != 1 {
+		t.Errorf("expected the file rewritten at %s, got %+v, %v", CurrentVersion, saveData, err)
+	}
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != old {
+		t.Errorf("expected the original kept as a backup, got %q, %v", backup, err)
+	}
+
+	if from, err := UpgradeFile(path, false); err != nil || from != CurrentVersion {
+		t.Errorf("expected a current file to be left alone, got %q, %v", from, err)
+	}
+}
+
+func TestMigrateKeepsLargeSeeds(t *testing.T) {
+	for _, seed := range []uint64{1760890000123456789, 1<<64 - 1} {
+		old := fmt.Sprintf(`{"version":"1.0.0","nodes":[],"roads":[],"state":{"simTime":1.5,"seed":%d,"vehicles":[]}}`, seed)
+		saveData, from, err := ParseSaveData([]byte(old))
+		if err != nil {
+			t.Fatalf("parse failed for seed %d: %v", seed, err)
+		}
+		if from != "1.0.0" || saveData.State == nil {
+			t.Fatalf("expected a migrated snapshot, got version %s and state %v", from, saveData.State)
+		}
+		if saveData.State.Seed != seed {
+			t.Errorf("seed %d became %d", seed, saveData.State.Seed)
+		}
+	}
+}
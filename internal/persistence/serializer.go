@@ -8,7 +8,12 @@ import (
 func SerializeWorld(w *world.World) *SaveFormat {
 	w.Mu.RLock()
 	defer w.Mu.RUnlock()
+	return serializeWorld(w)
+}
 
+// serializeWorld is SerializeWorld for callers already holding the world
+// lock.
+func serializeWorld(w *world.World) *SaveFormat {
 	saveData := &SaveFormat{
 		Version:       CurrentVersion,
 		Timestamp:     time.Now().Format(time.RFC3339),
@@ -43,6 +48,35 @@ func SerializeWorld(w *world.World) *SaveFormat {
 		if rd.ReverseRoad != nil {
 			roadData.ReverseRoadID = rd.ReverseRoad.ID
 		}
+
+		if rd.Curve != nil {
+			roadData.Curve = &RoadCurveData{
+				Control1X: rd.Curve.ControlP1.X,
+				Control1Y: rd.Curve.ControlP1.Y,
+				Control2X: rd.Curve.ControlP2.X,
+				Control2Y: rd.Curve.ControlP2.Y,
+			}
+		}
+
+		for _, z := range rd.SpeedZones {
+			roadData.SpeedZones = append(roadData.SpeedZones, SpeedZoneData{
+				ID:         z.ID,
+				Start:      z.Start,
+				End:        z.End,
+				Limit:      z.Limit,
+				ActiveFrom: z.ActiveFrom,
+				ActiveTo:   z.ActiveTo,
+				Period:     z.Period,
+			})
+		}
+
+		for _, d := range rd.Detectors {
+			roadData.Detectors = append(roadData.Detectors, DetectorData{
+				ID:       d.ID,
+				Position: d.Position,
+				Length:   d.Length,
+			})
+		}
 		
 		saveData.Roads = append(saveData.Roads, roadData)
 	}
@@ -88,5 +122,18 @@ func SerializeWorld(w *world.World) *SaveFormat {
 		})
 	}
 
+	for _, inc := range w.Incidents {
+		saveData.Incidents = append(saveData.Incidents, IncidentData{
+			ID:          inc.ID,
+			Kind:        inc.Kind.String(),
+			RoadID:      inc.Road.ID,
+			Start:       inc.Start,
+			End:         inc.End,
+			From:        inc.From,
+			Until:       inc.Until,
+			SpeedFactor: inc.SpeedFactor,
+		})
+	}
+
 	return saveData
 }
\ No newline at end of file
@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"encoding/json"
+	"testing"
+	"traffic-sim/internal/road"
+)
+
+func TestRoadCurveRoundTrip(t *testing.T) {
+	w, err := DeserializeWorld(newTestSave())
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+	curve := &road.RoadCurve{ControlP1: road.Point{X: 60, Y: -40}, ControlP2: road.Point{X: 140, Y: -40}}
+	w.FindRoad("r1").Curve = curve
+
+	data, err := json.Marshal(SerializeWorld(w))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var saveData SaveFormat
+	if err := json.Unmarshal(data, &saveData); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	loaded, err := DeserializeWorld(&saveData)
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+
+	if got := loaded.FindRoad("r1").Curve; got == nil || *got != *curve {
+		t.Errorf("expected curve %+v after reload, got %+v", curve, got)
+	}
+	if got := loaded.FindRoad("r2").Curve; got != nil {
+		t.Errorf("expected a straight road to stay straight, got %+v", got)
+	}
+}
+
+func TestLoadsRoadsSavedWithoutCurves(t *testing.T) {
+	old := `{"version":"1.0.0","nodes":[{"id":"n1","x":0,"y":0},{"id":"n2","x":100,"y":0}],
+		"roads":[{"id":"r1","fromNodeId":"n1","toNodeId":"n2","maxSpeed":40,"width":10}]}`
+
+	saveData, _, err := ParseSaveData([]byte(old))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	w, err := DeserializeWorld(saveData)
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+	if rd := w.FindRoad("r1"); rd == nil || rd.Curve != nil || rd.Length != 100 {
+		t.Errorf("expected a straight 100 long road, got %+v", rd)
+	}
+}
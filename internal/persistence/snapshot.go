@@ -0,0 +1,308 @@
+package persistence
+
+import (
+	"encoding/json"
+	"fmt"
+	"traffic-sim/internal/geom"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+// SnapshotState is the dynamic part of a save. A save with State set resumes
+// a run exactly where it was taken instead of starting from an empty network.
+type SnapshotState struct {
+	SimTime     float64                    `json:"simTime"`
+	Paused      bool                       `json:"paused,omitempty"`
+	Seed        uint64                     `json:"seed"`
+	RNG         []byte                     `json:"rng"`
+	Vehicles    []VehicleData              `json:"vehicles"`
+	SpawnTimers map[string]float64         `json:"spawnTimers,omitempty"`
+	Lights      []TrafficLightStateData    `json:"lights,omitempty"`
+	Incidents   []string                   `json:"activeIncidents,omitempty"`
+	Detectors   []DetectorStateData        `json:"detectors,omitempty"`
+	Systems     map[string]json.RawMessage `json:"systems,omitempty"`
+}
+
+type VehicleData struct {
+	ID              string     `json:"id"`
+	Kind            int        `json:"kind,omitempty"`
+	RoadID          string     `json:"roadId"`
+	NextRoadID      string     `json:"nextRoadId,omitempty"`
+	Distance        float64    `json:"distance"`
+	Speed           float64    `json:"speed"`
+	X               float64    `json:"x"`
+	Y               float64    `json:"y"`
+	InTransition    bool       `json:"inTransition,omitempty"`
+	TransitionCurve *CurveData `json:"transitionCurve,omitempty"`
+	TransitionT     float64    `json:"transitionT,omitempty"`
+	TransitionSpeed float64    `json:"transitionSpeed,omitempty"`
+	TargetDespawnID string     `json:"targetDespawnId,omitempty"`
+	OriginID        string     `json:"originId,omitempty"`
+	SpawnTime       float64    `json:"spawnTime"`
+	PullingOver     bool       `json:"pullingOver,omitempty"`
+	PullOver        float64    `json:"pullOver,omitempty"`
+	Trip            *TripData  `json:"trip,omitempty"`
+}
+
+type TripData struct {
+	Route       []string `json:"route,omitempty"`
+	Distance    float64  `json:"distance"`
+	Stops       int      `json:"stops,omitempty"`
+	StoppedTime float64  `json:"stoppedTime,omitempty"`
+	MaxSpeed    float64  `json:"maxSpeed"`
+	Stopped     bool     `json:"stopped,omitempty"`
+}
+
+type CurveData struct {
+	Points [4][2]float64 `json:"points"`
+	Length float64       `json:"length"`
+}
+
+type DetectorStateData struct {
+	ID           string   `json:"id"`
+	Count        int      `json:"count"`
+	OccupiedTime float64  `json:"occupiedTime"`
+	SpeedSum     float64  `json:"speedSum"`
+	LastPassage  float64  `json:"lastPassage"`
+	Occupants    []string `json:"occupants,omitempty"`
+}
+
+type TrafficLightStateData struct {
+	ID           string  `json:"id"`
+	State        int     `json:"state"`
+	PrevState    int     `json:"prevState"`
+	Timer        float64 `json:"timer"`
+	Preempted    bool    `json:"preempted,omitempty"`
+	PreemptState int     `json:"preemptState,omitempty"`
+}
+
+// SerializeSnapshot saves the network together with vehicles, timers, the
+// random generator and the given system state. Both are read under one
+// lock, so they describe the same tick; the caller must keep the simulation
+// from stepping between exporting systemState and this call.
+func SerializeSnapshot(w *world.World, systemState map[string]json.RawMessage, paused bool) (*SaveFormat, error) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	saveData := serializeWorld(w)
+
+	rngState, err := w.RNGState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save random state: %w", err)
+	}
+
+	state := &SnapshotState{
+		SimTime:     w.SimTime,
+		Paused:      paused,
+		Seed:        w.Seed,
+		RNG:         rngState,
+		Vehicles:    make([]VehicleData, 0, len(w.Vehicles)),
+		SpawnTimers: make(map[string]float64, len(w.SpawnPoints)),
+		Systems:     systemState,
+	}
+
+	for _, v := range w.Vehicles {
+		state.Vehicles = append(state.Vehicles, serializeVehicle(v))
+	}
+
+	for _, sp := range w.SpawnPoints {
+		state.SpawnTimers[sp.ID] = sp.Timer
+	}
+
+	for _, light := range w.TrafficLights {
+		state.Lights = append(state.Lights, TrafficLightStateData{
+			ID:           light.ID,
+			State:        int(light.State),
+			PrevState:    int(light.PrevState),
+			Timer:        light.Timer,
+			Preempted:    light.Preempted,
+			PreemptState: int(light.PreemptState),
+		})
+	}
+
+	for _, inc := range w.Incidents {
+		if inc.Active {
+			state.Incidents = append(state.Incidents, inc.ID)
+		}
+	}
+
+	for _, rd := range w.Roads {
+		for _, d := range rd.Detectors {
+			state.Detectors = append(state.Detectors, DetectorStateData{
+				ID:           d.ID,
+				Count:        d.Count,
+				OccupiedTime: d.OccupiedTime,
+				SpeedSum:     d.SpeedSum,
+				LastPassage:  d.LastPassage,
+				Occupants:    d.Occupants,
+			})
+		}
+	}
+
+	saveData.State = state
+	return saveData, nil
+}
+
+func serializeVehicle(v *vehicle.Vehicle) VehicleData {
+	data := VehicleData{
+		ID:              v.ID,
+		Kind:            int(v.Kind),
+		RoadID:          v.Road.ID,
+		Distance:        v.Distance,
+		Speed:           v.Speed,
+		X:               v.Pos.X,
+		Y:               v.Pos.Y,
+		InTransition:    v.InTransition,
+		TransitionT:     v.TransitionT,
+		TransitionSpeed: v.TransitionSpeed,
+		SpawnTime:       v.SpawnTime,
+		PullingOver:     v.PullingOver,
+		PullOver:        v.PullOver,
+	}
+
+	if v.NextRoad != nil {
+		data.NextRoadID = v.NextRoad.ID
+	}
+	if v.TargetDespawn != nil {
+		data.TargetDespawnID = v.TargetDespawn.ID
+	}
+	if v.Origin != nil {
+		data.OriginID = v.Origin.ID
+	}
+	if c := v.TransitionCurve; c != nil {
+		data.TransitionCurve = &CurveData{
+			Points: [4][2]float64{{c.P0.X, c.P0.Y}, {c.P1.X, c.P1.Y}, {c.P2.X, c.P2.Y}, {c.P3.X, c.P3.Y}},
+			Length: c.Length,
+		}
+	}
+	if t := v.Trip; len(t.Route) > 0 {
+		data.Trip = &TripData{
+			Route:       t.Route,
+			Distance:    t.Distance,
+			Stops:       t.Stops,
+			StoppedTime: t.StoppedTime,
+			MaxSpeed:    t.MaxSpeed,
+			Stopped:     t.Stopped,
+		}
+	}
+
+	return data
+}
+
+// restoreState applies a snapshot's dynamic state to a freshly deserialized
+// world.
+func restoreState(w *world.World, state *SnapshotState) error {
+	w.SimTime = state.SimTime
+	if err := w.RestoreRNG(state.Seed, state.RNG); err != nil {
+		return fmt.Errorf("failed to restore random state: %w", err)
+	}
+
+	roadMap := make(map[string]*road.Road, len(w.Roads))
+	for _, rd := range w.Roads {
+		roadMap[rd.ID] = rd
+	}
+
+	for _, data := range state.Vehicles {
+		v, err := deserializeVehicle(w, data, roadMap)
+		if err != nil {
+			return err
+		}
+		w.Vehicles = append(w.Vehicles, v)
+	}
+
+	for _, sp := range w.SpawnPoints {
+		sp.Timer = state.SpawnTimers[sp.ID]
+	}
+
+	for _, lightData := range state.Lights {
+		light := w.FindTrafficLight(lightData.ID)
+		if light == nil {
+			return fmt.Errorf("snapshot references non-existent traffic light %s", lightData.ID)
+		}
+		light.State = road.LightState(lightData.State)
+		light.PrevState = road.LightState(lightData.PrevState)
+		light.Timer = lightData.Timer
+		light.Preempted = lightData.Preempted
+		light.PreemptState = road.LightState(lightData.PreemptState)
+	}
+
+	for _, id := range state.Incidents {
+		inc := w.FindIncident(id)
+		if inc == nil {
+			return fmt.Errorf("snapshot references non-existent incident %s", id)
+		}
+		inc.Active = true
+	}
+
+	for _, detectorData := range state.Detectors {
+		_, d := w.FindDetector(detectorData.ID)
+		if d == nil {
+			return fmt.Errorf("snapshot references non-existent detector %s", detectorData.ID)
+		}
+		d.Count = detectorData.Count
+		d.OccupiedTime = detectorData.OccupiedTime
+		d.SpeedSum = detectorData.SpeedSum
+		d.LastPassage = detectorData.LastPassage
+		d.Occupants = detectorData.Occupants
+	}
+
+	return nil
+}
+
+func deserializeVehicle(w *world.World, data VehicleData, roadMap map[string]*road.Road) (*vehicle.Vehicle, error) {
+	rd, exists := roadMap[data.RoadID]
+	if !exists {
+		return nil, fmt.Errorf("vehicle %s references non-existent road %s", data.ID, data.RoadID)
+	}
+
+	v := &vehicle.Vehicle{
+		ID:              data.ID,
+		Kind:            vehicle.Kind(data.Kind),
+		Road:            rd,
+		Distance:        data.Distance,
+		Speed:           data.Speed,
+		Pos:             vehicle.Vec2{X: data.X, Y: data.Y},
+		InTransition:    data.InTransition,
+		TransitionT:     data.TransitionT,
+		TransitionSpeed: data.TransitionSpeed,
+		SpawnTime:       data.SpawnTime,
+		PullingOver:     data.PullingOver,
+		PullOver:        data.PullOver,
+	}
+
+	if data.NextRoadID != "" {
+		if v.NextRoad, exists = roadMap[data.NextRoadID]; !exists {
+			return nil, fmt.Errorf("vehicle %s references non-existent road %s", data.ID, data.NextRoadID)
+		}
+	}
+	if data.TargetDespawnID != "" {
+		if v.TargetDespawn = w.FindDespawnPoint(data.TargetDespawnID); v.TargetDespawn == nil {
+			return nil, fmt.Errorf("vehicle %s references non-existent despawn point %s", data.ID, data.TargetDespawnID)
+		}
+	}
+	if data.OriginID != "" {
+		v.Origin = w.FindSpawnPoint(data.OriginID)
+	}
+	if c := data.TransitionCurve; c != nil {
+		v.TransitionCurve = &geom.BezierCurve{
+			P0:     geom.Point{X: c.Points[0][0], Y: c.Points[0][1]},
+			P1:     geom.Point{X: c.Points[1][0], Y: c.Points[1][1]},
+			P2:     geom.Point{X: c.Points[2][0], Y: c.Points[2][1]},
+			P3:     geom.Point{X: c.Points[3][0], Y: c.Points[3][1]},
+			Length: c.Length,
+		}
+	}
+	if t := data.Trip; t != nil {
+		v.Trip = vehicle.TripStats{
+			Route:       t.Route,
+			Distance:    t.Distance,
+			Stops:       t.Stops,
+			StoppedTime: t.StoppedTime,
+			MaxSpeed:    t.MaxSpeed,
+			Stopped:     t.Stopped,
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"encoding/json"
+	"testing"
+	"traffic-sim/internal/systems"
+	"traffic-sim/internal/world"
+)
+
+func newTestSystems() *systems.SystemManager {
+	sm := systems.NewSystemManager()
+	sm.AddSystem(systems.NewClockSystem())
+	sm.AddSystem(systems.NewIncidentSystem())
+	sm.AddSystem(systems.NewSpawnSystem())
+	sm.AddSystem(systems.NewCollisionSystem())
+	sm.AddSystem(systems.NewTrafficLightSystem())
+	sm.AddSystem(systems.NewRightOfWaySystem())
+	sm.AddSystem(systems.NewEmergencySystem())
+	sm.AddSystem(systems.NewPathfindingSystem())
+	sm.AddSystem(systems.NewMovementSystem())
+	sm.AddSystem(systems.NewDetectorSystem())
+	sm.AddSystem(systems.NewTripSystem())
+	sm.AddSystem(systems.NewDespawnSystem())
+	return sm
+}
+
+func newTestSave() *SaveFormat {
+	return &SaveFormat{
+		Version: CurrentVersion,
+		Nodes: []NodeData{
+			{ID: "n1", X: 0, Y: 0},
+			{ID: "n2", X: 200, Y: 0},
+			{ID: "n3", X: 400, Y: 0},
+			{ID: "n4", X: 200, Y: 200},
+		},
+		Roads: []RoadData{
+			{ID: "r1", FromNodeID: "n1", ToNodeID: "n2", MaxSpeed: 40, Width: 10},
+			{ID: "r2", FromNodeID: "n2", ToNodeID: "n3", MaxSpeed: 40, Width: 10},
+			{ID: "r3", FromNodeID: "n2", ToNodeID: "n4", MaxSpeed: 40, Width: 10},
+		},
+		SpawnPoints: []SpawnPointData{
+			{ID: "s1", NodeID: "n1", RoadID: "r1", Interval: 1, MinSpeed: 20, MaxSpeed: 40, MaxVehicles: 20, Enabled: true},
+		},
+		DespawnPoints: []DespawnPointData{
+			{ID: "d1", NodeID: "n3", RoadID: "r2", Enabled: true},
+			{ID: "d2", NodeID: "n4", RoadID: "r3", Enabled: true},
+		},
+		TrafficLights: []TrafficLightData{
+			{ID: "l1", IntersectionID: "n2", ControlledRoadIDs: []string{"r1"}, State: 2, GreenTime: 3, YellowTime: 1, RedTime: 3, Enabled: true},
+		},
+	}
+}
+
+func run(sm *systems.SystemManager, w *world.World, steps int) {
+	for i := 0; i < steps; i++ {
+		sm.Update(w, 0.016)
+	}
+}
+
+func TestSnapshotResumesExactly(t *testing.T) {
+	w, err := DeserializeWorld(newTestSave())
+	if err != nil {
+		t.Fatalf("deserialize failed: %v", err)
+	}
+	w.SetSeed(42)
+
+	sm := newTestSystems()
+	run(sm, w, 600)
+
+	if len(w.Vehicles) == 0 {
+		t.Fatal("expected vehicles on the network before the snapshot")
+	}
+
+	systemState, err := sm.ExportState()
+	if err != nil {
+		t.Fatalf("export state failed: %v", err)
+	}
+	snap, err := SerializeSnapshot(w, systemState, true)
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var loaded SaveFormat
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	restored, err := DeserializeWorld(&loaded)
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	restoredSystems := newTestSystems()
+	if err := restoredSystems.ImportState(loaded.State.Systems); err != nil {
+		t.Fatalf("import state failed: %v", err)
+	}
+
+	run(sm, w, 600)
+	run(restoredSystems, restored, 600)
+
+	if w.SimTime != restored.SimTime {
+		t.Fatalf("sim time diverged: %v vs %v", w.SimTime, restored.SimTime)
+	}
+	if len(w.Vehicles) != len(restored.Vehicles) {
+		t.Fatalf("vehicle count diverged: %d vs %d", len(w.Vehicles), len(restored.Vehicles))
+	}
+	for i, v := range w.Vehicles {
+		r := restored.Vehicles[i]
+		if v.ID != r.ID || v.Distance != r.Distance || v.Speed != r.Speed || v.Pos != r.Pos {
+			t.Errorf("vehicle %d diverged: %+v vs %+v", i, *v, *r)
+		}
+	}
+}
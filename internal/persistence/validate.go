@@ -0,0 +1,444 @@
+package persistence
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"traffic-sim/internal/road"
+)
+
+type Severity int
+
+const (
+	// SeverityWarning marks data that loads but is probably not what was
+	// meant.
+	SeverityWarning Severity = iota
+	// SeverityError marks data that cannot be loaded safely.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Problem is one thing wrong with a save. Object names what it is on, such
+// as "road r1".
+type Problem struct {
+	Severity Severity
+	Object   string
+	Message  string
+	// Repairable is set when Repair can fix the problem without guessing:
+	// by dropping something that refers to what does not exist, or by
+	// pointing a reference at the only thing it can mean.
+	Repairable bool
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Object, p.Message)
+}
+
+// Report lists every problem found in a save, in the order of the file.
+type Report struct {
+	Problems []Problem
+}
+
+func (r *Report) add(severity Severity, repairable bool, object, format string, args ...any) {
+	r.Problems = append(r.Problems, Problem{
+		Severity:   severity,
+		Object:     object,
+		Message:    fmt.Sprintf(format, args...),
+		Repairable: repairable,
+	})
+}
+
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+// ValidationError is returned for saves with errors in them.
+type ValidationError struct {
+	Report *Report
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "save file has %d errors", e.Report.Count(SeverityError))
+	for _, p := range e.Report.Problems {
+		if p.Severity == SeverityError {
+			b.WriteString("\n  " + p.Object + ": " + p.Message)
+		}
+	}
+	return b.String()
+}
+
+// Validate checks a save's references and values and reports every problem
+// it finds. A save with errors is rejected by DeserializeWorld.
+func Validate(saveData *SaveFormat) *Report {
+	return check(saveData, false)
+}
+
+// Repair fixes the repairable problems in saveData in place and reports
+// all the problems found, fixed or not. Validate afterwards lists what is
+// left.
+func Repair(saveData *SaveFormat) *Report {
+	return check(saveData, true)
+}
+
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// check runs through the save in dependency order, so that when repairing,
+// whatever refers to something dropped earlier is dropped in turn.
+func check(s *SaveFormat, repair bool) *Report {
+	r := &Report{}
+
+	nodes := make(map[string]NodeData, len(s.Nodes))
+	keptNodes := make([]NodeData, 0, len(s.Nodes))
+	for _, n := range s.Nodes {
+		object := "node " + n.ID
+		if n.ID == "" {
+			r.add(SeverityError, false, "node", "has no ID")
+		}
+		if !finite(n.X, n.Y) {
+			r.add(SeverityError, false, object, "has an invalid position (%g, %g)", n.X, n.Y)
+		}
+		if prev, dup := nodes[n.ID]; dup && n.ID != "" {
+			same := prev.X == n.X && prev.Y == n.Y
+			r.add(SeverityError, same, object, "is defined more than once")
+			if same && repair {
+				continue
+			}
+		} else {
+			nodes[n.ID] = n
+		}
+		keptNodes = append(keptNodes, n)
+	}
+	if repair {
+		s.Nodes = keptNodes
+	}
+
+	roadIDs := make(map[string]bool, len(s.Roads))
+	// broken holds the roads reported for missing nodes; whether things
+	// on them are at the right node is not checked again.
+	broken := make(map[string]bool)
+	keptRoads := make([]RoadData, 0, len(s.Roads))
+	for _, rd := range s.Roads {
+		object := "road " + rd.ID
+		if rd.ID == "" {
+			r.add(SeverityError, false, "road", "has no ID")
+		} else if roadIDs[rd.ID] {
+			r.add(SeverityError, false, object, "is defined more than once")
+		}
+		roadIDs[rd.ID] = true
+
+		from, fromOK := nodes[rd.FromNodeID]
+		to, toOK := nodes[rd.ToNodeID]
+		if !fromOK || !toOK {
+			missing := rd.FromNodeID
+			if fromOK {
+				missing = rd.ToNodeID
+			}
+			r.add(SeverityError, true, object, "references non-existent node %q", missing)
+			if repair {
+				continue
+			}
+			broken[rd.ID] = true
+		} else {
+			dx := to.X + rd.EndOffsetX - from.X - rd.StartOffsetX
+			dy := to.Y + rd.EndOffsetY - from.Y - rd.StartOffsetY
+			if math.Hypot(dx, dy) == 0 {
+				r.add(SeverityError, false, object, "has zero length")
+			}
+		}
+
+		if !finite(rd.MaxSpeed) || rd.MaxSpeed <= 0 {
+			r.add(SeverityError, false, object, "has speed limit %g; it must be above zero", rd.MaxSpeed)
+		}
+		if !finite(rd.Width) || rd.Width <= 0 {
+			r.add(SeverityWarning, true, object, "has width %g; repair sets the default of 12", rd.Width)
+			if repair {
+				rd.Width = 12
+			}
+		}
+		keptRoads = append(keptRoads, rd)
+	}
+	if repair {
+		s.Roads = keptRoads
+	}
+	roads := make(map[string]*RoadData, len(s.Roads))
+	for i := range s.Roads {
+		if _, dup := roads[s.Roads[i].ID]; !dup {
+			roads[s.Roads[i].ID] = &s.Roads[i]
+		}
+	}
+
+	for i := range s.Roads {
+		rd := &s.Roads[i]
+		if rd.ReverseRoadID == "" || roads[rd.ID] != rd {
+			continue
+		}
+		object := "road " + rd.ID
+		rev, ok := roads[rd.ReverseRoadID]
+		switch {
+		case !ok:
+			r.add(SeverityWarning, true, object, "has non-existent reverse road %q", rd.ReverseRoadID)
+		case rev == rd:
+			r.add(SeverityWarning, true, object, "is its own reverse road")
+		case rev.FromNodeID != rd.ToNodeID || rev.ToNodeID != rd.FromNodeID:
+			r.add(SeverityWarning, true, object, "has reverse road %s, which does not run the other way", rev.ID)
+		case rev.ReverseRoadID != rd.ID:
+			r.add(SeverityWarning, true, object, "has reverse road %s, which does not point back to it", rev.ID)
+			if repair && rev.ReverseRoadID == "" {
+				rev.ReverseRoadID = rd.ID
+				continue
+			}
+		default:
+			continue
+		}
+		if repair {
+			rd.ReverseRoadID = ""
+		}
+	}
+
+	spawnIDs := make(map[string]bool, len(s.SpawnPoints))
+	keptSpawns := make([]SpawnPointData, 0, len(s.SpawnPoints))
+	for _, sp := range s.SpawnPoints {
+		object := "spawn point " + sp.ID
+		if spawnIDs[sp.ID] {
+			r.add(SeverityError, false, object, "is defined more than once")
+		}
+		spawnIDs[sp.ID] = true
+
+		rd, roadOK := roads[sp.RoadID]
+		_, nodeOK := nodes[sp.NodeID]
+		switch {
+		case !roadOK:
+			r.add(SeverityError, true, object, "references non-existent road %q", sp.RoadID)
+			if repair {
+				continue
+			}
+		case broken[rd.ID]:
+		case !nodeOK || rd.FromNodeID != sp.NodeID:
+			r.add(SeverityWarning, true, object, "is at node %q, but road %s starts at node %s", sp.NodeID, rd.ID, rd.FromNodeID)
+			if repair {
+				sp.NodeID = rd.FromNodeID
+			}
+		}
+		if sp.Interval <= 0 {
+			r.add(SeverityWarning, false, object, "has interval %g and will spawn every tick", sp.Interval)
+		}
+		keptSpawns = append(keptSpawns, sp)
+	}
+	if repair {
+		s.SpawnPoints = keptSpawns
+	}
+
+	despawnIDs := make(map[string]bool, len(s.DespawnPoints))
+	keptDespawns := make([]DespawnPointData, 0, len(s.DespawnPoints))
+	for _, dp := range s.DespawnPoints {
+		object := "despawn point " + dp.ID
+		if despawnIDs[dp.ID] {
+			r.add(SeverityError, false, object, "is defined more than once")
+		}
+
+		rd, roadOK := roads[dp.RoadID]
+		_, nodeOK := nodes[dp.NodeID]
+		switch {
+		case !roadOK:
+			r.add(SeverityError, true, object, "references non-existent road %q", dp.RoadID)
+			if repair {
+				continue
+			}
+		case broken[rd.ID]:
+		case !nodeOK || rd.ToNodeID != dp.NodeID:
+			r.add(SeverityWarning, true, object, "is at node %q, but road %s ends at node %s", dp.NodeID, rd.ID, rd.ToNodeID)
+			if repair {
+				dp.NodeID = rd.ToNodeID
+			}
+		}
+		despawnIDs[dp.ID] = true
+		keptDespawns = append(keptDespawns, dp)
+	}
+	if repair {
+		s.DespawnPoints = keptDespawns
+	}
+
+	lightIDs := make(map[string]bool, len(s.TrafficLights))
+	keptLights := make([]TrafficLightData, 0, len(s.TrafficLights))
+	for _, tl := range s.TrafficLights {
+		object := "traffic light " + tl.ID
+		if lightIDs[tl.ID] {
+			r.add(SeverityError, false, object, "is defined more than once")
+		}
+		if _, ok := nodes[tl.IntersectionID]; !ok {
+			r.add(SeverityError, true, object, "references non-existent intersection %q", tl.IntersectionID)
+			if repair {
+				continue
+			}
+		}
+		lightIDs[tl.ID] = true
+
+		controlled := make([]string, 0, len(tl.ControlledRoadIDs))
+		for _, id := range tl.ControlledRoadIDs {
+			rd, ok := roads[id]
+			switch {
+			case !ok:
+				r.add(SeverityError, true, object, "controls non-existent road %q", id)
+			case rd.ToNodeID != tl.IntersectionID && !broken[id]:
+				r.add(SeverityWarning, true, object, "controls road %s, which does not enter intersection %s", id, tl.IntersectionID)
+			default:
+				controlled = append(controlled, id)
+				continue
+			}
+			if !repair {
+				controlled = append(controlled, id)
+			}
+		}
+		tl.ControlledRoadIDs = controlled
+		if len(controlled) == 0 {
+			r.add(SeverityWarning, false, object, "controls no roads")
+		}
+
+		if tl.State < int(road.LightRed) || tl.State > int(road.LightGreen) {
+			r.add(SeverityWarning, true, object, "has unknown state %d; repair starts it red", tl.State)
+			if repair {
+				tl.State = int(road.LightRed)
+			}
+		}
+		if tl.GreenTime <= 0 || tl.YellowTime <= 0 || tl.RedTime <= 0 {
+			r.add(SeverityWarning, false, object, "has phase times %g/%g/%g; each should be above zero", tl.GreenTime, tl.YellowTime, tl.RedTime)
+		}
+		keptLights = append(keptLights, tl)
+	}
+	if repair {
+		s.TrafficLights = keptLights
+	}
+
+	incidentIDs := make(map[string]bool, len(s.Incidents))
+	keptIncidents := make([]IncidentData, 0, len(s.Incidents))
+	for _, inc := range s.Incidents {
+		object := "incident " + inc.ID
+		if incidentIDs[inc.ID] {
+			r.add(SeverityError, false, object, "is defined more than once")
+		}
+		if _, ok := roads[inc.RoadID]; !ok {
+			r.add(SeverityError, true, object, "references non-existent road %q", inc.RoadID)
+			if repair {
+				continue
+			}
+		}
+		if _, err := road.ParseIncidentKind(inc.Kind); err != nil {
+			r.add(SeverityError, true, object, "has %v", err)
+			if repair {
+				continue
+			}
+		}
+		incidentIDs[inc.ID] = true
+		keptIncidents = append(keptIncidents, inc)
+	}
+	if repair {
+		s.Incidents = keptIncidents
+	}
+
+	if s.State != nil {
+		checkState(r, s.State, roads, despawnIDs, lightIDs, incidentIDs, repair)
+	}
+
+	return r
+}
+
+// checkState checks that a snapshot's vehicles and timers refer to parts of
+// the network that exist.
+func checkState(r *Report, state *SnapshotState, roads map[string]*RoadData, despawns, lights, incidents map[string]bool, repair bool) {
+	keptVehicles := make([]VehicleData, 0, len(state.Vehicles))
+	for _, v := range state.Vehicles {
+		object := "vehicle " + v.ID
+		_, onRoad := roads[v.RoadID]
+		_, nextOK := roads[v.NextRoadID]
+		switch {
+		case !onRoad:
+			r.add(SeverityError, true, object, "is on non-existent road %q", v.RoadID)
+		case v.NextRoadID != "" && !nextOK:
+			r.add(SeverityError, true, object, "is heading for non-existent road %q", v.NextRoadID)
+		case v.TargetDespawnID != "" && !despawns[v.TargetDespawnID]:
+			r.add(SeverityError, true, object, "is heading for non-existent despawn point %q", v.TargetDespawnID)
+		default:
+			keptVehicles = append(keptVehicles, v)
+			continue
+		}
+		if !repair {
+			keptVehicles = append(keptVehicles, v)
+		}
+	}
+	if repair {
+		state.Vehicles = keptVehicles
+	}
+
+	keptLights := make([]TrafficLightStateData, 0, len(state.Lights))
+	for _, l := range state.Lights {
+		if !lights[l.ID] {
+			r.add(SeverityError, true, "snapshot", "has state for non-existent traffic light %q", l.ID)
+			if repair {
+				continue
+			}
+		}
+		keptLights = append(keptLights, l)
+	}
+	if repair {
+		state.Lights = keptLights
+	}
+
+	keptIncidents := make([]string, 0, len(state.Incidents))
+	for _, id := range state.Incidents {
+		if !incidents[id] {
+			r.add(SeverityError, true, "snapshot", "activates non-existent incident %q", id)
+			if repair {
+				continue
+			}
+		}
+		keptIncidents = append(keptIncidents, id)
+	}
+	if repair {
+		state.Incidents = keptIncidents
+	}
+
+	detectors := make(map[string]bool)
+	for _, rd := range roads {
+		for _, d := range rd.Detectors {
+			detectors[d.ID] = true
+		}
+	}
+	keptDetectors := make([]DetectorStateData, 0, len(state.Detectors))
+	for _, d := range state.Detectors {
+		if !detectors[d.ID] {
+			r.add(SeverityError, true, "snapshot", "has counts for non-existent detector %q", d.ID)
+			if repair {
+				continue
+			}
+		}
+		keptDetectors = append(keptDetectors, d)
+	}
+	if repair {
+		state.Detectors = keptDetectors
+	}
+}
@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateCleanSave(t *testing.T) {
+	if report := Validate(newTestSave()); len(report.Problems) != 0 {
+		t.Errorf("expected no problems, got %v", report.Problems)
+	}
+}
+
+func TestValidateFindsProblems(t *testing.T) {
+	tests := []struct {
+		name       string
+		breakSave  func(s *SaveFormat)
+		object     string
+		severity   Severity
+		repairable bool
+	}{
+		{"duplicate node", func(s *SaveFormat) { s.Nodes = append(s.Nodes, NodeData{ID: "n1", X: 5}) }, "node n1", SeverityError, false},
+		{"duplicate road", func(s *SaveFormat) { s.Roads = append(s.Roads, s.Roads[0]) }, "road r1", SeverityError, false},
+		{"dangling node", func(s *SaveFormat) { s.Roads[1].ToNodeID = "n9" }, "road r2", SeverityError, true},
+		{"zero speed", func(s *SaveFormat) { s.Roads[0].MaxSpeed = 0 }, "road r1", SeverityError, false},
+		{"one-sided reverse", func(s *SaveFormat) {
+			s.Roads = append(s.Roads, RoadData{ID: "r4", FromNodeID: "n2", ToNodeID: "n1", MaxSpeed: 40, Width: 10})
+			s.Roads[0].ReverseRoadID = "r4"
+		}, "road r1", SeverityWarning, true},
+		{"spawn off its road", func(s *SaveFormat) { s.SpawnPoints[0].NodeID = "n2" }, "spawn point s1", SeverityWarning, true},
+		{"despawn off its road", func(s *SaveFormat) { s.DespawnPoints[0].NodeID = "n2" }, "despawn point d1", SeverityWarning, true},
+		{"light on outgoing road", func(s *SaveFormat) {
+			s.TrafficLights[0].ControlledRoadIDs = append(s.TrafficLights[0].ControlledRoadIDs, "r2")
+		}, "traffic light l1", SeverityWarning, true},
+		{"light on missing road", func(s *SaveFormat) {
+			s.TrafficLights[0].ControlledRoadIDs = append(s.TrafficLights[0].ControlledRoadIDs, "r9")
+		}, "traffic light l1", SeverityError, true},
+		{"vehicle on missing road", func(s *SaveFormat) {
+			s.State = &SnapshotState{Vehicles: []VehicleData{{ID: "v1", RoadID: "r9"}}}
+		}, "vehicle v1", SeverityError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSave()
+			tt.breakSave(s)
+
+			report := Validate(s)
+			if len(report.Problems) != 1 {
+				t.Fatalf("expected one problem, got %v", report.Problems)
+			}
+			p := report.Problems[0]
+			if p.Object != tt.object || p.Severity != tt.severity || p.Repairable != tt.repairable {
+				t.Errorf("got %s (repairable %v), want %s on %s (repairable %v)", p, p.Repairable, tt.severity, tt.object, tt.repairable)
+			}
+
+			if tt.repairable {
+				Repair(s)
+				if report := Validate(s); len(report.Problems) != 0 {
+					t.Errorf("problems left after repair: %v", report.Problems)
+				}
+			}
+		})
+	}
+}
+
+func TestRepairDropsDependents(t *testing.T) {
+	s := newTestSave()
+	s.Roads[0].FromNodeID = "n9"
+
+	Repair(s)
+	if len(s.Roads) != 2 || len(s.SpawnPoints) != 0 || len(s.TrafficLights[0].ControlledRoadIDs) != 0 {
+		t.Errorf("expected r1, its spawn point and its light control to be dropped, got %d roads, %d spawn points, lights %+v",
+			len(s.Roads), len(s.SpawnPoints), s.TrafficLights)
+	}
+	if _, err := DeserializeWorld(s); err != nil {
+		t.Errorf("repaired save failed to load: %v", err)
+	}
+}
+
+func TestDeserializeRejectsInvalidSave(t *testing.T) {
+	s := newTestSave()
+	s.Roads[1].ToNodeID = "n9"
+	s.DespawnPoints[1].RoadID = "r9"
+
+	_, err := DeserializeWorld(s)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "road r2") || !strings.Contains(err.Error(), "despawn point d2") {
+		t.Errorf("expected every error in the message, got %q", err)
+	}
+}
@@ -108,6 +108,28 @@ func (q *WorldQuery) FindNearestSpawnPoint(x, y, maxDistance float64) (*road.Spa
 	return nearestSpawnPoint, nearestX, nearestY
 }
 
+func (q *WorldQuery) FindNearestDespawnPoint(x, y, maxDistance float64) (*road.DespawnPoint, float64, float64) {
+	q.world.Mu.RLock()
+	defer q.world.Mu.RUnlock()
+
+	var nearestDespawnPoint *road.DespawnPoint
+	var nearestX, nearestY float64
+	minDist := maxDistance
+
+	for _, dp := range q.world.DespawnPoints {
+		dist := math.Sqrt((x-dp.Node.X)*(x-dp.Node.X) + (y-dp.Node.Y)*(y-dp.Node.Y))
+
+		if dist < minDist {
+			minDist = dist
+			nearestDespawnPoint = dp
+			nearestX = dp.Node.X
+			nearestY = dp.Node.Y
+		}
+	}
+
+	return nearestDespawnPoint, nearestX, nearestY
+}
+
 func (q *WorldQuery) closestPointOnRoad(rd *road.Road, x, y float64) (float64, float64, float64) {
 	x1, y1 := rd.From.X, rd.From.Y
 	x2, y2 := rd.To.X, rd.To.Y
@@ -179,4 +201,47 @@ func (q *WorldQuery) GetIncomingRoads(node *road.Node) []*road.Road {
 	incoming := make([]*road.Road, len(intersection.Incoming))
 	copy(incoming, intersection.Incoming)
 	return incoming
-}
\ No newline at end of file
+}
+
+func (q *WorldQuery) SimTime() float64 {
+	q.world.Mu.RLock()
+	defer q.world.Mu.RUnlock()
+
+	return q.world.SimTime
+}
+
+func (q *WorldQuery) FindIncidentAt(rd *road.Road, dist float64) *road.Incident {
+	q.world.Mu.RLock()
+	defer q.world.Mu.RUnlock()
+
+	for _, inc := range q.world.Incidents {
+		if inc.Road == rd && inc.Covers(dist) {
+			return inc
+		}
+	}
+
+	return nil
+}
+
+func (q *WorldQuery) FindDetector(id string) *road.Detector {
+	q.world.Mu.RLock()
+	defer q.world.Mu.RUnlock()
+
+	_, d := q.world.FindDetector(id)
+	return d
+}
+
+// FindDetectorAt returns the detector on rd whose loop lies within margin
+// of dist.
+func (q *WorldQuery) FindDetectorAt(rd *road.Road, dist, margin float64) *road.Detector {
+	q.world.Mu.RLock()
+	defer q.world.Mu.RUnlock()
+
+	for _, d := range rd.Detectors {
+		if dist >= d.Position-margin && dist <= d.Position+d.Length+margin {
+			return d
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package renderer
+
+import (
+	"image/color"
+	"traffic-sim/internal/heatmap"
+	"traffic-sim/internal/ui"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawLegend draws a box in the bottom-left corner describing the active
+// overlays.
+func drawLegend(screen *ebiten.Image, h *heatmap.Heatmap, screenHeight int) {
+	var rows []string
+	var scales [][2]string
+	if h.RoadMode != heatmap.RoadOverlayNone {
+		rows = append(rows, "Roads: "+h.RoadMode.String())
+		scales = append(scales, h.RoadScale())
+	}
+	if h.VehicleMode != heatmap.VehicleOverlayNone {
+		rows = append(rows, "Vehicles: "+h.VehicleMode.String())
+		scales = append(scales, h.VehicleScale())
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	width := float32(300)
+	rowHeight := float32(48)
+	x := float32(15)
+	y := float32(screenHeight) - rowHeight*float32(len(rows)) - 50
+	vector.FillRect(screen, x, y, width, rowHeight*float32(len(rows))+30, color.RGBA{40, 40, 50, 230}, false)
+
+	for i, text := range rows {
+		rowY := y + 10 + rowHeight*float32(i)
+		label := ui.NewLabel(float64(x+12), float64(rowY), text)
+		label.Size = 12
+		label.Draw(screen)
+
+		barY := rowY + 20
+		if scales[i][0] == "" {
+			for j, c := range heatmap.DestinationPalette {
+				vector.FillRect(screen, x+12+float32(j)*34, barY, 30, 10, c, false)
+			}
+			continue
+		}
+
+		steps := 40
+		stepWidth := (width - 24) / float32(steps)
+		for j := 0; j < steps; j++ {
+			c := heatmap.Color(float64(j) / float64(steps-1))
+			vector.FillRect(screen, x+12+float32(j)*stepWidth, barY, stepWidth+1, 10, c, false)
+		}
+
+		low := ui.NewLabel(float64(x+12), float64(barY+14), scales[i][0])
+		low.Size = 10
+		low.Draw(screen)
+		high := ui.NewLabel(float64(x+width-12-float32(len(scales[i][1]))*6), float64(barY+14), scales[i][1])
+		high.Size = 10
+		high.Draw(screen)
+	}
+
+	hint := ui.NewLabel(float64(x+12), float64(y+rowHeight*float32(len(rows))+10), "H: road overlay, V: vehicle overlay")
+	hint.Size = 10
+	hint.Color = color.RGBA{160, 160, 170, 255}
+	hint.Draw(screen)
+}
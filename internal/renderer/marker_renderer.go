@@ -1,9 +1,11 @@
 package renderer
 
 import (
+	"fmt"
 	"image/color"
 	"math"
 	"traffic-sim/internal/road"
+	"traffic-sim/internal/ui"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -166,6 +168,91 @@ func (mr *MarkerRenderer) drawArrowToNode(screen *ebiten.Image, node *road.Node,
 	vector.StrokeLine(screen, x, y, rightX, rightY, 3, clr, false)
 }
 
+func (mr *MarkerRenderer) RenderSpeedZones(screen *ebiten.Image, roads []*road.Road, simTime float64) {
+	for _, rd := range roads {
+		for _, z := range rd.SpeedZones {
+			bandColor := color.RGBA{255, 170, 40, 140}
+			signColor := color.RGBA{255, 255, 255, 255}
+			if !z.IsActive(simTime) {
+				bandColor = color.RGBA{120, 120, 130, 90}
+				signColor = color.RGBA{160, 160, 170, 255}
+			}
+
+			mr.drawRoadBand(screen, rd, z.Start, z.End, float32(rd.Width*0.35), bandColor)
+
+			x, y := rd.PosAt(z.Start)
+			vector.FillCircle(screen, float32(x), float32(y), 9, signColor, false)
+			vector.StrokeCircle(screen, float32(x), float32(y), 9, 2, color.RGBA{220, 40, 40, 255}, false)
+
+			label := ui.NewLabel(x-6, y-5, fmt.Sprintf("%.0f", z.Limit))
+			label.Size = 9
+			label.Color = color.RGBA{20, 20, 20, 255}
+			label.Draw(screen)
+		}
+	}
+}
+
+func (mr *MarkerRenderer) RenderDetectors(screen *ebiten.Image, roads []*road.Road) {
+	for _, rd := range roads {
+		for _, d := range rd.Detectors {
+			loopColor := color.RGBA{60, 200, 230, 150}
+			if d.Occupied() {
+				loopColor = color.RGBA{120, 255, 255, 230}
+			}
+
+			mr.drawRoadBand(screen, rd, d.Position, d.Position+d.Length, float32(rd.Width*0.8), loopColor)
+
+			x, y := rd.PosAt(d.Position)
+			label := ui.NewLabel(x+8, y-14, fmt.Sprintf("%s: %d", d.ID, d.Count))
+			label.Size = 9
+			label.Color = color.RGBA{160, 230, 245, 255}
+			label.Draw(screen)
+		}
+	}
+}
+
+func (mr *MarkerRenderer) RenderIncidents(screen *ebiten.Image, incidents []*road.Incident) {
+	for _, inc := range incidents {
+		bandColor := color.RGBA{255, 140, 30, 150}
+		if inc.IsClosure() {
+			bandColor = color.RGBA{230, 40, 40, 170}
+		}
+		if !inc.Active {
+			bandColor.A = 60
+		}
+
+		end := inc.EndDistance()
+		mr.drawRoadBand(screen, inc.Road, inc.Start, end, float32(inc.Road.Width*0.8), bandColor)
+
+		x, y := inc.Road.PosAt(inc.Start)
+		size := float32(5)
+		signColor := color.RGBA{255, 255, 255, bandColor.A + 60}
+		vector.StrokeLine(screen, float32(x)-size, float32(y)-size, float32(x)+size, float32(y)+size, 3, signColor, false)
+		vector.StrokeLine(screen, float32(x)-size, float32(y)+size, float32(x)+size, float32(y)-size, 3, signColor, false)
+	}
+}
+
+// drawRoadBand strokes the part of a road between two distances, following
+// the road geometry so it also works on curved roads.
+func (mr *MarkerRenderer) drawRoadBand(screen *ebiten.Image, rd *road.Road, start, end float64, width float32, clr color.RGBA) {
+	if end <= start {
+		return
+	}
+
+	steps := int(math.Ceil((end - start) / 5.0))
+	if steps < 1 {
+		steps = 1
+	}
+
+	px, py := rd.PosAt(start)
+	for i := 1; i <= steps; i++ {
+		d := start + (end-start)*float64(i)/float64(steps)
+		x, y := rd.PosAt(d)
+		vector.StrokeLine(screen, float32(px), float32(py), float32(x), float32(y), width, clr, false)
+		px, py = x, y
+	}
+}
+
 func (mr *MarkerRenderer) findNodeByID(id string, nodes []*road.Node) *road.Node {
 	for _, node := range nodes {
 		if node.ID == id {
@@ -19,6 +19,9 @@ func (or *OverlayRenderer) RenderToolOverlay(screen *ebiten.Image, inputHandler
 	mode := inputHandler.Mode()
 
 	switch mode {
+	case input.ModeNormal:
+		or.renderSelectedIntersection(screen, inputHandler)
+		or.renderChartRoad(screen, inputHandler)
 	case input.ModeRoadBuilding:
 		or.renderRoadBuildingOverlay(screen, inputHandler)
 	case input.ModeNodeMoving:
@@ -39,6 +42,14 @@ func (or *OverlayRenderer) RenderToolOverlay(screen *ebiten.Image, inputHandler
 		or.renderSpawnPointPropertiesOverlay(screen, inputHandler)
 	case input.ModeRoadCurving:
 		or.renderRoadCurvingOverlay(screen, inputHandler)
+	case input.ModeEmergency:
+		or.renderEmergencyOverlay(screen, inputHandler)
+	case input.ModeSpeedZone:
+		or.renderSpeedZoneOverlay(screen, inputHandler)
+	case input.ModeIncident:
+		or.renderIncidentOverlay(screen, inputHandler)
+	case input.ModeDetector:
+		or.renderDetectorOverlay(screen, inputHandler)
 	}
 }
 
@@ -372,4 +383,126 @@ func (or *OverlayRenderer) renderRoadCurvingOverlay(screen *ebiten.Image, inputH
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+func (or *OverlayRenderer) renderEmergencyOverlay(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	mouseX, mouseY := inputHandler.MousePos()
+	mx := float64(mouseX)
+	my := float64(mouseY)
+
+	emergencyTool := inputHandler.EmergencyTool()
+	selected := emergencyTool.GetSelectedSpawnPoint()
+
+	if selected == nil {
+		hoverSpawn := emergencyTool.GetHoverSpawnPoint(mx, my)
+		if hoverSpawn != nil {
+			vector.StrokeCircle(screen, float32(hoverSpawn.Node.X), float32(hoverSpawn.Node.Y), 15, 3, color.RGBA{255, 80, 80, 220}, false)
+		}
+		return
+	}
+
+	sx := float32(selected.Node.X)
+	sy := float32(selected.Node.Y)
+	vector.StrokeCircle(screen, sx, sy, 15, 3, color.RGBA{255, 255, 100, 255}, false)
+
+	hoverDespawn := emergencyTool.GetHoverDespawnPoint(mx, my)
+	if hoverDespawn != nil {
+		dx := float32(hoverDespawn.Node.X)
+		dy := float32(hoverDespawn.Node.Y)
+		vector.StrokeCircle(screen, dx, dy, 15, 3, color.RGBA{255, 80, 80, 255}, false)
+		vector.StrokeLine(screen, sx, sy, dx, dy, 2, color.RGBA{255, 80, 80, 180}, false)
+	} else {
+		vector.StrokeLine(screen, sx, sy, float32(mouseX), float32(mouseY), 2, color.RGBA{255, 80, 80, 100}, false)
+	}
+}
+
+func (or *OverlayRenderer) renderSpeedZoneOverlay(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	mouseX, mouseY := inputHandler.MousePos()
+	mx := float64(mouseX)
+	my := float64(mouseY)
+
+	zoneTool := inputHandler.SpeedZoneTool()
+	hoverRoad, hoverDist := zoneTool.GetHoverRoad(mx, my)
+
+	if hoverRoad != nil {
+		x, y := hoverRoad.PosAt(hoverDist)
+		vector.StrokeCircle(screen, float32(x), float32(y), 8, 2, color.RGBA{255, 170, 40, 255}, false)
+	}
+
+	selectedRoad := zoneTool.GetSelectedRoad()
+	if selectedRoad == nil {
+		return
+	}
+
+	sx, sy := selectedRoad.PosAt(zoneTool.GetStartDistance())
+	vector.FillCircle(screen, float32(sx), float32(sy), 6, color.RGBA{255, 255, 100, 255}, false)
+
+	if hoverRoad == selectedRoad {
+		ex, ey := selectedRoad.PosAt(hoverDist)
+		vector.StrokeLine(screen, float32(sx), float32(sy), float32(ex), float32(ey), float32(selectedRoad.Width*0.35), color.RGBA{255, 170, 40, 160}, false)
+	}
+}
+
+func (or *OverlayRenderer) renderIncidentOverlay(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	mouseX, mouseY := inputHandler.MousePos()
+	mx := float64(mouseX)
+	my := float64(mouseY)
+
+	incidentTool := inputHandler.IncidentTool()
+	hoverRoad, hoverDist := incidentTool.GetHoverRoad(mx, my)
+
+	if hoverRoad != nil {
+		x, y := hoverRoad.PosAt(hoverDist)
+		vector.StrokeCircle(screen, float32(x), float32(y), 8, 2, color.RGBA{255, 90, 40, 255}, false)
+	}
+
+	selectedRoad := incidentTool.GetSelectedRoad()
+	if selectedRoad == nil {
+		return
+	}
+
+	sx, sy := selectedRoad.PosAt(incidentTool.GetStartDistance())
+	vector.FillCircle(screen, float32(sx), float32(sy), 6, color.RGBA{255, 255, 100, 255}, false)
+
+	if hoverRoad == selectedRoad {
+		ex, ey := selectedRoad.PosAt(hoverDist)
+		vector.StrokeLine(screen, float32(sx), float32(sy), float32(ex), float32(ey), float32(selectedRoad.Width*0.5), color.RGBA{255, 90, 40, 160}, false)
+	}
+}
+
+func (or *OverlayRenderer) renderDetectorOverlay(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	mouseX, mouseY := inputHandler.MousePos()
+
+	hoverRoad, hoverDist := inputHandler.DetectorTool().GetHoverRoad(float64(mouseX), float64(mouseY))
+	if hoverRoad == nil {
+		return
+	}
+
+	x, y := hoverRoad.PosAt(hoverDist)
+	vector.StrokeCircle(screen, float32(x), float32(y), 8, 2, color.RGBA{60, 200, 230, 255}, false)
+}
+
+func (or *OverlayRenderer) renderSelectedIntersection(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	node := inputHandler.SelectedIntersection()
+	if node == nil {
+		return
+	}
+
+	vector.StrokeCircle(screen, float32(node.X), float32(node.Y), 16, 3, color.RGBA{120, 200, 255, 255}, false)
+}
+
+func (or *OverlayRenderer) renderChartRoad(screen *ebiten.Image, inputHandler *input.InputHandler) {
+	rd := inputHandler.ChartRoad()
+	if rd == nil {
+		return
+	}
+
+	highlight := color.RGBA{90, 150, 220, 120}
+	const steps = 24
+	px, py := rd.PosAt(0)
+	for i := 1; i <= steps; i++ {
+		x, y := rd.PosAt(rd.Length * float64(i) / steps)
+		vector.StrokeLine(screen, float32(px), float32(py), float32(x), float32(y), float32(rd.Width)+4, highlight, true)
+		px, py = x, y
+	}
+}
@@ -1,12 +1,23 @@
 package renderer
 
 import (
+	"fmt"
 	"image/color"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/figure"
+	"traffic-sim/internal/heatmap"
 	"traffic-sim/internal/input"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/replay"
 	"traffic-sim/internal/ui"
 	"traffic-sim/internal/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type Renderer struct {
@@ -20,10 +31,15 @@ type Renderer struct {
 	vehicleRenderer *VehicleRenderer
 	overlayRenderer *OverlayRenderer
 	markerRenderer  *MarkerRenderer
+	heatmap         *heatmap.Heatmap
+	images          config.ImagesConfig
+
+	replayPanel *ui.ReplayPanel
+	liveWorld   *world.World
 }
 
 func NewRenderer(w *world.World, inputHandler *input.InputHandler) *Renderer {
-	return &Renderer{
+	r := &Renderer{
 		World:           w,
 		InputHandler:    inputHandler,
 		Toolbar:         ui.NewToolbar(inputHandler,w),
@@ -33,36 +49,148 @@ func NewRenderer(w *world.World, inputHandler *input.InputHandler) *Renderer {
 		vehicleRenderer: NewVehicleRenderer(),
 		overlayRenderer: NewOverlayRenderer(),
 		markerRenderer:  NewMarkerRenderer(),
+		heatmap:         heatmap.New(),
+		images:          config.Default().Images,
 	}
+	r.roadRenderer.SetColorFunc(r.heatmap.RoadColor)
+	r.vehicleRenderer.SetColorFunc(r.heatmap.VehicleColor)
+	return r
 }
 
 func (r *Renderer) Update() error {
 	return nil
 }
 
+// UpdateOverlays handles the heatmap hotkeys: H cycles the road overlay and
+// V the vehicle overlay. F12 exports an image. They work in replays too.
+func (r *Renderer) UpdateOverlays() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		r.heatmap.CycleRoadMode()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		r.heatmap.CycleVehicleMode()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		r.ExportImage()
+	}
+}
+
+func (r *Renderer) SetImageConfig(images config.ImagesConfig) {
+	r.images = images
+}
+
+// ExportImage writes the world with the current overlays to the images
+// directory, drawn without the toolbar and tool overlays.
+func (r *Renderer) ExportImage() {
+	opts := figure.Options{
+		Scale:    r.images.Scale,
+		Legend:   r.images.Legend,
+		Roads:    r.heatmap.RoadMode,
+		Vehicles: r.heatmap.VehicleMode,
+	}
+	if r.images.Bounds != "" {
+		bounds, err := figure.ParseBounds(r.images.Bounds)
+		if err != nil {
+			log.Printf("Failed to export image: %v", err)
+			return
+		}
+		opts.Bounds = bounds
+	}
+	if r.replayPanel == nil && r.InputHandler.Simulator != nil {
+		opts.Metrics = r.InputHandler.Simulator.Metrics()
+	}
+
+	ext := ".png"
+	if strings.EqualFold(r.images.Format, "svg") {
+		ext = ".svg"
+	}
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	path := filepath.Join(r.images.Dir, fmt.Sprintf("figure_%s%s", timestamp, ext))
+
+	r.World.Mu.RLock()
+	err := figure.Write(path, r.World, opts)
+	r.World.Mu.RUnlock()
+	if err != nil {
+		log.Printf("Failed to export image: %v", err)
+		return
+	}
+	log.Printf("Image exported to: %s", path)
+}
+
 func (r *Renderer) Draw(screen *ebiten.Image) {
 	r.World.Mu.RLock()
 	defer r.World.Mu.RUnlock()
 
 	screen.Fill(color.RGBA{20, 20, 30, 255})
 
+	var collector *metrics.Collector
+	if r.replayPanel == nil && r.InputHandler.Simulator != nil {
+		collector = r.InputHandler.Simulator.Metrics()
+	}
+	r.heatmap.Prepare(r.World, collector)
+
 	r.roadRenderer.RenderRoads(screen, r.World.Roads,r.World.Nodes)
+	r.markerRenderer.RenderSpeedZones(screen, r.World.Roads, r.World.SimTime)
+	r.markerRenderer.RenderIncidents(screen, r.World.Incidents)
+	r.markerRenderer.RenderDetectors(screen, r.World.Roads)
 	r.markerRenderer.RenderSpawnPoints(screen, r.World.SpawnPoints)
 	r.markerRenderer.RenderDespawnPoints(screen, r.World.DespawnPoints)
 	r.vehicleRenderer.RenderVehicles(screen, r.World.Vehicles)
+	if r.replayPanel != nil {
+		r.markerRenderer.RenderTrafficLights(screen, r.World.TrafficLights, r.World.Nodes)
+		drawLegend(screen, r.heatmap, r.screenHeight)
+		r.replayPanel.Draw(screen)
+		return
+	}
 	r.overlayRenderer.RenderToolOverlay(screen, r.InputHandler)
 	r.markerRenderer.RenderTrafficLights(screen, r.World.TrafficLights, r.World.Nodes)
 	r.Toolbar.Draw(screen)
+	drawLegend(screen, r.heatmap, r.screenHeight)
 }
 
 func (r *Renderer) Layout(w, h int) (int, int) {
 	r.screenWidth = w
 	r.screenHeight = h
 	r.Toolbar.UpdatePanelPositions(w, h)
+	if r.replayPanel != nil {
+		r.replayPanel.UpdatePosition(w, h)
+	}
 	return w, h
 }
 
+// StartReplay shows the player's world instead of the live one until
+// StopReplay is called. The toolbar and editing tools are hidden meanwhile.
+func (r *Renderer) StartReplay(player *replay.Player, path string, onExit func()) {
+	if r.replayPanel == nil {
+		r.liveWorld = r.World
+	}
+	r.World = player.World()
+	r.replayPanel = ui.NewReplayPanel(player, path)
+	r.replayPanel.SetOnExit(onExit)
+	r.replayPanel.UpdatePosition(r.screenWidth, r.screenHeight)
+}
+
+func (r *Renderer) StopReplay() {
+	if r.replayPanel == nil {
+		return
+	}
+	r.World = r.liveWorld
+	r.liveWorld = nil
+	r.replayPanel = nil
+}
+
+func (r *Renderer) Replaying() bool {
+	return r.replayPanel != nil
+}
+
+func (r *Renderer) UpdateReplay(mouseX, mouseY int, clicked bool, dt float64) {
+	if r.replayPanel != nil {
+		r.replayPanel.Update(mouseX, mouseY, clicked, dt)
+	}
+}
+
 func (r *Renderer) ReplaceWorld(newWorld *world.World) {
+	r.StopReplay()
 	r.World = newWorld
 	r.Toolbar.ReplaceWorld(newWorld)
 }
@@ -17,6 +17,9 @@ var (
 
 type RoadRenderer struct {
 	shadowOffset float64
+
+	// colorFor, when set, overrides the base colour of individual roads.
+	colorFor func(rd *road.Road) (color.RGBA, bool)
 }
 
 func NewRoadRenderer() *RoadRenderer {
@@ -25,6 +28,19 @@ func NewRoadRenderer() *RoadRenderer {
 	}
 }
 
+func (rr *RoadRenderer) SetColorFunc(colorFor func(rd *road.Road) (color.RGBA, bool)) {
+	rr.colorFor = colorFor
+}
+
+func (rr *RoadRenderer) baseColor(rd *road.Road) color.Color {
+	if rr.colorFor != nil {
+		if c, ok := rr.colorFor(rd); ok {
+			return c
+		}
+	}
+	return colorRoadBase
+}
+
 func (rr *RoadRenderer) RenderRoads(screen *ebiten.Image, roads []*road.Road, nodes []*road.Node) {
 	for _, rd := range roads {
 		rr.drawSingleRoadShadow(screen, rd)
@@ -117,7 +133,7 @@ func (rr *RoadRenderer) drawStraightRoadBase(screen *ebiten.Image, rd *road.Road
 		return
 	}
 
-	rr.drawRoadRect(screen, x1, y1, x2, y2, width, perpX, perpY, 0, 0, colorRoadBase)
+	rr.drawRoadRect(screen, x1, y1, x2, y2, width, perpX, perpY, 0, 0, rr.baseColor(rd))
 }
 
 func (rr *RoadRenderer) drawCurvedRoadShadow(screen *ebiten.Image, rd *road.Road) {
@@ -125,7 +141,7 @@ func (rr *RoadRenderer) drawCurvedRoadShadow(screen *ebiten.Image, rd *road.Road
 }
 
 func (rr *RoadRenderer) drawCurvedRoadBase(screen *ebiten.Image, rd *road.Road) {
-	rr.drawCurvedRoad(screen, rd, 0, 0, colorRoadBase)
+	rr.drawCurvedRoad(screen, rd, 0, 0, rr.baseColor(rd))
 }
 
 func (rr *RoadRenderer) drawCurvedRoad(screen *ebiten.Image, rd *road.Road, offX, offY float64, clr color.Color) {
@@ -3,6 +3,7 @@ package renderer
 import (
 	"image/color"
 	"math"
+	"time"
 	"traffic-sim/internal/vehicle"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -12,6 +13,9 @@ import (
 type VehicleRenderer struct{
 	shadowOffset float32
 	showTargetLines bool
+
+	// colorFor, when set, overrides the body colour of individual vehicles.
+	colorFor func(v *vehicle.Vehicle) (color.RGBA, bool)
 }
 
 func NewVehicleRenderer() *VehicleRenderer {
@@ -25,6 +29,10 @@ func (vr *VehicleRenderer) SetShowTargetLines(show bool) {
 	vr.showTargetLines = show
 }
 
+func (vr *VehicleRenderer) SetColorFunc(colorFor func(v *vehicle.Vehicle) (color.RGBA, bool)) {
+	vr.colorFor = colorFor
+}
+
 func (vr *VehicleRenderer) RenderVehicles(screen *ebiten.Image, vehicles []*vehicle.Vehicle) {
 	if vr.showTargetLines {
 		for _, v := range vehicles {
@@ -94,8 +102,19 @@ func (vr *VehicleRenderer) renderSingleVehicle(screen *ebiten.Image, v *vehicle.
 	indices := []uint16{0, 1, 2, 0, 2, 3}
 	screen.DrawTriangles(shadowVerts, indices, vr.createWhiteImage(), nil)
 
+	overlayColor, overlaid := color.RGBA{}, false
+	if vr.colorFor != nil {
+		overlayColor, overlaid = vr.colorFor(v)
+	}
+
 	var bodyColor1, bodyColor2 color.RGBA
-	if v.TargetDespawn != nil {
+	if overlaid {
+		bodyColor1 = overlayColor
+		bodyColor2 = darken(overlayColor)
+	} else if v.IsEmergency() {
+		bodyColor1 = color.RGBA{245, 245, 245, 255}
+		bodyColor2 = color.RGBA{210, 210, 210, 255}
+	} else if v.TargetDespawn != nil {
 		bodyColor1 = color.RGBA{90, 90, 230, 255}
 		bodyColor2 = color.RGBA{70, 70, 180, 255}
 	} else {
@@ -177,8 +196,16 @@ func (vr *VehicleRenderer) renderSingleVehicle(screen *ebiten.Image, v *vehicle.
 			color.RGBA{255, 50, 50, 255}, false)
 	}
 
+	if v.IsEmergency() {
+		vr.renderLightBar(screen, cx, cy, cos, sin, hw)
+	}
+
 	var edgeColor color.RGBA
-	if v.TargetDespawn != nil {
+	if overlaid {
+		edgeColor = darken(bodyColor2)
+	} else if v.IsEmergency() {
+		edgeColor = color.RGBA{200, 40, 40, 255}
+	} else if v.TargetDespawn != nil {
 		edgeColor = color.RGBA{70, 70, 180, 255}
 	} else {
 		edgeColor = color.RGBA{180, 50, 50, 255}
@@ -190,6 +217,25 @@ func (vr *VehicleRenderer) renderSingleVehicle(screen *ebiten.Image, v *vehicle.
 	vector.StrokeLine(screen, rotated[3][0], rotated[3][1], rotated[0][0], rotated[0][1], 1, edgeColor, false)
 }
 
+func darken(c color.RGBA) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * 0.78), uint8(float64(c.G) * 0.78), uint8(float64(c.B) * 0.78), c.A}
+}
+
+// renderLightBar draws alternating red and blue roof lights.
+func (vr *VehicleRenderer) renderLightBar(screen *ebiten.Image, cx, cy, cos, sin, hw float32) {
+	red := color.RGBA{255, 40, 40, 255}
+	blue := color.RGBA{40, 80, 255, 255}
+	if (time.Now().UnixMilli()/250)%2 == 0 {
+		red, blue = blue, red
+	}
+
+	left := [2]float32{cx + (-hw*0.5)*cos, cy + (-hw*0.5)*sin}
+	right := [2]float32{cx + (hw*0.5)*cos, cy + (hw*0.5)*sin}
+
+	vector.FillCircle(screen, left[0], left[1], 1.6, red, false)
+	vector.FillCircle(screen, right[0], right[1], 1.6, blue, false)
+}
+
 func (vr *VehicleRenderer) createWhiteImage() *ebiten.Image {
 	img := ebiten.NewImage(1, 1)
 	img.Fill(color.White)
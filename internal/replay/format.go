@@ -0,0 +1,355 @@
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/road"
+)
+
+// A recording is a gzip stream: a header holding the network as save JSON,
+// followed by one frame per sample. IDs are interned, so each frame only
+// carries the strings it introduces, and vehicle values are float32.
+
+const (
+	magic         = "TSRP"
+	formatVersion = 1
+	maxStringSize = 1 << 16
+
+	maxFrameVehicles = 1 << 20
+)
+
+const (
+	flagTransition byte = 1 << iota
+	flagEmergency
+	flagPullingOver
+)
+
+type Frame struct {
+	SimTime  float64
+	Vehicles []VehicleState
+	Lights   []LightState
+}
+
+type VehicleState struct {
+	ID           string
+	Road         string
+	NextRoad     string
+	Distance     float32
+	X, Y         float32
+	Speed        float32
+	TransitionT  float32
+	InTransition bool
+	Emergency    bool
+	PullingOver  bool
+}
+
+type LightState struct {
+	ID    string
+	State road.LightState
+}
+
+type Recording struct {
+	Network *persistence.SaveFormat
+	Frames  []Frame
+}
+
+func (r *Recording) Start() float64 {
+	if len(r.Frames) == 0 {
+		return 0
+	}
+	return r.Frames[0].SimTime
+}
+
+func (r *Recording) End() float64 {
+	if len(r.Frames) == 0 {
+		return 0
+	}
+	return r.Frames[len(r.Frames)-1].SimTime
+}
+
+type Writer struct {
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	strings map[string]uint64
+	pending []string
+	scratch []byte
+}
+
+func NewWriter(out io.Writer, network *persistence.SaveFormat) (*Writer, error) {
+	gz := gzip.NewWriter(out)
+	w := &Writer{
+		gz:      gz,
+		buf:     bufio.NewWriter(gz),
+		strings: make(map[string]uint64),
+	}
+
+	data, err := json.Marshal(network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode network: %w", err)
+	}
+
+	w.buf.WriteString(magic)
+	w.buf.WriteByte(formatVersion)
+	w.writeUvarint(uint64(len(data)))
+	w.buf.Write(data)
+
+	return w, nil
+}
+
+func (w *Writer) WriteFrame(f *Frame) error {
+	w.pending = w.pending[:0]
+	for _, v := range f.Vehicles {
+		w.intern(v.ID)
+		w.intern(v.Road)
+		if v.NextRoad != "" {
+			w.intern(v.NextRoad)
+		}
+	}
+	for _, l := range f.Lights {
+		w.intern(l.ID)
+	}
+
+	w.writeFloat64(f.SimTime)
+	w.writeUvarint(uint64(len(w.pending)))
+	for _, s := range w.pending {
+		w.writeUvarint(uint64(len(s)))
+		w.buf.WriteString(s)
+	}
+
+	w.writeUvarint(uint64(len(f.Vehicles)))
+	for _, v := range f.Vehicles {
+		var flags byte
+		if v.InTransition {
+			flags |= flagTransition
+		}
+		if v.Emergency {
+			flags |= flagEmergency
+		}
+		if v.PullingOver {
+			flags |= flagPullingOver
+		}
+
+		w.writeUvarint(w.strings[v.ID])
+		w.writeUvarint(w.strings[v.Road])
+		if v.NextRoad == "" {
+			w.writeUvarint(0)
+		} else {
+			w.writeUvarint(w.strings[v.NextRoad] + 1)
+		}
+		w.buf.WriteByte(flags)
+		w.writeFloat32(v.Distance)
+		w.writeFloat32(v.X)
+		w.writeFloat32(v.Y)
+		w.writeFloat32(v.Speed)
+		if v.InTransition {
+			w.writeFloat32(v.TransitionT)
+		}
+	}
+
+	w.writeUvarint(uint64(len(f.Lights)))
+	for _, l := range f.Lights {
+		w.writeUvarint(w.strings[l.ID])
+		w.buf.WriteByte(byte(l.State))
+	}
+
+	return nil
+}
+
+func (w *Writer) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+func (w *Writer) intern(s string) {
+	if _, exists := w.strings[s]; exists {
+		return
+	}
+	w.strings[s] = uint64(len(w.strings))
+	w.pending = append(w.pending, s)
+}
+
+func (w *Writer) writeUvarint(x uint64) {
+	w.scratch = binary.AppendUvarint(w.scratch[:0], x)
+	w.buf.Write(w.scratch)
+}
+
+func (w *Writer) writeFloat32(f float32) {
+	w.scratch = binary.LittleEndian.AppendUint32(w.scratch[:0], math.Float32bits(f))
+	w.buf.Write(w.scratch)
+}
+
+func (w *Writer) writeFloat64(f float64) {
+	w.scratch = binary.LittleEndian.AppendUint64(w.scratch[:0], math.Float64bits(f))
+	w.buf.Write(w.scratch)
+}
+
+// Read decodes a whole recording. A recording cut short by a crash is read
+// up to its last complete frame.
+func Read(in io.Reader) (*Recording, error) {
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("not a recording: %w", err)
+	}
+	defer gz.Close()
+
+	r := &reader{buf: bufio.NewReader(gz)}
+
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r.buf, head); err != nil || string(head[:len(magic)]) != magic {
+		return nil, fmt.Errorf("not a recording")
+	}
+	if head[len(magic)] != formatVersion {
+		return nil, fmt.Errorf("unsupported recording version %d", head[len(magic)])
+	}
+
+	size := r.uvarint()
+	data := make([]byte, size)
+	if r.err == nil {
+		_, r.err = io.ReadFull(r.buf, data)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to read recording header: %w", r.err)
+	}
+
+	network, _, err := persistence.ParseSaveData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse recording network: %w", err)
+	}
+	rec := &Recording{Network: network}
+
+	for {
+		if _, err := r.buf.Peek(1); err == io.EOF {
+			break
+		}
+
+		f, ok := r.frame()
+		if !ok {
+			if errors.Is(r.err, io.ErrUnexpectedEOF) || errors.Is(r.err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to read frame %d: %w", len(rec.Frames), r.err)
+		}
+		rec.Frames = append(rec.Frames, f)
+	}
+
+	return rec, nil
+}
+
+type reader struct {
+	buf     *bufio.Reader
+	strings []string
+	err     error
+}
+
+func (r *reader) frame() (Frame, bool) {
+	var f Frame
+	f.SimTime = r.float64()
+
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		size := r.uvarint()
+		if size > maxStringSize {
+			r.err = fmt.Errorf("string of %d bytes is too long", size)
+		}
+		s := make([]byte, size)
+		if r.err == nil {
+			_, r.err = io.ReadFull(r.buf, s)
+		}
+		r.strings = append(r.strings, string(s))
+	}
+
+	count := r.uvarint()
+	if count > maxFrameVehicles {
+		r.err = fmt.Errorf("frame with %d vehicles is too large", count)
+	}
+	if r.err == nil {
+		f.Vehicles = make([]VehicleState, 0, count)
+	}
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		var v VehicleState
+		v.ID = r.str(r.uvarint())
+		v.Road = r.str(r.uvarint())
+		if next := r.uvarint(); next > 0 {
+			v.NextRoad = r.str(next - 1)
+		}
+		flags := r.byte()
+		v.InTransition = flags&flagTransition != 0
+		v.Emergency = flags&flagEmergency != 0
+		v.PullingOver = flags&flagPullingOver != 0
+		v.Distance = r.float32()
+		v.X = r.float32()
+		v.Y = r.float32()
+		v.Speed = r.float32()
+		if v.InTransition {
+			v.TransitionT = r.float32()
+		}
+		f.Vehicles = append(f.Vehicles, v)
+	}
+
+	count = r.uvarint()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		id := r.str(r.uvarint())
+		f.Lights = append(f.Lights, LightState{ID: id, State: road.LightState(r.byte())})
+	}
+
+	return f, r.err == nil
+}
+
+func (r *reader) str(i uint64) string {
+	if r.err != nil {
+		return ""
+	}
+	if i >= uint64(len(r.strings)) {
+		r.err = fmt.Errorf("string index %d out of range", i)
+		return ""
+	}
+	return r.strings[i]
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	x, err := binary.ReadUvarint(r.buf)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+	return x
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	b, err := r.buf.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+	return b
+}
+
+func (r *reader) float32() float32 {
+	var b [4]byte
+	if r.err == nil {
+		_, r.err = io.ReadFull(r.buf, b[:])
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b[:]))
+}
+
+func (r *reader) float64() float64 {
+	var b [8]byte
+	if r.err == nil {
+		_, r.err = io.ReadFull(r.buf, b[:])
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+}
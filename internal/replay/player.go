@@ -0,0 +1,232 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"traffic-sim/internal/geom"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/systems"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+var playbackSpeeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64}
+
+type curveKey struct {
+	from, to *road.Road
+}
+
+// Player shows a recording in its own world so the normal renderers can draw
+// it. No systems run; every frame is placed exactly as it was recorded.
+type Player struct {
+	recording *Recording
+	world     *world.World
+
+	time       float64
+	speedIndex int
+	playing    bool
+	frame      int
+
+	roads    map[string]*road.Road
+	lights   map[string]*road.TrafficLight
+	vehicles map[string]*vehicle.Vehicle
+	curves   map[curveKey]*geom.BezierCurve
+}
+
+func Open(path string) (*Player, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	rec, err := Read(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(rec)
+}
+
+func NewPlayer(rec *Recording) (*Player, error) {
+	if len(rec.Frames) == 0 {
+		return nil, fmt.Errorf("recording has no frames")
+	}
+
+	network := *rec.Network
+	network.State = nil
+	w, err := persistence.DeserializeWorld(&network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build recorded network: %w", err)
+	}
+
+	p := &Player{
+		recording:  rec,
+		world:      w,
+		speedIndex: 2,
+		roads:      make(map[string]*road.Road, len(w.Roads)),
+		lights:     make(map[string]*road.TrafficLight, len(w.TrafficLights)),
+		vehicles:   make(map[string]*vehicle.Vehicle),
+		curves:     make(map[curveKey]*geom.BezierCurve),
+	}
+	for _, rd := range w.Roads {
+		p.roads[rd.ID] = rd
+	}
+	for _, light := range w.TrafficLights {
+		p.lights[light.ID] = light
+	}
+
+	p.Seek(rec.Start())
+	return p, nil
+}
+
+func (p *Player) World() *world.World {
+	return p.world
+}
+
+func (p *Player) Start() float64 {
+	return p.recording.Start()
+}
+
+func (p *Player) End() float64 {
+	return p.recording.End()
+}
+
+func (p *Player) Time() float64 {
+	return p.time
+}
+
+func (p *Player) Playing() bool {
+	return p.playing
+}
+
+func (p *Player) TogglePlay() {
+	if !p.playing && p.time >= p.End() {
+		p.Seek(p.Start())
+	}
+	p.playing = !p.playing
+}
+
+func (p *Player) Speed() float64 {
+	return playbackSpeeds[p.speedIndex]
+}
+
+func (p *Player) Faster() {
+	if p.speedIndex < len(playbackSpeeds)-1 {
+		p.speedIndex++
+	}
+}
+
+func (p *Player) Slower() {
+	if p.speedIndex > 0 {
+		p.speedIndex--
+	}
+}
+
+// Update advances playback by dt seconds of wall time.
+func (p *Player) Update(dt float64) {
+	if !p.playing {
+		return
+	}
+
+	t := p.time + dt*p.Speed()
+	if t >= p.End() {
+		t = p.End()
+		p.playing = false
+	}
+	p.Seek(t)
+}
+
+// Seek shows the last frame recorded at or before t.
+func (p *Player) Seek(t float64) {
+	t = max(p.Start(), min(t, p.End()))
+	p.time = t
+
+	frames := p.recording.Frames
+	i := sort.Search(len(frames), func(i int) bool { return frames[i].SimTime > t }) - 1
+	if i < 0 {
+		i = 0
+	}
+	p.frame = i
+	p.apply(&frames[i])
+}
+
+// SeekFraction seeks to a point between the start (0) and end (1).
+func (p *Player) SeekFraction(f float64) {
+	p.Seek(p.Start() + f*(p.End()-p.Start()))
+}
+
+func (p *Player) Progress() float64 {
+	span := p.End() - p.Start()
+	if span <= 0 {
+		return 0
+	}
+	return (p.time - p.Start()) / span
+}
+
+func (p *Player) apply(f *Frame) {
+	w := p.world
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	w.SimTime = f.SimTime
+	w.Vehicles = w.Vehicles[:0]
+
+	seen := make(map[string]bool, len(f.Vehicles))
+	for _, state := range f.Vehicles {
+		rd := p.roads[state.Road]
+		if rd == nil {
+			continue
+		}
+
+		v := p.vehicles[state.ID]
+		if v == nil {
+			v = &vehicle.Vehicle{ID: state.ID}
+			p.vehicles[state.ID] = v
+		}
+		seen[state.ID] = true
+
+		v.Road = rd
+		v.NextRoad = p.roads[state.NextRoad]
+		v.Distance = float64(state.Distance)
+		v.Speed = float64(state.Speed)
+		v.Pos = vehicle.Vec2{X: float64(state.X), Y: float64(state.Y)}
+		v.PullingOver = state.PullingOver
+		v.Kind = vehicle.KindCar
+		if state.Emergency {
+			v.Kind = vehicle.KindEmergency
+		}
+
+		v.InTransition = state.InTransition && v.NextRoad != nil
+		v.TransitionCurve = nil
+		v.TransitionT = float64(state.TransitionT)
+		if v.InTransition {
+			v.TransitionCurve = p.curve(rd, v.NextRoad)
+		}
+
+		w.Vehicles = append(w.Vehicles, v)
+	}
+
+	for id := range p.vehicles {
+		if !seen[id] {
+			delete(p.vehicles, id)
+		}
+	}
+
+	for _, state := range f.Lights {
+		if light := p.lights[state.ID]; light != nil {
+			light.State = state.State
+		}
+	}
+}
+
+func (p *Player) curve(from, to *road.Road) *geom.BezierCurve {
+	key := curveKey{from, to}
+	c, exists := p.curves[key]
+	if !exists {
+		c = systems.TransitionCurve(from, to)
+		p.curves[key] = c
+	}
+	return c
+}
@@ -0,0 +1,139 @@
+package replay
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/world"
+)
+
+const (
+	RecordingDir = "recordings"
+	FileExt      = ".tsr"
+
+	// DefaultInterval is the sim time between recorded frames.
+	DefaultInterval = 0.1
+)
+
+// Recorder is a system that samples vehicles and lights into a recording
+// file. Add it to a simulator to start recording and Close it to finish.
+type Recorder struct {
+	path     string
+	interval float64
+	file     *os.File
+	writer   *Writer
+	lastTime float64
+	started  bool
+	frames   int
+	frame    Frame
+}
+
+func NewRecorder(path string, interval float64) (*Recorder, error) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	return &Recorder{path: path, interval: interval, file: file}, nil
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+func (r *Recorder) Frames() int {
+	return r.frames
+}
+
+func (r *Recorder) Reset() {
+}
+
+func (r *Recorder) Update(w *world.World, dt float64) {
+	if r.file == nil {
+		return
+	}
+
+	if r.writer == nil {
+		writer, err := NewWriter(r.file, persistence.SerializeWorld(w))
+		if err != nil {
+			log.Printf("Recording stopped: %v", err)
+			r.Close()
+			return
+		}
+		r.writer = writer
+	}
+
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	if r.started && w.SimTime-r.lastTime < r.interval {
+		return
+	}
+	r.started = true
+	r.lastTime = w.SimTime
+
+	r.capture(w)
+	if err := r.writer.WriteFrame(&r.frame); err != nil {
+		log.Printf("Recording stopped: %v", err)
+		r.file.Close()
+		r.file = nil
+		return
+	}
+	r.frames++
+}
+
+func (r *Recorder) capture(w *world.World) {
+	r.frame.SimTime = w.SimTime
+	r.frame.Vehicles = r.frame.Vehicles[:0]
+	r.frame.Lights = r.frame.Lights[:0]
+
+	for _, v := range w.Vehicles {
+		state := VehicleState{
+			ID:           v.ID,
+			Road:         v.Road.ID,
+			Distance:     float32(v.Distance),
+			X:            float32(v.Pos.X),
+			Y:            float32(v.Pos.Y),
+			Speed:        float32(v.Speed),
+			InTransition: v.InTransition,
+			Emergency:    v.IsEmergency(),
+			PullingOver:  v.PullingOver,
+		}
+		if v.NextRoad != nil {
+			state.NextRoad = v.NextRoad.ID
+		}
+		if v.InTransition {
+			state.TransitionT = float32(v.TransitionT)
+		}
+		r.frame.Vehicles = append(r.frame.Vehicles, state)
+	}
+
+	for _, light := range w.TrafficLights {
+		r.frame.Lights = append(r.frame.Lights, LightState{ID: light.ID, State: light.State})
+	}
+}
+
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	var err error
+	if r.writer != nil {
+		err = r.writer.Close()
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+	return err
+}
@@ -0,0 +1,107 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/road"
+)
+
+func testNetwork() *persistence.SaveFormat {
+	return &persistence.SaveFormat{
+		Version: persistence.CurrentVersion,
+		Nodes: []persistence.NodeData{
+			{ID: "n1", X: 0, Y: 0},
+			{ID: "n2", X: 100, Y: 0},
+		},
+		Roads: []persistence.RoadData{
+			{ID: "r1", FromNodeID: "n1", ToNodeID: "n2", MaxSpeed: 40, Width: 10},
+		},
+		TrafficLights: []persistence.TrafficLightData{
+			{ID: "l1", IntersectionID: "n2", ControlledRoadIDs: []string{"r1"}, GreenTime: 8, YellowTime: 2, RedTime: 8, Enabled: true},
+		},
+	}
+}
+
+func testFrames() []Frame {
+	return []Frame{
+		{SimTime: 0, Vehicles: []VehicleState{{ID: "s1-v1", Road: "r1", Distance: 5, X: 5, Speed: 20}}, Lights: []LightState{{ID: "l1", State: road.LightGreen}}},
+		{SimTime: 1, Vehicles: []VehicleState{{ID: "s1-v1", Road: "r1", Distance: 25, X: 25, Speed: 20}, {ID: "s1-v2", Road: "r1", Distance: 1, X: 1, Speed: 30, Emergency: true}}, Lights: []LightState{{ID: "l1", State: road.LightYellow}}},
+		{SimTime: 2, Vehicles: []VehicleState{{ID: "s1-v2", Road: "r1", Distance: 31, X: 31, Speed: 30, Emergency: true}}, Lights: []LightState{{ID: "l1", State: road.LightRed}}},
+	}
+}
+
+func writeRecording(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testNetwork())
+	if err != nil {
+		t.Fatalf("new writer failed: %v", err)
+	}
+	frames := testFrames()
+	for i := range frames {
+		if err := w.WriteFrame(&frames[i]); err != nil {
+			t.Fatalf("write frame failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	rec, err := Read(bytes.NewReader(writeRecording(t)))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	want := testFrames()
+	if len(rec.Frames) != len(want) {
+		t.Fatalf("expected %d frames, got %d", len(want), len(rec.Frames))
+	}
+	for i, f := range rec.Frames {
+		if f.SimTime != want[i].SimTime || len(f.Vehicles) != len(want[i].Vehicles) {
+			t.Fatalf("frame %d mismatch: %+v", i, f)
+		}
+		for j, v := range f.Vehicles {
+			if v != want[i].Vehicles[j] {
+				t.Errorf("frame %d vehicle %d: expected %+v, got %+v", i, j, want[i].Vehicles[j], v)
+			}
+		}
+		if f.Lights[0] != want[i].Lights[0] {
+			t.Errorf("frame %d light: expected %+v, got %+v", i, want[i].Lights[0], f.Lights[0])
+		}
+	}
+	if len(rec.Network.Roads) != 1 {
+		t.Errorf("expected network with 1 road, got %d", len(rec.Network.Roads))
+	}
+}
+
+func TestPlayerSeekShowsRecordedFrame(t *testing.T) {
+	rec, err := Read(bytes.NewReader(writeRecording(t)))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	p, err := NewPlayer(rec)
+	if err != nil {
+		t.Fatalf("new player failed: %v", err)
+	}
+
+	p.Seek(1.5)
+	w := p.World()
+	if len(w.Vehicles) != 2 {
+		t.Fatalf("expected 2 vehicles at t=1.5, got %d", len(w.Vehicles))
+	}
+	if w.Vehicles[0].Distance != 25 || !w.Vehicles[1].IsEmergency() {
+		t.Errorf("unexpected vehicles %+v %+v", *w.Vehicles[0], *w.Vehicles[1])
+	}
+	if w.TrafficLights[0].State != road.LightYellow {
+		t.Errorf("expected yellow light, got %v", w.TrafficLights[0].State)
+	}
+
+	p.TogglePlay()
+	p.Update(10)
+	if p.Playing() || p.Time() != p.End() || len(w.Vehicles) != 1 {
+		t.Errorf("expected playback to stop at the last frame, time %.1f, %d vehicles", p.Time(), len(w.Vehicles))
+	}
+}
@@ -0,0 +1,279 @@
+package rlenv
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// ControllerSpec describes one signal controller: the intersection it sits
+// at, the lights that turn green together in each phase and the incoming
+// roads its queue observations refer to, in order. PhaseSource says where
+// the phases came from: "config" for Config.Phases, "plan" for the lights'
+// fixed-time plan, or "lights" when the plan turns every light green at
+// once and each light is given a phase of its own.
+type ControllerSpec struct {
+	ID          string     `json:"id"`
+	Phases      [][]string `json:"phases"`
+	PhaseSource string     `json:"phaseSource"`
+	Approaches  []string   `json:"approaches"`
+}
+
+const (
+	PhasesFromConfig = "config"
+	PhasesFromPlan   = "plan"
+	PhasesFromLights = "lights"
+)
+
+// planTolerance is how far apart, in sim seconds, two lights' greens may
+// start in the fixed-time plan and still count as one phase.
+const planTolerance = 0.5
+
+// controller holds the lights of one intersection in the phase the agent
+// chose. Phase changes clear the old lights through yellow before the new
+// ones turn green.
+type controller struct {
+	id          string
+	lights      []*road.TrafficLight
+	phases      [][]*road.TrafficLight
+	phaseOf     map[*road.TrafficLight]int
+	phaseSource string
+	approaches  []*road.Road
+
+	phase    int
+	since    float64
+	clearing bool
+}
+
+// buildControllers groups the enabled lights by intersection. An
+// intersection listed in explicit gets those phases; any other gets the
+// phases of its lights' fixed-time plan, see planPhases.
+func buildControllers(w *world.World, explicit map[string][][]string) ([]*controller, error) {
+	byNode := make(map[string]*controller)
+	for _, tl := range w.TrafficLights {
+		if !tl.Enabled || tl.Intersection == nil {
+			continue
+		}
+		c := byNode[tl.Intersection.ID]
+		if c == nil {
+			c = &controller{id: tl.Intersection.ID, phaseOf: make(map[*road.TrafficLight]int)}
+			byNode[c.id] = c
+		}
+		c.lights = append(c.lights, tl)
+	}
+	for id := range explicit {
+		if byNode[id] == nil {
+			return nil, fmt.Errorf("phases given for %q, which has no enabled traffic lights", id)
+		}
+	}
+
+	controllers := make([]*controller, 0, len(byNode))
+	for _, c := range byNode {
+		sort.Slice(c.lights, func(i, j int) bool { return c.lights[i].ID < c.lights[j].ID })
+
+		if phases, ok := explicit[c.id]; ok {
+			if err := c.setPhases(phases); err != nil {
+				return nil, err
+			}
+			c.phaseSource = PhasesFromConfig
+		} else {
+			c.phases, c.phaseSource = planPhases(c.lights)
+		}
+		for i, group := range c.phases {
+			for _, tl := range group {
+				c.phaseOf[tl] = i
+			}
+		}
+
+		if intersection := w.GetIntersection(c.id); intersection != nil {
+			c.approaches = append(c.approaches, intersection.Incoming...)
+			sort.Slice(c.approaches, func(i, j int) bool { return c.approaches[i].ID < c.approaches[j].ID })
+		}
+
+		c.clearing = true
+		controllers = append(controllers, c)
+	}
+
+	sort.Slice(controllers, func(i, j int) bool { return controllers[i].id < controllers[j].id })
+	return controllers, nil
+}
+
+// setPhases resolves light IDs into phases. Every enabled light of the
+// intersection must be in exactly one phase.
+func (c *controller) setPhases(phases [][]string) error {
+	byID := make(map[string]*road.TrafficLight, len(c.lights))
+	for _, tl := range c.lights {
+		byID[tl.ID] = tl
+	}
+	seen := make(map[string]bool, len(c.lights))
+	for i, ids := range phases {
+		if len(ids) == 0 {
+			return fmt.Errorf("phase %d of %q has no lights", i, c.id)
+		}
+		group := make([]*road.TrafficLight, 0, len(ids))
+		for _, id := range ids {
+			tl := byID[id]
+			if tl == nil {
+				return fmt.Errorf("phase %d of %q: %q is not an enabled light at the intersection", i, c.id, id)
+			}
+			if seen[id] {
+				return fmt.Errorf("light %q is in more than one phase of %q", id, c.id)
+			}
+			seen[id] = true
+			group = append(group, tl)
+		}
+		c.phases = append(c.phases, group)
+	}
+	for _, tl := range c.lights {
+		if !seen[tl.ID] {
+			return fmt.Errorf("light %q of %q is in no phase", tl.ID, c.id)
+		}
+	}
+	return nil
+}
+
+// planPhases groups lights whose greens start together in the fixed-time
+// plan, wherever in its cycle each light is at load time. Phase 0 is the
+// group that is green now or turns green soonest. If the plan turns every
+// light green at once, as when they all start red with the same timers, the
+// agent could not choose anything, so each light becomes its own phase.
+func planPhases(lights []*road.TrafficLight) ([][]*road.TrafficLight, string) {
+	ordered := append([]*road.TrafficLight(nil), lights...)
+	sort.SliceStable(ordered, func(i, j int) bool { return untilGreen(ordered[i]) < untilGreen(ordered[j]) })
+
+	var phases [][]*road.TrafficLight
+	for _, tl := range ordered {
+		joined := false
+		for i, group := range phases {
+			if sameGreen(group[0], tl) {
+				phases[i] = append(group, tl)
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			phases = append(phases, []*road.TrafficLight{tl})
+		}
+	}
+
+	if len(phases) == 1 && len(ordered) > 1 {
+		phases = phases[:0]
+		for _, tl := range ordered {
+			phases = append(phases, []*road.TrafficLight{tl})
+		}
+		return phases, PhasesFromLights
+	}
+	return phases, PhasesFromPlan
+}
+
+// cycle is the length of a light's fixed-time plan: green, yellow, red and
+// yellow again.
+func cycle(tl *road.TrafficLight) float64 {
+	return tl.GreenTime + 2*tl.YellowTime + tl.RedTime
+}
+
+// cyclePos is how far, in sim seconds, the light is past the start of its
+// last green.
+func cyclePos(tl *road.TrafficLight) float64 {
+	switch {
+	case tl.State == road.LightGreen:
+		return tl.Timer
+	case tl.State == road.LightYellow && tl.PrevState == road.LightGreen:
+		return tl.GreenTime + tl.Timer
+	case tl.State == road.LightRed:
+		return tl.GreenTime + tl.YellowTime + tl.Timer
+	default:
+		return tl.GreenTime + tl.YellowTime + tl.RedTime + tl.Timer
+	}
+}
+
+// untilGreen orders lights by when their plan turns them green; lights that
+// are green now come first.
+func untilGreen(tl *road.TrafficLight) float64 {
+	pos := cyclePos(tl)
+	if pos < tl.GreenTime {
+		return -pos
+	}
+	return cycle(tl) - pos
+}
+
+// sameGreen reports whether the plans of a and b start green within
+// planTolerance of each other.
+func sameGreen(a, b *road.TrafficLight) bool {
+	c := cycle(a)
+	if c <= 0 {
+		return cycle(b) <= 0
+	}
+	d := math.Mod(math.Abs(cyclePos(a)-cyclePos(b)), c)
+	return min(d, c-d) <= planTolerance
+}
+
+func (c *controller) spec() ControllerSpec {
+	spec := ControllerSpec{
+		ID:          c.id,
+		Phases:      make([][]string, len(c.phases)),
+		PhaseSource: c.phaseSource,
+		Approaches:  make([]string, len(c.approaches)),
+	}
+	for i, group := range c.phases {
+		for _, tl := range group {
+			spec.Phases[i] = append(spec.Phases[i], tl.ID)
+		}
+	}
+	for i, rd := range c.approaches {
+		spec.Approaches[i] = rd.ID
+	}
+	return spec
+}
+
+func (c *controller) choose(phase int, now float64) {
+	if phase == c.phase {
+		return
+	}
+	c.phase = phase
+	c.since = now
+	c.clearing = true
+}
+
+// hold runs before every tick with the world lock held. Once a phase is
+// established the lights are left alone, so an emergency vehicle's
+// preemption still takes priority; a light it releases sends the controller
+// back through clearance.
+func (c *controller) hold() {
+	if !c.clearing {
+		for _, tl := range c.lights {
+			if !tl.Preempted {
+				c.clearing = true
+			}
+		}
+		if !c.clearing {
+			return
+		}
+	}
+
+	clear := true
+	for _, tl := range c.lights {
+		if c.phaseOf[tl] == c.phase {
+			continue
+		}
+		tl.Preempt(road.LightRed)
+		if tl.State != road.LightRed {
+			clear = false
+		}
+	}
+	green := clear
+	for _, tl := range c.phases[c.phase] {
+		if clear || tl.State == road.LightGreen {
+			tl.Preempt(road.LightGreen)
+		} else {
+			tl.Preempt(road.LightRed)
+		}
+		if tl.State != road.LightGreen {
+			green = false
+		}
+	}
+	c.clearing = !green
+}
@@ -0,0 +1,251 @@
+package rlenv
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/scenario"
+	"traffic-sim/internal/sim"
+	"traffic-sim/internal/world"
+)
+
+const (
+	DefaultTick     = 8 * time.Millisecond
+	DefaultInterval = 5.0
+	DefaultDuration = 3600.0
+
+	// A vehicle counts as queued when it is this close to the stop line and
+	// slower than queueSpeed, as in the intersection report.
+	approachDist = 100.0
+	queueSpeed   = 2.0
+)
+
+// Config fixes the timing of an environment. Interval and Duration are in
+// sim seconds; a Duration of 0 never ends an episode. Sim configures the
+// simulator; nil uses config.Default, so an environment does not depend on
+// the working directory.
+//
+// Phases optionally sets the phases of controllers by intersection ID, each
+// phase a list of light IDs. Intersections it leaves out take their phases
+// from the lights' fixed-time plan.
+type Config struct {
+	Tick     time.Duration
+	Interval float64
+	Duration float64
+	Sim      *config.Config
+	Phases   map[string][][]string
+}
+
+func DefaultConfig() Config {
+	return Config{Tick: DefaultTick, Interval: DefaultInterval, Duration: DefaultDuration, Sim: config.Default()}
+}
+
+// IntersectionObservation is what the agent sees of one controller: the
+// phase it last chose, sim seconds since then, whether the lights are still
+// clearing into it, and the queue on each approach in ControllerSpec order.
+type IntersectionObservation struct {
+	ID       string  `json:"id"`
+	Phase    int     `json:"phase"`
+	Elapsed  float64 `json:"elapsed"`
+	Clearing bool    `json:"clearing"`
+	Queues   []int   `json:"queues"`
+}
+
+type Observation struct {
+	Time          float64                   `json:"t"`
+	Vehicles      int                       `json:"vehicles"`
+	Intersections []IntersectionObservation `json:"intersections"`
+}
+
+// StepResult carries the observation after a decision interval. Reward is
+// the negative total delay, in vehicle-seconds, accumulated by every vehicle
+// on the network during the interval.
+type StepResult struct {
+	Observation *Observation `json:"observation"`
+	Reward      float64      `json:"reward"`
+	Done        bool         `json:"done"`
+}
+
+// Env is a Gym-style environment for training signal controllers. Each
+// intersection with traffic lights is a controller whose action is a phase
+// index. The simulation runs headless, as fast as it can, for one decision
+// interval per Step. An Env is not safe for concurrent use.
+type Env struct {
+	cfg Config
+
+	sim         *sim.Simulator
+	world       *world.World
+	controllers []*controller
+	byID        map[string]*controller
+	start       float64
+}
+
+func NewEnv(cfg Config) *Env {
+	if cfg.Tick <= 0 {
+		cfg.Tick = DefaultTick
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Sim == nil {
+		cfg.Sim = config.Default()
+	}
+	return &Env{cfg: cfg}
+}
+
+// Reset starts a new episode from a save file, together with the scenario
+// file next to it if there is one. The seed drives every random choice in
+// the run, so the same seed and actions reproduce an episode; it is ignored
+// for snapshots, which carry their own generator state.
+func (e *Env) Reset(savePath string, seed uint64) (*Observation, error) {
+	w, saveData, err := persistence.LoadWorldFrom(savePath)
+	if err != nil {
+		return nil, err
+	}
+	if saveData.State == nil {
+		w.SetSeed(seed)
+	}
+
+	simulator := sim.NewSimulatorWithConfig(w, e.cfg.Tick, e.cfg.Sim)
+	if path, ok := scenario.FindFor(savePath); ok {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		simulator.AddSystem(scenario.NewRunner(sc, simulator.Metrics()))
+	}
+	if saveData.State != nil {
+		if err := simulator.ImportSystemState(saveData.State.Systems); err != nil {
+			return nil, fmt.Errorf("failed to restore system state: %w", err)
+		}
+	}
+
+	controllers, err := buildControllers(w, e.cfg.Phases)
+	if err != nil {
+		return nil, err
+	}
+
+	e.sim = simulator
+	e.world = w
+	e.start = w.SimTime
+	e.controllers = controllers
+	e.byID = make(map[string]*controller, len(e.controllers))
+	for _, c := range e.controllers {
+		c.since = w.SimTime
+		e.byID[c.id] = c
+	}
+	if len(e.controllers) == 0 {
+		log.Printf("No traffic lights in %s; the agent has nothing to control", savePath)
+	}
+
+	return e.observe(), nil
+}
+
+// Controllers describes the controllers of the current episode, sorted by
+// intersection ID.
+func (e *Env) Controllers() []ControllerSpec {
+	specs := make([]ControllerSpec, len(e.controllers))
+	for i, c := range e.controllers {
+		specs[i] = c.spec()
+	}
+	return specs
+}
+
+// Step applies the chosen phases and runs one decision interval. Controllers
+// missing from actions keep their current phase.
+func (e *Env) Step(actions map[string]int) (*StepResult, error) {
+	if e.sim == nil {
+		return nil, errors.New("Reset must be called before Step")
+	}
+	for id, phase := range actions {
+		c := e.byID[id]
+		if c == nil {
+			return nil, fmt.Errorf("no signal controller at %q", id)
+		}
+		if phase < 0 || phase >= len(c.phases) {
+			return nil, fmt.Errorf("controller %q has no phase %d", id, phase)
+		}
+	}
+
+	w := e.world
+	w.Mu.Lock()
+	for id, phase := range actions {
+		e.byID[id].choose(phase, w.SimTime)
+	}
+	w.Mu.Unlock()
+
+	dt := e.cfg.Tick.Seconds()
+	ticks := max(1, int(math.Round(e.cfg.Interval/dt)))
+	delay := 0.0
+	for range ticks {
+		w.Mu.Lock()
+		for _, c := range e.controllers {
+			c.hold()
+		}
+		w.Mu.Unlock()
+
+		e.sim.Step()
+		delay += e.delay(dt)
+	}
+
+	obs := e.observe()
+	done := e.cfg.Duration > 0 && obs.Time-e.start >= e.cfg.Duration
+	return &StepResult{Observation: obs, Reward: -delay, Done: done}, nil
+}
+
+// delay is the time lost over one tick against each road's speed limit.
+func (e *Env) delay(dt float64) float64 {
+	w := e.world
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	total := 0.0
+	for _, v := range w.Vehicles {
+		if v.Road == nil || v.Road.MaxSpeed <= 0 {
+			continue
+		}
+		if lost := dt - v.Speed*dt/v.Road.MaxSpeed; lost > 0 {
+			total += lost
+		}
+	}
+	return total
+}
+
+func (e *Env) observe() *Observation {
+	w := e.world
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	queues := make(map[string]int)
+	for _, v := range w.Vehicles {
+		if v.InTransition || v.Road == nil || v.Speed >= queueSpeed || v.Road.Length-v.Distance > approachDist {
+			continue
+		}
+		queues[v.Road.ID]++
+	}
+
+	obs := &Observation{
+		Time:          w.SimTime,
+		Vehicles:      len(w.Vehicles),
+		Intersections: make([]IntersectionObservation, len(e.controllers)),
+	}
+	for i, c := range e.controllers {
+		io := IntersectionObservation{
+			ID:       c.id,
+			Phase:    c.phase,
+			Elapsed:  w.SimTime - c.since,
+			Clearing: c.clearing,
+			Queues:   make([]int, len(c.approaches)),
+		}
+		for j, rd := range c.approaches {
+			io.Queues[j] = queues[rd.ID]
+		}
+		obs.Intersections[i] = io
+	}
+	return obs
+}
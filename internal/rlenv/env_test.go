@@ -0,0 +1,255 @@
+package rlenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// writeTestSave writes a junction where two approaches, each with its own
+// light, merge into one exit road.
+func writeTestSave(t *testing.T) string {
+	t.Helper()
+	save := &persistence.SaveFormat{
+		Version: persistence.CurrentVersion,
+		Nodes: []persistence.NodeData{
+			{ID: "n1", X: 0, Y: 200},
+			{ID: "n2", X: 200, Y: 200},
+			{ID: "n3", X: 400, Y: 200},
+			{ID: "n4", X: 200, Y: 0},
+		},
+		Roads: []persistence.RoadData{
+			{ID: "r1", FromNodeID: "n1", ToNodeID: "n2", MaxSpeed: 20, Width: 10},
+			{ID: "r2", FromNodeID: "n2", ToNodeID: "n3", MaxSpeed: 20, Width: 10},
+			{ID: "r4", FromNodeID: "n4", ToNodeID: "n2", MaxSpeed: 20, Width: 10},
+		},
+		SpawnPoints: []persistence.SpawnPointData{
+			{ID: "s1", NodeID: "n1", RoadID: "r1", Interval: 2, MinSpeed: 10, MaxSpeed: 20, MaxVehicles: 50, Enabled: true},
+			{ID: "s4", NodeID: "n4", RoadID: "r4", Interval: 2, MinSpeed: 10, MaxSpeed: 20, MaxVehicles: 50, Enabled: true},
+		},
+		DespawnPoints: []persistence.DespawnPointData{
+			{ID: "d1", NodeID: "n3", RoadID: "r2", Enabled: true},
+		},
+		TrafficLights: []persistence.TrafficLightData{
+			{ID: "l1", IntersectionID: "n2", ControlledRoadIDs: []string{"r1"}, State: int(road.LightGreen), GreenTime: 8, YellowTime: 2, RedTime: 8, Enabled: true},
+			{ID: "l2", IntersectionID: "n2", ControlledRoadIDs: []string{"r4"}, State: int(road.LightRed), GreenTime: 8, YellowTime: 2, RedTime: 8, Enabled: true},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "junction.json")
+	if err := persistence.WriteSaveFile(path, save); err != nil {
+		t.Fatalf("write save failed: %v", err)
+	}
+	return path
+}
+
+func runEpisode(t *testing.T, env *Env, path string, seed uint64) float64 {
+	t.Helper()
+	if _, err := env.Reset(path, seed); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	total := 0.0
+	for i := 0; i < 12; i++ {
+		res, err := env.Step(map[string]int{"n2": (i / 4) % 2})
+		if err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+		total += res.Reward
+	}
+	return total
+}
+
+func TestEnvControlsPhases(t *testing.T) {
+	path := writeTestSave(t)
+
+	env := NewEnv(Config{Interval: 5, Duration: 60})
+	obs, err := env.Reset(path, 1)
+	if err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	specs := env.Controllers()
+	if len(specs) != 1 || specs[0].ID != "n2" || len(specs[0].Phases) != 2 || specs[0].Phases[1][0] != "l2" {
+		t.Fatalf("unexpected controllers %+v", specs)
+	}
+	if got := specs[0].Approaches; len(got) != 2 || got[0] != "r1" || got[1] != "r4" {
+		t.Errorf("unexpected approaches %v", got)
+	}
+	if len(obs.Intersections) != 1 || obs.Intersections[0].Phase != 0 {
+		t.Fatalf("unexpected initial observation %+v", obs)
+	}
+
+	// Holding phase 0 well past the fixed-time green keeps l2 red and lets
+	// its approach queue up.
+	var res *StepResult
+	for i := 0; i < 6; i++ {
+		if res, err = env.Step(nil); err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+	}
+	l1, l2 := env.world.FindTrafficLight("l1"), env.world.FindTrafficLight("l2")
+	if l1.State != road.LightGreen || l2.State != road.LightRed {
+		t.Fatalf("expected phase 0 to be held, got l1=%v l2=%v", l1.State, l2.State)
+	}
+	if q := res.Observation.Intersections[0].Queues[1]; q == 0 {
+		t.Errorf("expected a queue on the red approach")
+	}
+	if res.Reward >= 0 {
+		t.Errorf("expected queued vehicles to cost delay, got reward %f", res.Reward)
+	}
+
+	if res, err = env.Step(map[string]int{"n2": 1}); err != nil {
+		t.Fatalf("step failed: %v", err)
+	}
+	if l1.State != road.LightRed || l2.State != road.LightGreen {
+		t.Errorf("expected phase 1 after clearance, got l1=%v l2=%v", l1.State, l2.State)
+	}
+	if got := res.Observation.Intersections[0]; got.Phase != 1 || got.Elapsed < 4.9 || got.Clearing {
+		t.Errorf("unexpected observation after switching %+v", got)
+	}
+
+	for !res.Done {
+		if res, err = env.Step(nil); err != nil {
+			t.Fatalf("step failed: %v", err)
+		}
+	}
+	if res.Observation.Time < 60 {
+		t.Errorf("episode ended early at %f", res.Observation.Time)
+	}
+
+	if _, err := env.Step(map[string]int{"n2": 2}); err == nil {
+		t.Errorf("expected an out of range phase to be rejected")
+	}
+	if _, err := env.Step(map[string]int{"n9": 0}); err == nil {
+		t.Errorf("expected an unknown controller to be rejected")
+	}
+}
+
+func TestEnvIsDeterministic(t *testing.T) {
+	path := writeTestSave(t)
+
+	env := NewEnv(Config{Interval: 5})
+	first := runEpisode(t, env, path, 7)
+	if again := runEpisode(t, env, path, 7); again != first {
+		t.Errorf("expected the same seed to reproduce the episode, got %f and %f", first, again)
+	}
+}
+
+func TestServeProtocol(t *testing.T) {
+	path := writeTestSave(t)
+
+	client, server := net.Pipe()
+	go serveConn(server, NewEnv(Config{Interval: 1}))
+	defer client.Close()
+
+	r := bufio.NewReader(client)
+	call := func(req string, out any) {
+		t.Helper()
+		if _, err := client.Write([]byte(req + "\n")); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if err := json.Unmarshal(line, out); err != nil {
+			t.Fatalf("bad reply %s: %v", line, err)
+		}
+	}
+
+	var failed errorReply
+	call(`{"cmd":"step"}`, &failed)
+	if failed.Error == "" {
+		t.Errorf("expected step before reset to fail")
+	}
+
+	var reset resetReply
+	req, _ := json.Marshal(request{Cmd: "reset", Scenario: path, Seed: 3})
+	call(string(req), &reset)
+	if reset.Observation == nil || len(reset.Controllers) != 1 {
+		t.Fatalf("unexpected reset reply %+v", reset)
+	}
+
+	var step StepResult
+	call(`{"cmd":"step","actions":{"n2":1}}`, &step)
+	if step.Observation == nil || step.Observation.Time < 0.99 || step.Observation.Intersections[0].Phase != 1 {
+		t.Errorf("unexpected step reply %+v", step)
+	}
+}
+
+func junctionLights(states ...road.LightState) *world.World {
+	w := world.New()
+	n2 := road.NewIntersection("n2")
+	for i, state := range states {
+		tl := road.NewTrafficLight(fmt.Sprintf("l%d", i+1), n2, false)
+		tl.State = state
+		w.TrafficLights = append(w.TrafficLights, tl)
+	}
+	return w
+}
+
+func phaseIDs(t *testing.T, w *world.World, explicit map[string][][]string) ControllerSpec {
+	t.Helper()
+	controllers, err := buildControllers(w, explicit)
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if len(controllers) != 1 {
+		t.Fatalf("expected one controller, got %d", len(controllers))
+	}
+	return controllers[0].spec()
+}
+
+func TestPhasesFollowFixedTimePlan(t *testing.T) {
+	// Both lights red, l2 further into its red: l2 turns green first, l1
+	// half a cycle later, so they are separate phases.
+	w := junctionLights(road.LightRed, road.LightRed)
+	w.TrafficLights[1].Timer = 5
+	spec := phaseIDs(t, w, nil)
+	if spec.PhaseSource != PhasesFromPlan || fmt.Sprint(spec.Phases) != "[[l2] [l1]]" {
+		t.Errorf("unexpected phases %v from %q", spec.Phases, spec.PhaseSource)
+	}
+
+	// Mid-cycle: l1 clearing from green, l2 clearing from red, and l3 about
+	// to clear from red in step with l2.
+	w = junctionLights(road.LightYellow, road.LightYellow, road.LightRed)
+	w.TrafficLights[0].PrevState = road.LightGreen
+	w.TrafficLights[1].PrevState = road.LightRed
+	w.TrafficLights[2].Timer = 7.8
+	spec = phaseIDs(t, w, nil)
+	if fmt.Sprint(spec.Phases) != "[[l2 l3] [l1]]" {
+		t.Errorf("unexpected mid-cycle phases %v", spec.Phases)
+	}
+}
+
+func TestPhasesWithoutPlanSplitLights(t *testing.T) {
+	spec := phaseIDs(t, junctionLights(road.LightRed, road.LightRed), nil)
+	if spec.PhaseSource != PhasesFromLights || fmt.Sprint(spec.Phases) != "[[l1] [l2]]" {
+		t.Errorf("expected a phase per light, got %v from %q", spec.Phases, spec.PhaseSource)
+	}
+}
+
+func TestExplicitPhases(t *testing.T) {
+	w := junctionLights(road.LightGreen, road.LightRed, road.LightRed)
+	spec := phaseIDs(t, w, map[string][][]string{"n2": {{"l3"}, {"l1", "l2"}}})
+	if spec.PhaseSource != PhasesFromConfig || fmt.Sprint(spec.Phases) != "[[l3] [l1 l2]]" {
+		t.Errorf("unexpected phases %v from %q", spec.Phases, spec.PhaseSource)
+	}
+
+	for _, bad := range []map[string][][]string{
+		{"n2": {{"l1"}, {"l2"}}},
+		{"n2": {{"l1", "l2"}, {"l2", "l3"}}},
+		{"n2": {{"l1", "l2", "l3"}, {}}},
+		{"n2": {{"l1", "l2", "l3", "l9"}}},
+		{"n9": {{"l1"}}},
+	} {
+		if _, err := buildControllers(w, bad); err == nil {
+			t.Errorf("expected phases %v to be rejected", bad)
+		}
+	}
+}
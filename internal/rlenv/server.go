@@ -0,0 +1,124 @@
+package rlenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+)
+
+// The socket protocol is newline-delimited JSON, one request and one reply
+// per line. Every connection gets its own environment.
+//
+//	{"cmd":"reset","scenario":"saves/grid.json","seed":7}
+//	  -> {"observation":{...},"controllers":[...]}
+//	{"cmd":"step","actions":{"n3":1,"n7":0}}
+//	  -> {"observation":{...},"reward":-412.7,"done":false}
+//	{"cmd":"spec"}
+//	  -> {"controllers":[...]}
+//	{"cmd":"close"}
+//
+// A request that fails gets {"error":"..."} and leaves the episode as it was.
+
+type request struct {
+	Cmd      string         `json:"cmd"`
+	Scenario string         `json:"scenario"`
+	Seed     uint64         `json:"seed"`
+	Actions  map[string]int `json:"actions"`
+}
+
+type resetReply struct {
+	Observation *Observation     `json:"observation"`
+	Controllers []ControllerSpec `json:"controllers"`
+}
+
+type specReply struct {
+	Controllers []ControllerSpec `json:"controllers"`
+}
+
+type errorReply struct {
+	Error string `json:"error"`
+}
+
+// maxRequest bounds a single request line.
+const maxRequest = 1 << 20
+
+// Listen opens a listener for Serve. Like the HTTP API it only accepts
+// loopback addresses, and an address without a host binds to 127.0.0.1.
+func Listen(addr string) (net.Listener, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("address %q is not a loopback address", addr)
+		}
+	}
+	return net.Listen("tcp", net.JoinHostPort(host, port))
+}
+
+// Serve accepts connections until the listener is closed.
+func Serve(ln net.Listener, cfg Config) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn, NewEnv(cfg))
+	}
+}
+
+func serveConn(conn net.Conn, env *Env) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequest)
+	out := bufio.NewWriter(conn)
+	enc := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(errorReply{Error: fmt.Sprintf("invalid request: %v", err)})
+		} else if req.Cmd == "close" {
+			return
+		} else if reply, err := handle(env, &req); err != nil {
+			enc.Encode(errorReply{Error: err.Error()})
+		} else {
+			enc.Encode(reply)
+		}
+		if err := out.Flush(); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("RL client %s: %v", conn.RemoteAddr(), err)
+	}
+}
+
+func handle(env *Env, req *request) (any, error) {
+	switch req.Cmd {
+	case "reset":
+		obs, err := env.Reset(req.Scenario, req.Seed)
+		if err != nil {
+			return nil, err
+		}
+		return resetReply{Observation: obs, Controllers: env.Controllers()}, nil
+	case "step":
+		return env.Step(req.Actions)
+	case "spec":
+		return specReply{Controllers: env.Controllers()}, nil
+	}
+	return nil, fmt.Errorf("unknown command %q", req.Cmd)
+}
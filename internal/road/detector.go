@@ -0,0 +1,75 @@
+package road
+
+// DefaultDetectorLength is the length of a detection zone in metres, about
+// the size of a standard inductive loop.
+const DefaultDetectorLength = 2.0
+
+// Detector is a virtual loop detector covering Length metres of road from
+// Position. Like a field loop it only keeps running totals and its live
+// state; splitting the totals into aggregation periods is up to the reader.
+type Detector struct {
+	ID       string
+	Position float64
+	Length   float64
+
+	Count        int
+	OccupiedTime float64
+	SpeedSum     float64
+	LastPassage  float64
+	Occupants    []string
+}
+
+func NewDetector(id string, position float64) *Detector {
+	return &Detector{
+		ID:          id,
+		Position:    position,
+		Length:      DefaultDetectorLength,
+		LastPassage: -1,
+	}
+}
+
+// Overlaps reports whether a vehicle spanning [rear, front] along the road
+// is over the loop.
+func (d *Detector) Overlaps(rear, front float64) bool {
+	return front >= d.Position && rear <= d.Position+d.Length
+}
+
+func (d *Detector) Occupied() bool {
+	return len(d.Occupants) > 0
+}
+
+// Gap returns the time since the last vehicle was detected, which is what an
+// actuated controller uses to decide whether to extend a green. A detector
+// that has never fired reports simTime.
+func (d *Detector) Gap(simTime float64) float64 {
+	if d.Occupied() {
+		return 0
+	}
+	if d.LastPassage < 0 {
+		return simTime
+	}
+	return simTime - d.LastPassage
+}
+
+func (r *Road) AddDetector(d *Detector) {
+	r.Detectors = append(r.Detectors, d)
+}
+
+func (r *Road) RemoveDetector(id string) bool {
+	for i, d := range r.Detectors {
+		if d.ID == id {
+			r.Detectors = append(r.Detectors[:i], r.Detectors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Road) FindDetector(id string) *Detector {
+	for _, d := range r.Detectors {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
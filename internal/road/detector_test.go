@@ -0,0 +1,38 @@
+package road
+
+import "testing"
+
+func TestDetectorOverlaps(t *testing.T) {
+	d := NewDetector("d1", 50)
+
+	if d.Overlaps(40, 49) {
+		t.Error("Expected vehicle ending before the loop not to overlap")
+	}
+	if !d.Overlaps(46, 50.5) {
+		t.Error("Expected vehicle reaching the loop to overlap")
+	}
+	if !d.Overlaps(51.9, 56) {
+		t.Error("Expected vehicle leaving the loop to overlap")
+	}
+	if d.Overlaps(52.1, 57) {
+		t.Error("Expected vehicle past the loop not to overlap")
+	}
+}
+
+func TestDetectorGap(t *testing.T) {
+	d := NewDetector("d1", 50)
+
+	if got := d.Gap(30); got != 30 {
+		t.Errorf("Expected gap 30 before any detection, got %.1f", got)
+	}
+
+	d.LastPassage = 20
+	if got := d.Gap(30); got != 10 {
+		t.Errorf("Expected gap 10, got %.1f", got)
+	}
+
+	d.Occupants = []string{"v1"}
+	if got := d.Gap(30); got != 0 {
+		t.Errorf("Expected gap 0 while occupied, got %.1f", got)
+	}
+}
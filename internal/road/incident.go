@@ -0,0 +1,79 @@
+package road
+
+import "fmt"
+
+type IncidentKind int
+
+const (
+	IncidentClosure IncidentKind = iota
+	IncidentSlowdown
+)
+
+func (k IncidentKind) String() string {
+	switch k {
+	case IncidentClosure:
+		return "closure"
+	case IncidentSlowdown:
+		return "slowdown"
+	}
+	return "unknown"
+}
+
+func ParseIncidentKind(kind string) (IncidentKind, error) {
+	switch kind {
+	case "", "closure":
+		return IncidentClosure, nil
+	case "slowdown":
+		return IncidentSlowdown, nil
+	}
+	return 0, fmt.Errorf("unknown incident kind %q", kind)
+}
+
+// Incident blocks or slows part of a road during a window of sim time.
+// End <= Start covers the rest of the road from Start, and Until <= From
+// keeps the incident in place until it is cleared.
+type Incident struct {
+	ID          string
+	Kind        IncidentKind
+	Road        *Road
+	Start       float64
+	End         float64
+	From        float64
+	Until       float64
+	SpeedFactor float64
+	Active      bool
+}
+
+func NewIncident(id string, kind IncidentKind, rd *Road) *Incident {
+	return &Incident{
+		ID:          id,
+		Kind:        kind,
+		Road:        rd,
+		SpeedFactor: 0.5,
+	}
+}
+
+func (i *Incident) IsActiveAt(simTime float64) bool {
+	if simTime < i.From {
+		return false
+	}
+	if i.Until > i.From && simTime >= i.Until {
+		return false
+	}
+	return true
+}
+
+func (i *Incident) EndDistance() float64 {
+	if i.End <= i.Start {
+		return i.Road.Length
+	}
+	return i.End
+}
+
+func (i *Incident) Covers(dist float64) bool {
+	return dist >= i.Start && dist < i.EndDistance()
+}
+
+func (i *Incident) IsClosure() bool {
+	return i.Kind == IncidentClosure
+}
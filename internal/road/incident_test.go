@@ -0,0 +1,36 @@
+package road
+
+import "testing"
+
+func TestIncidentActiveWindow(t *testing.T) {
+	inc := NewIncident("i1", IncidentClosure, NewRoad("r1", &Node{ID: "a"}, &Node{ID: "b", X: 100}, 40.0))
+	inc.From = 60
+	inc.Until = 120
+
+	if inc.IsActiveAt(30) {
+		t.Errorf("Expected incident to be inactive before From")
+	}
+	if !inc.IsActiveAt(60) {
+		t.Errorf("Expected incident to be active at From")
+	}
+	if inc.IsActiveAt(120) {
+		t.Errorf("Expected incident to be inactive at Until")
+	}
+
+	inc.Until = 0
+	if !inc.IsActiveAt(10000) {
+		t.Errorf("Expected open-ended incident to stay active")
+	}
+}
+
+func TestIncidentCoversRestOfRoadWithoutEnd(t *testing.T) {
+	inc := NewIncident("i1", IncidentSlowdown, NewRoad("r1", &Node{ID: "a"}, &Node{ID: "b", X: 100}, 40.0))
+	inc.Start = 30
+
+	if inc.Covers(10) {
+		t.Errorf("Expected distance before Start not to be covered")
+	}
+	if !inc.Covers(99) {
+		t.Errorf("Expected distance near road end to be covered")
+	}
+}
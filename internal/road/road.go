@@ -28,6 +28,8 @@ type Road struct {
 	Curve *RoadCurve
 	StartOffset Point
 	EndOffset Point
+	SpeedZones []*SpeedZone
+	Detectors []*Detector
 }
 
 func NewRoad(id string, from, to *Node, maxSpeed float64) *Road{
@@ -108,6 +110,23 @@ func (r *Road) PosAt(dist float64) (float64, float64) {
     return x, y
 }
 
+// ShiftCurve moves the control point next to an end node that moved by
+// (dx, dy), so the curve keeps its shape and the direction it leaves and
+// enters the node. A loop road moves as a whole.
+func (r *Road) ShiftCurve(n *Node, dx, dy float64) {
+	if r.Curve == nil {
+		return
+	}
+	if r.From == n {
+		r.Curve.ControlP1.X += dx
+		r.Curve.ControlP1.Y += dy
+	}
+	if r.To == n {
+		r.Curve.ControlP2.X += dx
+		r.Curve.ControlP2.Y += dy
+	}
+}
+
 func CalculateLoopRoadOffsets(dragX, dragY, roadWidth float64) (Point, Point) {
 	const defaultOffsetFactor = 0.75
 	
@@ -0,0 +1,91 @@
+package road
+
+import "math"
+
+// SpeedZone overrides the road speed limit between two distances along the
+// road. A zone with a time window only applies while the sim clock is inside
+// it; with a Period the window repeats, e.g. a daily school-hours schedule.
+type SpeedZone struct {
+	ID    string
+	Start float64
+	End   float64
+	Limit float64
+
+	ActiveFrom float64
+	ActiveTo   float64
+	Period     float64
+}
+
+func NewSpeedZone(id string, start, end, limit float64) *SpeedZone {
+	if start > end {
+		start, end = end, start
+	}
+
+	return &SpeedZone{
+		ID:    id,
+		Start: start,
+		End:   end,
+		Limit: limit,
+	}
+}
+
+func (z *SpeedZone) Contains(dist float64) bool {
+	return dist >= z.Start && dist < z.End
+}
+
+func (z *SpeedZone) IsScheduled() bool {
+	return z.ActiveTo > z.ActiveFrom
+}
+
+func (z *SpeedZone) IsActive(simTime float64) bool {
+	if !z.IsScheduled() {
+		return true
+	}
+
+	t := simTime
+	if z.Period > 0 {
+		t = math.Mod(simTime, z.Period)
+	}
+
+	return t >= z.ActiveFrom && t < z.ActiveTo
+}
+
+func (r *Road) AddSpeedZone(z *SpeedZone) {
+	r.SpeedZones = append(r.SpeedZones, z)
+}
+
+func (r *Road) RemoveSpeedZone(id string) bool {
+	for i, z := range r.SpeedZones {
+		if z.ID == id {
+			r.SpeedZones = append(r.SpeedZones[:i], r.SpeedZones[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Road) FindSpeedZone(id string) *SpeedZone {
+	for _, z := range r.SpeedZones {
+		if z.ID == id {
+			return z
+		}
+	}
+	return nil
+}
+
+// SpeedLimitAt returns the limit in force at dist. Overlapping active zones
+// resolve to the lowest limit; outside any zone the road's MaxSpeed applies.
+func (r *Road) SpeedLimitAt(dist, simTime float64) float64 {
+	limit := math.Inf(1)
+
+	for _, z := range r.SpeedZones {
+		if z.Contains(dist) && z.IsActive(simTime) && z.Limit < limit {
+			limit = z.Limit
+		}
+	}
+
+	if math.IsInf(limit, 1) {
+		return r.MaxSpeed
+	}
+	return limit
+}
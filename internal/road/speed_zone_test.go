@@ -0,0 +1,53 @@
+package road
+
+import "testing"
+
+func TestSpeedLimitAtOutsideZonesUsesMaxSpeed(t *testing.T) {
+	r := NewRoad("r1", &Node{ID: "a"}, &Node{ID: "b", X: 200}, 50.0)
+	r.AddSpeedZone(NewSpeedZone("z1", 40, 120, 20))
+
+	if got := r.SpeedLimitAt(10, 0); got != 50.0 {
+		t.Errorf("Expected 50 before zone, got %.1f", got)
+	}
+	if got := r.SpeedLimitAt(80, 0); got != 20.0 {
+		t.Errorf("Expected 20 inside zone, got %.1f", got)
+	}
+	if got := r.SpeedLimitAt(120, 0); got != 50.0 {
+		t.Errorf("Expected 50 at zone end, got %.1f", got)
+	}
+}
+
+func TestSpeedLimitAtOverlappingZonesUsesLowest(t *testing.T) {
+	r := NewRoad("r1", &Node{ID: "a"}, &Node{ID: "b", X: 200}, 50.0)
+	r.AddSpeedZone(NewSpeedZone("z1", 0, 100, 30))
+	r.AddSpeedZone(NewSpeedZone("z2", 50, 150, 15))
+
+	if got := r.SpeedLimitAt(75, 0); got != 15.0 {
+		t.Errorf("Expected 15 in overlap, got %.1f", got)
+	}
+}
+
+func TestScheduledSpeedZoneRepeatsWithPeriod(t *testing.T) {
+	r := NewRoad("r1", &Node{ID: "a"}, &Node{ID: "b", X: 200}, 50.0)
+	z := NewSpeedZone("school", 40, 120, 20)
+	z.ActiveFrom = 100
+	z.ActiveTo = 200
+	z.Period = 1000
+	r.AddSpeedZone(z)
+
+	cases := []struct {
+		time     float64
+		expected float64
+	}{
+		{50, 50},
+		{150, 20},
+		{250, 50},
+		{1150, 20},
+	}
+
+	for _, c := range cases {
+		if got := r.SpeedLimitAt(80, c.time); got != c.expected {
+			t.Errorf("At t=%.0f expected %.0f, got %.1f", c.time, c.expected, got)
+		}
+	}
+}
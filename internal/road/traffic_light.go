@@ -19,6 +19,9 @@ type TrafficLight struct {
 	YellowTime   float64
 	RedTime      float64
 	Enabled      bool
+
+	Preempted    bool
+	PreemptState LightState
 }
 func NewTrafficLight(id string, intersection *Intersection, startGreen bool) *TrafficLight {
 	initialState := LightRed
@@ -50,6 +53,13 @@ func (tl *TrafficLight) Update(dt float64) {
 
 	tl.Timer += dt
 
+	if tl.Preempted {
+		if tl.State != LightYellow || tl.Timer >= tl.YellowTime {
+			tl.advancePreemption()
+		}
+		return
+	}
+
 	switch tl.State {
 	case LightGreen:
 		if tl.Timer >= tl.GreenTime {
@@ -75,6 +85,53 @@ func (tl *TrafficLight) Update(dt float64) {
 	}
 }
 
+// Preempt holds the light in the given state until ReleasePreemption is
+// called. The light gets there through the normal sequence: green clears
+// through yellow to red, red turns green through yellow, and a yellow
+// already running finishes first.
+func (tl *TrafficLight) Preempt(state LightState) {
+	if tl.Preempted && tl.PreemptState == state {
+		return
+	}
+
+	tl.Preempted = true
+	tl.PreemptState = state
+
+	if tl.State != LightYellow {
+		tl.advancePreemption()
+	}
+}
+
+// advancePreemption takes a preempted light one step towards PreemptState.
+// A yellow after green always ends in red, so a light heading for green
+// still clears first.
+func (tl *TrafficLight) advancePreemption() {
+	if tl.State == tl.PreemptState {
+		return
+	}
+	tl.Timer = 0.0
+
+	switch tl.State {
+	case LightGreen, LightRed:
+		tl.PrevState = tl.State
+		tl.State = LightYellow
+	case LightYellow:
+		if tl.PrevState == LightGreen || tl.PreemptState == LightRed {
+			tl.State = LightRed
+		} else {
+			tl.State = LightGreen
+		}
+	}
+}
+
+func (tl *TrafficLight) ReleasePreemption() {
+	if !tl.Preempted {
+		return
+	}
+	tl.Preempted = false
+	tl.Timer = 0.0
+}
+
 func (tl *TrafficLight) CanProceed() bool {
 	return tl.State == LightGreen || !tl.Enabled
 }
@@ -0,0 +1,80 @@
+package road
+
+import "testing"
+
+func TestPreemptGreenToRedPassesThroughYellow(t *testing.T) {
+	tl := NewTrafficLight("tl1", NewIntersection("n1"), true)
+
+	tl.Preempt(LightRed)
+	if tl.State != LightYellow {
+		t.Fatalf("Expected yellow clearance, got %v", tl.State)
+	}
+
+	tl.Update(tl.YellowTime)
+	if tl.State != LightRed {
+		t.Fatalf("Expected red after clearance, got %v", tl.State)
+	}
+
+	tl.Update(tl.RedTime * 3)
+	if tl.State != LightRed {
+		t.Errorf("Expected preempted light to hold red, got %v", tl.State)
+	}
+}
+
+func TestPreemptHoldsGreenUntilReleased(t *testing.T) {
+	tl := NewTrafficLight("tl1", NewIntersection("n1"), false)
+
+	tl.Preempt(LightGreen)
+	tl.Update(tl.GreenTime * 3)
+	if tl.State != LightGreen {
+		t.Fatalf("Expected preempted light to hold green, got %v", tl.State)
+	}
+
+	tl.ReleasePreemption()
+	tl.Update(tl.GreenTime)
+	if tl.State != LightYellow {
+		t.Errorf("Expected normal cycle to resume, got %v", tl.State)
+	}
+}
+
+func TestPreemptKeepsRunningYellow(t *testing.T) {
+	tl := NewTrafficLight("tl1", NewIntersection("n1"), true)
+	tl.Update(tl.GreenTime)
+	tl.Update(tl.YellowTime / 2)
+
+	tl.Preempt(LightRed)
+	if tl.State != LightYellow || tl.Timer != tl.YellowTime/2 {
+		t.Fatalf("Expected the running yellow to continue, got %v after %f", tl.State, tl.Timer)
+	}
+	tl.Update(tl.YellowTime / 2)
+	if tl.State != LightRed {
+		t.Errorf("Expected red once the yellow ran its normal time, got %v", tl.State)
+	}
+}
+
+func TestPreemptGreenFromYellowClearsFirst(t *testing.T) {
+	tl := NewTrafficLight("tl1", NewIntersection("n1"), true)
+	tl.Update(tl.GreenTime)
+
+	tl.Preempt(LightGreen)
+	if tl.State != LightYellow {
+		t.Fatalf("Expected the yellow to continue, got %v", tl.State)
+	}
+
+	var states []LightState
+	for i := 0; i < 100 && tl.State != LightGreen; i++ {
+		tl.Update(0.1)
+		if len(states) == 0 || states[len(states)-1] != tl.State {
+			states = append(states, tl.State)
+		}
+	}
+	want := []LightState{LightYellow, LightRed, LightYellow, LightGreen}
+	if len(states) != len(want) {
+		t.Fatalf("Expected %v on the way to green, got %v", want, states)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("Expected %v on the way to green, got %v", want, states)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/metrics"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+// Runner applies a scenario's actions once the sim clock reaches them. It is
+// added to a simulator like any other system.
+type Runner struct {
+	scenario *Scenario
+	metrics  *metrics.Collector
+	next     int
+}
+
+// NewRunner creates a runner for sc. The collector, if given, feeds metrics
+// into snapshot actions.
+func NewRunner(sc *Scenario, collector *metrics.Collector) *Runner {
+	return &Runner{scenario: sc, metrics: collector}
+}
+
+func (r *Runner) Reset() {
+	r.next = 0
+}
+
+func (r *Runner) StateKey() string {
+	return "scenario"
+}
+
+// ExportState records how far the timeline has got, so a resumed snapshot
+// does not replay actions that already ran.
+func (r *Runner) ExportState() (json.RawMessage, error) {
+	return json.Marshal(r.next)
+}
+
+func (r *Runner) ImportState(data json.RawMessage) error {
+	return json.Unmarshal(data, &r.next)
+}
+
+// Done reports whether every action has been applied.
+func (r *Runner) Done() bool {
+	return r.next >= len(r.scenario.Actions)
+}
+
+func (r *Runner) Update(w *world.World, dt float64) {
+	w.Mu.RLock()
+	now := w.SimTime
+	w.Mu.RUnlock()
+
+	for r.next < len(r.scenario.Actions) && r.scenario.Actions[r.next].At <= now {
+		a := r.scenario.Actions[r.next]
+		r.next++
+
+		if err := r.apply(w, a); err != nil {
+			log.Printf("scenario %s: %s at %.1fs: %v", r.scenario.Name, a.Type, a.At, err)
+		}
+	}
+}
+
+func (r *Runner) apply(w *world.World, a Action) error {
+	cmd, err := r.buildCommand(w, a)
+	if err != nil {
+		return err
+	}
+	return commands.NewRuntimeExecutor(w).Execute(cmd)
+}
+
+// buildCommand resolves the action's target under a read lock and turns it
+// into the matching editor command.
+func (r *Runner) buildCommand(w *world.World, a Action) (commands.Command, error) {
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	switch a.Type {
+	case ActionSpawnUpdate:
+		sp := w.FindSpawnPoint(a.Target)
+		if sp == nil {
+			return nil, fmt.Errorf("spawn point %s not found", a.Target)
+		}
+		cmd := &commands.UpdateSpawnPointPropertiesCommand{
+			SpawnPoint:  sp,
+			Interval:    sp.Interval,
+			MinSpeed:    sp.MinSpeed,
+			MaxSpeed:    sp.MaxSpeed,
+			MaxVehicles: sp.MaxVehicles,
+			Enabled:     sp.Enabled,
+		}
+		if a.Interval != nil {
+			cmd.Interval = *a.Interval
+		}
+		if a.MinSpeed != nil {
+			cmd.MinSpeed = *a.MinSpeed
+		}
+		if a.MaxSpeed != nil {
+			cmd.MaxSpeed = *a.MaxSpeed
+		}
+		if a.MaxVehicles != nil {
+			cmd.MaxVehicles = *a.MaxVehicles
+		}
+		if a.Enabled != nil {
+			cmd.Enabled = *a.Enabled
+		}
+		return cmd, nil
+
+	case ActionDespawnEnable, ActionDespawnDisable:
+		dp := w.FindDespawnPoint(a.Target)
+		if dp == nil {
+			return nil, fmt.Errorf("despawn point %s not found", a.Target)
+		}
+		return &commands.SetDespawnPointEnabledCommand{DespawnPoint: dp, Enabled: a.Type == ActionDespawnEnable}, nil
+
+	case ActionRoadClose, ActionRoadSlowdown:
+		rd := w.FindRoad(a.Target)
+		if rd == nil {
+			return nil, fmt.Errorf("road %s not found", a.Target)
+		}
+		kind := road.IncidentClosure
+		if a.Type == ActionRoadSlowdown {
+			kind = road.IncidentSlowdown
+		}
+		id := a.ID
+		if id == "" {
+			id = fmt.Sprintf("scenario-%s-%.0f", a.Target, a.At)
+		}
+		inc := road.NewIncident(id, kind, rd)
+		inc.Start = a.Start
+		inc.End = a.End
+		inc.From = a.At
+		inc.Until = a.Until
+		if a.SpeedFactor > 0 {
+			inc.SpeedFactor = a.SpeedFactor
+		}
+		return &commands.CreateIncidentCommand{Incident: inc}, nil
+
+	case ActionIncidentClear:
+		return &commands.ClearIncidentCommand{IncidentID: a.Target}, nil
+
+	case ActionLightTiming:
+		tl := w.FindTrafficLight(a.Target)
+		if tl == nil {
+			return nil, fmt.Errorf("traffic light %s not found", a.Target)
+		}
+		return &commands.UpdateTrafficLightTimingCommand{
+			Light:      tl,
+			GreenTime:  a.GreenTime,
+			YellowTime: a.YellowTime,
+			RedTime:    a.RedTime,
+		}, nil
+
+	case ActionSpeedZoneLimit:
+		rd := w.FindRoad(a.Target)
+		if rd == nil {
+			return nil, fmt.Errorf("road %s not found", a.Target)
+		}
+		return &commands.UpdateSpeedZoneCommand{Road: rd, ZoneID: a.Zone, Limit: a.Limit}, nil
+
+	case ActionEmergencyDispatch:
+		sp := w.FindSpawnPoint(a.Target)
+		if sp == nil {
+			return nil, fmt.Errorf("spawn point %s not found", a.Target)
+		}
+		var target *road.DespawnPoint
+		if a.Destination != "" {
+			target = w.FindDespawnPoint(a.Destination)
+			if target == nil {
+				return nil, fmt.Errorf("despawn point %s not found", a.Destination)
+			}
+		}
+		speed := 0.0
+		if a.MaxSpeed != nil {
+			speed = *a.MaxSpeed
+		}
+		return &commands.SpawnEmergencyVehicleCommand{SpawnPoint: sp, Target: target, Speed: speed}, nil
+
+	case ActionSnapshot:
+		return &commands.TakeSnapshotCommand{Label: a.Label, Path: a.File, Metrics: r.metrics}, nil
+	}
+
+	return nil, fmt.Errorf("unknown action type %q", a.Type)
+}
@@ -0,0 +1,146 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	ActionSpawnUpdate       = "spawn.update"
+	ActionDespawnEnable     = "despawn.enable"
+	ActionDespawnDisable    = "despawn.disable"
+	ActionRoadClose         = "road.close"
+	ActionRoadSlowdown      = "road.slowdown"
+	ActionIncidentClear     = "incident.clear"
+	ActionLightTiming       = "light.timing"
+	ActionSpeedZoneLimit    = "speedzone.limit"
+	ActionEmergencyDispatch = "emergency.dispatch"
+	ActionSnapshot          = "snapshot"
+)
+
+// Scenario is a list of actions applied to a world at given sim times.
+type Scenario struct {
+	Name    string   `json:"name" yaml:"name"`
+	Actions []Action `json:"actions" yaml:"actions"`
+}
+
+// Action is a single timed edit. Target names the entity the action applies
+// to; optional fields left unset keep the entity's current value.
+type Action struct {
+	At     float64 `json:"at" yaml:"at"`
+	Type   string  `json:"type" yaml:"type"`
+	Target string  `json:"target" yaml:"target"`
+	ID     string  `json:"id,omitempty" yaml:"id,omitempty"`
+
+	Interval    *float64 `json:"interval,omitempty" yaml:"interval,omitempty"`
+	MinSpeed    *float64 `json:"minSpeed,omitempty" yaml:"minSpeed,omitempty"`
+	MaxSpeed    *float64 `json:"maxSpeed,omitempty" yaml:"maxSpeed,omitempty"`
+	MaxVehicles *int     `json:"maxVehicles,omitempty" yaml:"maxVehicles,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	GreenTime  float64 `json:"greenTime,omitempty" yaml:"greenTime,omitempty"`
+	YellowTime float64 `json:"yellowTime,omitempty" yaml:"yellowTime,omitempty"`
+	RedTime    float64 `json:"redTime,omitempty" yaml:"redTime,omitempty"`
+
+	Start       float64 `json:"start,omitempty" yaml:"start,omitempty"`
+	End         float64 `json:"end,omitempty" yaml:"end,omitempty"`
+	Until       float64 `json:"until,omitempty" yaml:"until,omitempty"`
+	SpeedFactor float64 `json:"speedFactor,omitempty" yaml:"speedFactor,omitempty"`
+
+	Zone  string  `json:"zone,omitempty" yaml:"zone,omitempty"`
+	Limit float64 `json:"limit,omitempty" yaml:"limit,omitempty"`
+
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+	File  string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+var knownActions = map[string]bool{
+	ActionSpawnUpdate:       true,
+	ActionDespawnEnable:     true,
+	ActionDespawnDisable:    true,
+	ActionRoadClose:         true,
+	ActionRoadSlowdown:      true,
+	ActionIncidentClear:     true,
+	ActionLightTiming:       true,
+	ActionSpeedZoneLimit:    true,
+	ActionEmergencyDispatch: true,
+	ActionSnapshot:          true,
+}
+
+// Load reads a scenario from a .json, .yaml or .yml file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	return Parse(data, filepath.Ext(path))
+}
+
+func Parse(data []byte, ext string) (*Scenario, error) {
+	var sc Scenario
+
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &sc); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &sc); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario: %w", err)
+		}
+	}
+
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(sc.Actions, func(i, j int) bool {
+		return sc.Actions[i].At < sc.Actions[j].At
+	})
+
+	return &sc, nil
+}
+
+func (sc *Scenario) Validate() error {
+	for i, a := range sc.Actions {
+		if !knownActions[a.Type] {
+			return fmt.Errorf("action %d: unknown type %q", i, a.Type)
+		}
+		if a.At < 0 {
+			return fmt.Errorf("action %d: negative time %.1f", i, a.At)
+		}
+		if a.Target == "" && a.Type != ActionSnapshot {
+			return fmt.Errorf("action %d (%s): missing target", i, a.Type)
+		}
+	}
+	return nil
+}
+
+// PathsFor lists the scenario files looked for next to a save file, e.g.
+// saves/rush.json pairs with saves/rush.scenario.json or .yaml.
+func PathsFor(savePath string) []string {
+	base := strings.TrimSuffix(savePath, filepath.Ext(savePath))
+	return []string{
+		base + ".scenario.json",
+		base + ".scenario.yaml",
+		base + ".scenario.yml",
+	}
+}
+
+func FindFor(savePath string) (string, bool) {
+	for _, p := range PathsFor(savePath) {
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	return "", false
+}
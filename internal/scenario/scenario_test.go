@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"testing"
+	"traffic-sim/internal/autosave"
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+func TestParseSortsActionsByTime(t *testing.T) {
+	data := []byte(`
+name: rush
+actions:
+  - at: 120
+    type: despawn.disable
+    target: d1
+  - at: 30
+    type: spawn.update
+    target: s1
+    interval: 0.5
+`)
+	sc, err := Parse(data, ".yaml")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(sc.Actions) != 2 || sc.Actions[0].At != 30 || sc.Actions[1].At != 120 {
+		t.Fatalf("expected actions ordered by time, got %+v", sc.Actions)
+	}
+	if sc.Actions[0].Interval == nil || *sc.Actions[0].Interval != 0.5 {
+		t.Errorf("expected interval 0.5, got %v", sc.Actions[0].Interval)
+	}
+}
+
+func TestParseRejectsUnknownType(t *testing.T) {
+	_, err := Parse([]byte(`{"actions":[{"at":1,"type":"teleport","target":"x"}]}`), ".json")
+	if err == nil {
+		t.Fatal("expected error for unknown action type")
+	}
+}
+
+func TestRunnerAppliesDueActions(t *testing.T) {
+	w := world.New()
+	dp := &road.DespawnPoint{ID: "d1", Enabled: true}
+	w.DespawnPoints = append(w.DespawnPoints, dp)
+
+	sc, err := Parse([]byte(`{"actions":[{"at":10,"type":"despawn.disable","target":"d1"}]}`), ".json")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	r := NewRunner(sc, nil)
+
+	w.SimTime = 5
+	r.Update(w, 0.1)
+	if !dp.Enabled {
+		t.Fatal("action applied before its time")
+	}
+
+	w.SimTime = 10
+	r.Update(w, 0.1)
+	if dp.Enabled {
+		t.Error("expected despawn point to be disabled")
+	}
+	if !r.Done() {
+		t.Error("expected runner to be done")
+	}
+}
+
+func TestRunnerActionsAreNotEdits(t *testing.T) {
+	w := world.New()
+	dp := &road.DespawnPoint{ID: "d1", Enabled: true}
+	w.DespawnPoints = append(w.DespawnPoints, dp)
+
+	saver := autosave.New(t.TempDir(), config.AutosaveConfig{Interval: 60, Keep: 1})
+	saver.Attach(w)
+
+	sc, err := Parse([]byte(`{"actions":[{"at":0,"type":"despawn.disable","target":"d1"}]}`), ".json")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	NewRunner(sc, nil).Update(w, 0.1)
+	if dp.Enabled {
+		t.Fatal("expected the action to run")
+	}
+	if saver.Dirty() {
+		t.Error("a scenario action should not mark the world as edited")
+	}
+}
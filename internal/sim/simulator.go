@@ -1,10 +1,14 @@
 package sim
 
 import (
+	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"traffic-sim/internal/config"
+	"traffic-sim/internal/metrics"
 	"traffic-sim/internal/systems"
 	"traffic-sim/internal/world"
 )
@@ -14,7 +18,15 @@ type Simulator struct {
 	tickRate      time.Duration
 	systemManager *systems.SystemManager
 	accumulator   float64
-	paused		bool
+	// speed scales wall-clock time in UpdateOnce; it is guarded by stepMu.
+	speed         float64
+	paused        atomic.Bool
+	// stepMu serializes ticks, so the API server can step or pause the
+	// simulation from its own goroutines while the game loop runs.
+	stepMu        sync.Mutex
+	metrics       *metrics.Collector
+	intersections *metrics.IntersectionMonitor
+	sampler       *metrics.Sampler
 }
 
 func NewSimulator(w *world.World, tickRate time.Duration) *Simulator {
@@ -23,23 +35,44 @@ func NewSimulator(w *world.World, tickRate time.Duration) *Simulator {
 	if err != nil {
         log.Fatalf("Could not load config: %v", err)
     }
+	return NewSimulatorWithConfig(w, tickRate, cfg)
+}
+
+// NewSimulatorWithConfig builds a simulator from an already loaded config,
+// for callers that do not run from the repository root.
+func NewSimulatorWithConfig(w *world.World, tickRate time.Duration, cfg *config.Config) *Simulator {
 	sm := systems.NewSystemManager()
+	sm.AddSystem(systems.NewClockSystem())
+	sm.AddSystem(systems.NewIncidentSystem())
 	sm.AddSystem(systems.NewSpawnSystem())
 	sm.AddSystem(systems.NewCollisionSystem())
 	sm.AddSystem(systems.NewTrafficLightSystem())
-	if cfg.FeatureFlags.RightOfWaySystem && err	== nil {
+	if cfg.FeatureFlags.RightOfWaySystem {
 		sm.AddSystem(systems.NewRightOfWaySystem())
 	}
+	sm.AddSystem(systems.NewEmergencySystem())
 	sm.AddSystem(systems.NewPathfindingSystem())
 	sm.AddSystem(systems.NewMovementSystem())
+	sm.AddSystem(systems.NewDetectorSystem())
+	sm.AddSystem(systems.NewTripSystem())
 	sm.AddSystem(systems.NewDespawnSystem())
 
+	collector := metrics.NewCollector(cfg.Metrics.Interval)
+	sm.AddSystem(collector)
+	monitor := metrics.NewIntersectionMonitor()
+	sm.AddSystem(monitor)
+	sampler := metrics.NewSampler(metrics.DefaultSamplePeriod, cfg.Charts.Window*60)
+	sm.AddSystem(sampler)
+
 	return &Simulator{
 		world:         w,
 		tickRate:      tickRate,
 		systemManager: sm,
 		accumulator:   0.0,
-		paused: false,
+		speed:         1,
+		metrics:       collector,
+		intersections: monitor,
+		sampler:       sampler,
 	}
 }
 
@@ -56,10 +89,13 @@ func (s *Simulator) Start() {
 }
 
 func (s *Simulator) UpdateOnce(deltaTime float64) {
-	if s.paused {
+	if s.paused.Load() {
 		return
 	}
-	s.accumulator += deltaTime
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+
+	s.accumulator += deltaTime * s.speed
 	fixedDt := s.tickRate.Seconds()
 	
 	for s.accumulator >= fixedDt {
@@ -68,13 +104,104 @@ func (s *Simulator) UpdateOnce(deltaTime float64) {
 	}
 }
 
+// AddSystem appends a system that runs after the built-in ones each tick.
+// It is safe to call while another goroutine is stepping the simulation.
+func (s *Simulator) AddSystem(system systems.System) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	s.systemManager.AddSystem(system)
+}
+
+func (s *Simulator) RemoveSystem(system systems.System) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	s.systemManager.RemoveSystem(system)
+}
+
+// Step advances the simulation by exactly one fixed tick, even when paused.
+func (s *Simulator) Step() {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	s.systemManager.Update(s.world, s.tickRate.Seconds())
+}
+
+func (s *Simulator) World() *world.World {
+	return s.world
+}
+
+func (s *Simulator) Metrics() *metrics.Collector {
+	return s.metrics
+}
+
+func (s *Simulator) Intersections() *metrics.IntersectionMonitor {
+	return s.intersections
+}
+
+func (s *Simulator) Sampler() *metrics.Sampler {
+	return s.sampler
+}
+
+// ExportSystemState collects the internal state of systems for a snapshot.
+func (s *Simulator) ExportSystemState() (map[string]json.RawMessage, error) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	return s.systemManager.ExportState()
+}
+
+// WithSystemState exports the system state and passes it to save with no
+// tick running until save returns, so a snapshot of the world taken in save
+// matches the state.
+func (s *Simulator) WithSystemState(save func(systemState map[string]json.RawMessage) error) error {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+
+	systemState, err := s.systemManager.ExportState()
+	if err != nil {
+		return err
+	}
+	return save(systemState)
+}
+
+func (s *Simulator) ImportSystemState(state map[string]json.RawMessage) error {
+	return s.systemManager.ImportState(state)
+}
+
 func (s *Simulator) update() {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
 	dt := s.tickRate.Seconds()
 	s.systemManager.Update(s.world, dt)
 }
 func (s *Simulator) TogglePause() {
-	s.paused = !s.paused
+	for {
+		paused := s.paused.Load()
+		if s.paused.CompareAndSwap(paused, !paused) {
+			return
+		}
+	}
 }
 func (s *Simulator) IsPaused() bool {
-	return s.paused
+	return s.paused.Load()
+}
+func (s *Simulator) SetPaused(paused bool) {
+	s.paused.Store(paused)
+}
+
+// SetSpeed runs UpdateOnce faster or slower than real time; 2 simulates
+// two seconds per second.
+func (s *Simulator) SetSpeed(speed float64) {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	s.speed = speed
+}
+
+func (s *Simulator) Speed() float64 {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+	return s.speed
+}
+
+// TickRate is the fixed simulated time advanced by each Step.
+func (s *Simulator) TickRate() time.Duration {
+	return s.tickRate
 }
\ No newline at end of file
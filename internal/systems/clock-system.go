@@ -0,0 +1,22 @@
+package systems
+
+import (
+	"traffic-sim/internal/world"
+)
+
+type ClockSystem struct{}
+
+func NewClockSystem() *ClockSystem {
+	return &ClockSystem{}
+}
+
+func (cs *ClockSystem) Reset() {
+	// No state to reset
+}
+
+func (cs *ClockSystem) Update(w *world.World, dt float64) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	w.SimTime += dt
+}
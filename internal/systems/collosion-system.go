@@ -94,6 +94,10 @@ func (cs *CollisionSystem) findNearestVehicleAhead(current *vehicle.Vehicle, veh
 			continue
 		}
 
+		if current.IsEmergency() && v.PullingOver {
+			continue
+		}
+
 		if v.Distance > current.Distance {
 			dist := v.Distance - current.Distance
 			if dist < minDist {
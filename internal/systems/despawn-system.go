@@ -1,6 +1,8 @@
 package systems
 
 import (
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/road"
 	"traffic-sim/internal/vehicle"
 	"traffic-sim/internal/world"
 )
@@ -16,17 +18,43 @@ func (ds *DespawnSystem) Reset() {
 }
 
 func (ds *DespawnSystem) Update(w *world.World, dt float64) {
+	trips, arrivals := ds.removeArrivedVehicles(w)
+
+	if w.Events == nil {
+		return
+	}
+	for _, ev := range trips {
+		w.Events.Emit(events.EventTripCompleted, ev)
+	}
+	for _, ev := range arrivals {
+		w.Events.Emit(events.EventEmergencyArrived, ev)
+	}
+}
+
+func (ds *DespawnSystem) removeArrivedVehicles(w *world.World) ([]events.TripCompletedEvent, []events.EmergencyArrivedEvent) {
 	w.Mu.Lock()
 	defer w.Mu.Unlock()
 
+	var trips []events.TripCompletedEvent
+	var arrivals []events.EmergencyArrivedEvent
+
 	despawnRoads := ds.buildDespawnRoadSet(w)
 
 	toRemove := make(map[int]bool)
 
 	for i, v := range w.Vehicles {
 		if v.Distance >= v.Road.Length {
-			if despawnRoads[v.Road.ID] {
+			if dp := despawnRoads[v.Road.ID]; dp != nil {
 				toRemove[i] = true
+				trips = append(trips, ds.completeTrip(w, v, dp))
+				if v.IsEmergency() {
+					arrivals = append(arrivals, events.EmergencyArrivedEvent{
+						VehicleID:    v.ID,
+						Origin:       v.Origin,
+						Target:       v.TargetDespawn,
+						ResponseTime: w.SimTime - v.SpawnTime,
+					})
+				}
 			}
 		}
 	}
@@ -40,14 +68,36 @@ func (ds *DespawnSystem) Update(w *world.World, dt float64) {
 		}
 		w.Vehicles = newVehicles
 	}
+
+	return trips, arrivals
+}
+
+func (ds *DespawnSystem) completeTrip(w *world.World, v *vehicle.Vehicle, dp *road.DespawnPoint) events.TripCompletedEvent {
+	trip := events.TripCompletedEvent{
+		VehicleID:   v.ID,
+		Emergency:   v.IsEmergency(),
+		Destination: dp.ID,
+		SpawnTime:   v.SpawnTime,
+		ArrivalTime: w.SimTime,
+		TravelTime:  w.SimTime - v.SpawnTime,
+		Route:       v.Trip.Route,
+		Distance:    v.Trip.Distance,
+		Stops:       v.Trip.Stops,
+		StoppedTime: v.Trip.StoppedTime,
+		MaxSpeed:    v.Trip.MaxSpeed,
+	}
+	if v.Origin != nil {
+		trip.Origin = v.Origin.ID
+	}
+	return trip
 }
 
-func (ds *DespawnSystem) buildDespawnRoadSet(w *world.World) map[string]bool {
-	despawnRoads := make(map[string]bool)
+func (ds *DespawnSystem) buildDespawnRoadSet(w *world.World) map[string]*road.DespawnPoint {
+	despawnRoads := make(map[string]*road.DespawnPoint)
 	
 	for _, dp := range w.DespawnPoints {
 		if dp.Enabled {
-			despawnRoads[dp.Road.ID] = true
+			despawnRoads[dp.Road.ID] = dp
 		}
 	}
 	
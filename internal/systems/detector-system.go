@@ -0,0 +1,73 @@
+package systems
+
+import (
+	"slices"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+// DetectorSystem updates the loop detectors on every road. A passage is
+// counted when a vehicle first covers a loop, and its speed at that moment
+// is the spot speed.
+type DetectorSystem struct {
+	vehicleLength float64
+}
+
+func NewDetectorSystem() *DetectorSystem {
+	return &DetectorSystem{
+		vehicleLength: 4.5,
+	}
+}
+
+func (ds *DetectorSystem) Reset() {
+	// No state to reset
+}
+
+func (ds *DetectorSystem) Update(w *world.World, dt float64) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	var onRoad map[*road.Road][]*vehicle.Vehicle
+	for _, rd := range w.Roads {
+		if len(rd.Detectors) == 0 {
+			continue
+		}
+		if onRoad == nil {
+			onRoad = ds.groupByRoad(w.Vehicles)
+		}
+		for _, d := range rd.Detectors {
+			ds.updateDetector(d, onRoad[rd], w.SimTime, dt)
+		}
+	}
+}
+
+func (ds *DetectorSystem) groupByRoad(vehicles []*vehicle.Vehicle) map[*road.Road][]*vehicle.Vehicle {
+	onRoad := make(map[*road.Road][]*vehicle.Vehicle)
+	for _, v := range vehicles {
+		if !v.InTransition {
+			onRoad[v.Road] = append(onRoad[v.Road], v)
+		}
+	}
+	return onRoad
+}
+
+func (ds *DetectorSystem) updateDetector(d *road.Detector, vehicles []*vehicle.Vehicle, simTime, dt float64) {
+	var occupants []string
+	for _, v := range vehicles {
+		if !d.Overlaps(v.Distance-ds.vehicleLength, v.Distance) {
+			continue
+		}
+		occupants = append(occupants, v.ID)
+		if !slices.Contains(d.Occupants, v.ID) {
+			d.Count++
+			d.SpeedSum += v.Speed
+			d.LastPassage = simTime
+		}
+	}
+
+	if len(occupants) > 0 {
+		d.OccupiedTime += dt
+	}
+	d.Occupants = occupants
+}
@@ -0,0 +1,147 @@
+package systems
+
+import (
+	"encoding/json"
+	"sort"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
+	"traffic-sim/internal/world"
+)
+
+type EmergencySystem struct {
+	preemptDistance  float64
+	pullOverDistance float64
+	preempted        map[string]bool
+}
+
+func NewEmergencySystem() *EmergencySystem {
+	return &EmergencySystem{
+		preemptDistance:  120.0,
+		pullOverDistance: 80.0,
+		preempted:        make(map[string]bool),
+	}
+}
+
+func (es *EmergencySystem) Reset() {
+	es.preempted = make(map[string]bool)
+}
+
+func (es *EmergencySystem) StateKey() string {
+	return "emergency"
+}
+
+func (es *EmergencySystem) ExportState() (json.RawMessage, error) {
+	ids := make([]string, 0, len(es.preempted))
+	for id := range es.preempted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return json.Marshal(ids)
+}
+
+func (es *EmergencySystem) ImportState(data json.RawMessage) error {
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return err
+	}
+
+	es.preempted = make(map[string]bool)
+	for _, id := range ids {
+		es.preempted[id] = true
+	}
+	return nil
+}
+
+func (es *EmergencySystem) Update(w *world.World, dt float64) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	for _, v := range w.Vehicles {
+		v.PullingOver = false
+	}
+
+	requests := make(map[string]*road.Road)
+
+	for _, v := range w.Vehicles {
+		if !v.IsEmergency() {
+			continue
+		}
+
+		if v.InTransition {
+			requests[v.Road.To.ID] = v.Road
+			continue
+		}
+
+		distToEnd := v.Road.Length - v.Distance
+		if distToEnd < es.preemptDistance {
+			if _, exists := requests[v.Road.To.ID]; !exists {
+				requests[v.Road.To.ID] = v.Road
+			}
+		}
+
+		es.markVehiclesAhead(w, v)
+	}
+
+	es.applyPreemption(w, requests)
+}
+
+func (es *EmergencySystem) markVehiclesAhead(w *world.World, ev *vehicle.Vehicle) {
+	for _, other := range w.Vehicles {
+		if other == ev || other.IsEmergency() || other.InTransition {
+			continue
+		}
+
+		if other.Road == ev.Road {
+			gap := other.Distance - ev.Distance
+			if gap > 0 && gap < es.pullOverDistance {
+				other.PullingOver = true
+			}
+			continue
+		}
+
+		if ev.NextRoad != nil && other.Road == ev.NextRoad {
+			gap := (ev.Road.Length - ev.Distance) + other.Distance
+			if gap < es.pullOverDistance {
+				other.PullingOver = true
+			}
+		}
+	}
+}
+
+// applyPreemption gives green to the road each emergency vehicle arrives on
+// and red to every other light at that intersection. Lights no longer
+// requested return to their normal cycle.
+func (es *EmergencySystem) applyPreemption(w *world.World, requests map[string]*road.Road) {
+	active := make(map[string]bool)
+
+	for _, light := range w.TrafficLights {
+		approach, requested := requests[light.Intersection.ID]
+		if !requested {
+			continue
+		}
+
+		if es.controlsRoad(light, approach) {
+			light.Preempt(road.LightGreen)
+		} else {
+			light.Preempt(road.LightRed)
+		}
+		active[light.ID] = true
+	}
+
+	for _, light := range w.TrafficLights {
+		if es.preempted[light.ID] && !active[light.ID] {
+			light.ReleasePreemption()
+		}
+	}
+
+	es.preempted = active
+}
+
+func (es *EmergencySystem) controlsRoad(light *road.TrafficLight, rd *road.Road) bool {
+	for _, controlled := range light.ControlledRoads {
+		if controlled == rd {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package systems
+
+import (
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/world"
+)
+
+type IncidentSystem struct{}
+
+func NewIncidentSystem() *IncidentSystem {
+	return &IncidentSystem{}
+}
+
+func (is *IncidentSystem) Reset() {
+	// No state to reset
+}
+
+func (is *IncidentSystem) Update(w *world.World, dt float64) {
+	started, ended := is.updateActiveFlags(w)
+
+	if w.Events == nil {
+		return
+	}
+	for _, ev := range started {
+		w.Events.Emit(events.EventIncidentStarted, ev)
+	}
+	for _, ev := range ended {
+		w.Events.Emit(events.EventIncidentEnded, ev)
+	}
+}
+
+func (is *IncidentSystem) updateActiveFlags(w *world.World) ([]events.IncidentEvent, []events.IncidentEvent) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	var started, ended []events.IncidentEvent
+
+	for _, inc := range w.Incidents {
+		active := inc.IsActiveAt(w.SimTime)
+		if active == inc.Active {
+			continue
+		}
+
+		inc.Active = active
+		if active {
+			started = append(started, events.IncidentEvent{Incident: inc})
+		} else {
+			ended = append(ended, events.IncidentEvent{Incident: inc})
+		}
+	}
+
+	return started, ended
+}
@@ -13,6 +13,12 @@ type MovementSystem struct {
 	acceleration  float64
 	minSpeed      float64
 	jerkLimit     float64
+
+	emergencySpeedFactor float64
+	pullOverSpeedFactor  float64
+	pullOverOffset       float64
+	pullOverTime         float64
+	closureStopMargin    float64
 }
 
 func NewMovementSystem() *MovementSystem {
@@ -22,6 +28,12 @@ func NewMovementSystem() *MovementSystem {
 		acceleration:  10.0,
 		minSpeed:      5.0,
 		jerkLimit:     25.0,
+
+		emergencySpeedFactor: 1.3,
+		pullOverSpeedFactor:  0.3,
+		pullOverOffset:       0.35,
+		pullOverTime:         1.5,
+		closureStopMargin:    5.0,
 	}
 }
 
@@ -38,9 +50,16 @@ func (ms *MovementSystem) Update(w *world.World, dt float64) {
 			continue
 		}
 		
-		targetSpeed := ms.calculateTargetSpeed(w, v)
+		limit := ms.speedLimitFor(w, v)
+		targetSpeed := ms.calculateTargetSpeed(w, v, limit)
+		if v.IsEmergency() {
+			targetSpeed *= ms.emergencySpeedFactor
+			limit *= ms.emergencySpeedFactor
+		} else if v.PullingOver {
+			targetSpeed = math.Min(targetSpeed, limit*ms.pullOverSpeedFactor)
+		}
 		
-		ms.adjustSpeedSmooth(v, targetSpeed, dt)
+		ms.adjustSpeedSmooth(v, targetSpeed, limit, dt)
 		
 		newDist := v.Distance + v.Speed*dt
 		
@@ -50,17 +69,110 @@ func (ms *MovementSystem) Update(w *world.World, dt float64) {
 		
 		v.Distance = newDist
 
+		ms.easePullOver(v, dt)
 		x, y := v.Road.PosAt(v.Distance)
+		if v.PullOver > 0 {
+			x, y = ms.applyPullOverOffset(v.Road, v.Distance, v.PullOver, x, y)
+		}
 		v.Pos.X = x
 		v.Pos.Y = y
 	}
 }
 
-func (ms *MovementSystem) calculateTargetSpeed(w *world.World, v *vehicle.Vehicle) float64 {
+// easePullOver moves the vehicle towards or away from the road edge over
+// pullOverTime, rather than jumping when PullingOver changes.
+func (ms *MovementSystem) easePullOver(v *vehicle.Vehicle, dt float64) {
+	step := dt / ms.pullOverTime
+	if v.PullingOver {
+		v.PullOver = math.Min(1, v.PullOver+step)
+	} else {
+		v.PullOver = math.Max(0, v.PullOver-step)
+	}
+}
+
+// applyPullOverOffset shifts the position at dist towards the right edge of
+// the road, by share of the full offset. The offset is taken across the
+// road's local direction, so it follows curves.
+func (ms *MovementSystem) applyPullOverOffset(rd *road.Road, dist, share, x, y float64) (float64, float64) {
+	const eps = 0.5
+	ax, ay := rd.PosAt(math.Max(dist-eps, 0))
+	bx, by := rd.PosAt(math.Min(dist+eps, rd.Length))
+	dx := bx - ax
+	dy := by - ay
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return x, y
+	}
+
+	offset := rd.Width * ms.pullOverOffset * share
+	return x - dy/length*offset, y + dx/length*offset
+}
+
+// speedLimitFor returns the limit at the vehicle's position, lowered so the
+// vehicle can brake in time for any stricter zone ahead, on this road or at
+// the start of the next. Zones are looked for over the braking distance at
+// the current speed; one further away cannot lower the limit below it.
+func (ms *MovementSystem) speedLimitFor(w *world.World, v *vehicle.Vehicle) float64 {
+	limit := v.Road.SpeedLimitAt(v.Distance, w.SimTime)
+	lookAhead := ms.lookAheadDist + v.Speed*v.Speed/(2*ms.deceleration)
+
+	for _, z := range v.Road.SpeedZones {
+		if distToZone := z.Start - v.Distance; distToZone > 0 {
+			limit = ms.approachZone(limit, z, distToZone, lookAhead, w.SimTime)
+		}
+	}
+	if v.NextRoad != nil {
+		distToEnd := v.Road.Length - v.Distance
+		for _, z := range v.NextRoad.SpeedZones {
+			limit = ms.approachZone(limit, z, distToEnd+z.Start, lookAhead, w.SimTime)
+		}
+	}
+
+	for _, inc := range w.ActiveIncidentsOn(v.Road) {
+		limit = math.Min(limit, ms.incidentLimit(v, inc))
+	}
+
+	return limit
+}
+
+// approachZone lowers limit to the speed from which the vehicle can still
+// brake to the zone's limit over distToZone.
+func (ms *MovementSystem) approachZone(limit float64, z *road.SpeedZone, distToZone, lookAhead, simTime float64) float64 {
+	if !z.IsActive(simTime) || z.Limit >= limit || distToZone > lookAhead {
+		return limit
+	}
+	brakingSpeed := math.Sqrt(z.Limit*z.Limit + 2*ms.deceleration*distToZone)
+	return math.Min(limit, brakingSpeed)
+}
+
+// incidentLimit brings vehicles to a stop before a closure and slows them
+// through a slowdown. Vehicles already inside a closure are let out.
+func (ms *MovementSystem) incidentLimit(v *vehicle.Vehicle, inc *road.Incident) float64 {
+	distToIncident := inc.Start - v.Distance
+
+	if inc.IsClosure() {
+		if distToIncident <= 0 {
+			return math.Inf(1)
+		}
+		stopDist := math.Max(0, distToIncident-ms.closureStopMargin)
+		return math.Sqrt(2 * ms.deceleration * stopDist)
+	}
+
+	reduced := v.Road.MaxSpeed * inc.SpeedFactor
+	if inc.Covers(v.Distance) {
+		return reduced
+	}
+	if distToIncident > 0 {
+		return math.Sqrt(reduced*reduced + 2*ms.deceleration*distToIncident)
+	}
+	return math.Inf(1)
+}
+
+func (ms *MovementSystem) calculateTargetSpeed(w *world.World, v *vehicle.Vehicle, limit float64) float64 {
 	distToEnd := v.Road.Length - v.Distance
 	
 	if ms.hasDespawnPoint(w, v.Road) {
-		return v.Road.MaxSpeed
+		return limit
 	}
 	
 	hasValidExit := ms.hasValidExit(w, v)
@@ -70,10 +182,10 @@ func (ms *MovementSystem) calculateTargetSpeed(w *world.World, v *vehicle.Vehicl
 			turnSharpness := ms.calculateTurnSharpness(v.Road, v.NextRoad)
 			if turnSharpness > 0.5 {
 				slowdownFactor := 1.0 - (turnSharpness * 0.4)
-				return v.Road.MaxSpeed * slowdownFactor
+				return limit * slowdownFactor
 			}
 		}
-		return v.Road.MaxSpeed
+		return limit
 	}
 	
 	effectiveLookAhead := math.Min(ms.lookAheadDist, v.Road.Length*0.4)
@@ -82,10 +194,10 @@ func (ms *MovementSystem) calculateTargetSpeed(w *world.World, v *vehicle.Vehicl
 	}
 	
 	if distToEnd > effectiveLookAhead {
-		return v.Road.MaxSpeed
+		return limit
 	}
 	
-	return ms.calculateApproachSpeed(distToEnd, v.Road.MaxSpeed, effectiveLookAhead)
+	return ms.calculateApproachSpeed(distToEnd, limit, effectiveLookAhead)
 }
 
 func (ms *MovementSystem) calculateTurnSharpness(fromRoad, toRoad *road.Road) float64 {
@@ -152,7 +264,11 @@ func (ms *MovementSystem) hasDespawnPoint(w *world.World, rd *road.Road) bool {
 	return false
 }
 
-func (ms *MovementSystem) adjustSpeedSmooth(v *vehicle.Vehicle, targetSpeed, dt float64) {
+// adjustSpeedSmooth moves the vehicle's speed towards targetSpeed, never
+// above limit. A limit that drops under a moving vehicle, e.g. when a
+// scheduled zone switches on, is braked down to rather than jumped to.
+func (ms *MovementSystem) adjustSpeedSmooth(v *vehicle.Vehicle, targetSpeed, limit, dt float64) {
+	targetSpeed = math.Min(targetSpeed, limit)
 	speedDiff := targetSpeed - v.Speed
 	
 	if math.Abs(speedDiff) < 0.1 {
@@ -167,9 +283,11 @@ func (ms *MovementSystem) adjustSpeedSmooth(v *vehicle.Vehicle, targetSpeed, dt
 			maxAccel *= 1.5
 		}
 		
-		speedRatio := v.Speed / v.Road.MaxSpeed
-		accelModifier := 1.0 - 0.3*speedRatio
-		maxAccel *= accelModifier
+		if limit > 0 {
+			speedRatio := v.Speed / limit
+			accelModifier := 1.0 - 0.3*speedRatio
+			maxAccel *= accelModifier
+		}
 		
 		change := math.Min(speedDiff, maxAccel*dt)
 		change = math.Min(change, ms.jerkLimit*dt)
@@ -179,7 +297,7 @@ func (ms *MovementSystem) adjustSpeedSmooth(v *vehicle.Vehicle, targetSpeed, dt
 	} else {
 		maxDecel := ms.deceleration
 		
-		urgency := math.Abs(speedDiff) / v.Road.MaxSpeed
+		urgency := math.Abs(speedDiff) / math.Max(limit, ms.minSpeed)
 		if urgency > 0.5 {
 			maxDecel *= (1.0 + urgency)
 		}
@@ -197,7 +315,4 @@ func (ms *MovementSystem) adjustSpeedSmooth(v *vehicle.Vehicle, targetSpeed, dt
 	if v.Speed < 0 {
 		v.Speed = 0
 	}
-	if v.Speed > v.Road.MaxSpeed {
-		v.Speed = v.Road.MaxSpeed
-	}
 }
\ No newline at end of file
@@ -3,7 +3,6 @@ package systems
 import (
 	"container/heap"
 	"math"
-	"math/rand"
 	"traffic-sim/internal/geom"
 	"traffic-sim/internal/road"
 	"traffic-sim/internal/vehicle"
@@ -81,6 +80,10 @@ func (ps *PathfindingSystem) Update(w *world.World, dt float64) {
 		if v.TargetDespawn == nil {
 			ps.assignTarget(v, w)
 		}
+
+		if v.NextRoad != nil && w.IsRoadClosed(v.NextRoad) {
+			v.NextRoad = nil
+		}
 		
 		if v.NextRoad == nil {
 			threshold := v.Road.Length * 0.5
@@ -133,7 +136,7 @@ func (ps *PathfindingSystem) assignTarget(v *vehicle.Vehicle, w *world.World) {
 		return
 	}
 	
-	v.TargetDespawn = activeDespawns[rand.Intn(len(activeDespawns))]
+	v.TargetDespawn = activeDespawns[w.Rand.IntN(len(activeDespawns))]
 }
 
 func (ps *PathfindingSystem) findNextRoadToTarget(v *vehicle.Vehicle, w *world.World) *road.Road {
@@ -146,7 +149,7 @@ func (ps *PathfindingSystem) findNextRoadToTarget(v *vehicle.Vehicle, w *world.W
 	
 	if currentNodeID == targetNodeID {
 		for _, rd := range w.IntersectionsByNode[currentNodeID].Outgoing {
-			if rd.ID == v.TargetDespawn.Road.ID {
+			if rd.ID == v.TargetDespawn.Road.ID && !w.IsRoadClosed(rd) {
 				return v.TargetDespawn.Road
 			}
 		}
@@ -166,7 +169,7 @@ func (ps *PathfindingSystem) findNextRoadToTarget(v *vehicle.Vehicle, w *world.W
 	}
 	
 	for _, rd := range intersection.Outgoing {
-		if notSameRoad(rd, v.Road) && rd.To.ID == nextNodeID {
+		if notSameRoad(rd, v.Road) && rd.To.ID == nextNodeID && !w.IsRoadClosed(rd) {
 			return rd
 		}
 	}
@@ -210,6 +213,10 @@ func (ps *PathfindingSystem) findShortestPathDijkstra(startNodeID, targetNodeID
 		}
 		
 		for _, rd := range intersection.Outgoing {
+			if w.IsRoadClosed(rd) {
+				continue
+			}
+
 			neighbor := rd.To.ID
 			
 			if visited[neighbor] {
@@ -249,7 +256,7 @@ func (ps *PathfindingSystem) findNextRoadRandom(v *vehicle.Vehicle, w *world.Wor
 
 	available := make([]*road.Road, 0, len(intersection.Outgoing))
 	for _, r := range intersection.Outgoing {
-		if notSameRoad(r, v.Road) {
+		if notSameRoad(r, v.Road) && !w.IsRoadClosed(r) {
 			available = append(available, r)
 		}
 	}
@@ -258,13 +265,19 @@ func (ps *PathfindingSystem) findNextRoadRandom(v *vehicle.Vehicle, w *world.Wor
 		return nil
 	}
 
-	return available[rand.Intn(len(available))]
+	return available[w.Rand.IntN(len(available))]
 }
 
 func (ps *PathfindingSystem) startTransition(v *vehicle.Vehicle) {
-	fromRoad := v.Road
-	toRoad := v.NextRoad
-	
+	v.TransitionCurve = TransitionCurve(v.Road, v.NextRoad)
+	v.InTransition = true
+	v.TransitionT = 0
+	v.TransitionSpeed = v.Speed
+}
+
+// TransitionCurve is the path a vehicle follows through the intersection
+// from fromRoad onto toRoad.
+func TransitionCurve(fromRoad, toRoad *road.Road) *geom.BezierCurve {
 	startDist := 20.0
 	if toRoad.Length < 40.0 {
 		startDist = toRoad.Length * 0.3
@@ -307,10 +320,7 @@ func (ps *PathfindingSystem) startTransition(v *vehicle.Vehicle) {
 		Y: p3.Y - dirOut.Y*controlDist,
 	}
 	
-	v.TransitionCurve = geom.NewCubicBezier(p0, p1, p2, p3)
-	v.InTransition = true
-	v.TransitionT = 0
-	v.TransitionSpeed = v.Speed
+	return geom.NewCubicBezier(p0, p1, p2, p3)
 }
 
 func (ps *PathfindingSystem) updateTransition(v *vehicle.Vehicle, dt float64) {
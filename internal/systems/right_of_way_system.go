@@ -1,6 +1,7 @@
 package systems
 
 import (
+	"encoding/json"
 	"math"
 	"traffic-sim/internal/road"
 	"traffic-sim/internal/vehicle"
@@ -34,6 +35,40 @@ func (rows *RightOfWaySystem) Reset() {
 	rows.waitingVehicles = make(map[string]float64)
 }
 
+type rightOfWayState struct {
+	ArrivalTimes map[string]map[string]float64 `json:"arrivalTimes"`
+	Waiting      map[string]float64            `json:"waiting"`
+}
+
+func (rows *RightOfWaySystem) StateKey() string {
+	return "rightOfWay"
+}
+
+// ExportState saves arrival and waiting times. Rules are rebuilt from the
+// network on the next tick so they are not included.
+func (rows *RightOfWaySystem) ExportState() (json.RawMessage, error) {
+	return json.Marshal(rightOfWayState{
+		ArrivalTimes: rows.vehicleArrivalTimes,
+		Waiting:      rows.waitingVehicles,
+	})
+}
+
+func (rows *RightOfWaySystem) ImportState(data json.RawMessage) error {
+	var state rightOfWayState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	rows.Reset()
+	for id, times := range state.ArrivalTimes {
+		rows.vehicleArrivalTimes[id] = times
+	}
+	for id, wait := range state.Waiting {
+		rows.waitingVehicles[id] = wait
+	}
+	return nil
+}
+
 func (rows *RightOfWaySystem) Update(w *world.World, dt float64) {
 	w.Mu.Lock()
 	defer w.Mu.Unlock()
@@ -140,7 +175,7 @@ func (rows *RightOfWaySystem) updateVehicleArrivalTimes(w *world.World, dt float
 
 func (rows *RightOfWaySystem) applyRightOfWayRules(w *world.World, dt float64) {
 	for _, v := range w.Vehicles {
-		if v.NextRoad == nil {
+		if v.NextRoad == nil || v.IsEmergency() {
 			continue
 		}
 
@@ -189,6 +224,10 @@ func (rows *RightOfWaySystem) shouldVehicleYield(w *world.World, v *vehicle.Vehi
 	}
 
 	for _, conflicting := range conflictingVehicles {
+		if conflicting.IsEmergency() {
+			return true
+		}
+
 		vIsTurning := !road.IsMinorDirectionChange(v.Road, v.NextRoad)
 		conflictingIsTurning := !road.IsMinorDirectionChange(conflicting.Road, conflicting.NextRoad)
 		
@@ -2,7 +2,6 @@ package systems
 
 import (
 	"fmt"
-	"math/rand"
 	"traffic-sim/internal/road"
 	"traffic-sim/internal/vehicle"
 	"traffic-sim/internal/world"
@@ -32,28 +31,31 @@ func (ss *SpawnSystem) Update(w *world.World, dt float64) {
 
 		sp.Timer += dt
 
-		randomInterval := sp.Interval * (0.5 + rand.Float64())
+		randomInterval := sp.Interval * (0.5 + w.Rand.Float64())
 		
 		if sp.Timer >= randomInterval {
 			sp.Timer = 0.0
 
-			speed := sp.MinSpeed + rand.Float64()*(sp.MaxSpeed-sp.MinSpeed)
+			speed := sp.MinSpeed + w.Rand.Float64()*(sp.MaxSpeed-sp.MinSpeed)
 
 			sp.VehicleCounter++
 			vehicleID := fmt.Sprintf("%s-v%d", sp.ID, sp.VehicleCounter)
 
-			ss.spawnVehicle(w, vehicleID, sp.Road, speed)
+			ss.spawnVehicle(w, vehicleID, sp, speed)
 		}
 	}
 }
 
-func (ss *SpawnSystem) spawnVehicle(w *world.World, id string, rd *road.Road, speed float64) {
+func (ss *SpawnSystem) spawnVehicle(w *world.World, id string, sp *road.SpawnPoint, speed float64) {
+	rd := sp.Road
 	newVehicle := &vehicle.Vehicle{
-		ID:       id,
-		Road:     rd,
-		Distance: 0,
-		Speed:    speed,
-		Pos:      vehicle.Vec2{X: rd.From.X, Y: rd.From.Y},
+		ID:        id,
+		Road:      rd,
+		Distance:  0,
+		Speed:     speed,
+		Pos:       vehicle.Vec2{X: rd.From.X, Y: rd.From.Y},
+		Origin:    sp,
+		SpawnTime: w.SimTime,
 	}
 	
 	ss.assignTargetDespawn(newVehicle, w)
@@ -73,5 +75,5 @@ func (ss *SpawnSystem) assignTargetDespawn(v *vehicle.Vehicle, w *world.World) {
 		return
 	}
 	
-	v.TargetDespawn = activeDespawns[rand.Intn(len(activeDespawns))]
+	v.TargetDespawn = activeDespawns[w.Rand.IntN(len(activeDespawns))]
 }
\ No newline at end of file
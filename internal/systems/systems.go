@@ -1,6 +1,8 @@
 package systems
 
 import (
+	"encoding/json"
+	"fmt"
 	"traffic-sim/internal/world"
 )
 
@@ -9,6 +11,15 @@ type System interface {
 	Reset() 
 }
 
+// StatefulSystem is implemented by systems that keep state between ticks
+// which a snapshot has to carry for the run to resume exactly.
+type StatefulSystem interface {
+	System
+	StateKey() string
+	ExportState() (json.RawMessage, error)
+	ImportState(data json.RawMessage) error
+}
+
 type SystemManager struct {
 	systems []System
 }
@@ -23,6 +34,15 @@ func (sm *SystemManager) AddSystem(s System) {
 	sm.systems = append(sm.systems, s)
 }
 
+func (sm *SystemManager) RemoveSystem(s System) {
+	for i, system := range sm.systems {
+		if system == s {
+			sm.systems = append(sm.systems[:i], sm.systems[i+1:]...)
+			return
+		}
+	}
+}
+
 func (sm *SystemManager) Update(w *world.World, dt float64) {
 	for _, system := range sm.systems {
 		system.Update(w, dt)
@@ -36,3 +56,37 @@ func (sm *SystemManager) ResetAll() {
 	}
 }
 
+func (sm *SystemManager) ExportState() (map[string]json.RawMessage, error) {
+	state := make(map[string]json.RawMessage)
+	for _, system := range sm.systems {
+		stateful, ok := system.(StatefulSystem)
+		if !ok {
+			continue
+		}
+		data, err := stateful.ExportState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s state: %w", stateful.StateKey(), err)
+		}
+		state[stateful.StateKey()] = data
+	}
+	return state, nil
+}
+
+// ImportState restores systems from an ExportState result. Systems missing
+// from the map keep their current state.
+func (sm *SystemManager) ImportState(state map[string]json.RawMessage) error {
+	for _, system := range sm.systems {
+		stateful, ok := system.(StatefulSystem)
+		if !ok {
+			continue
+		}
+		data, exists := state[stateful.StateKey()]
+		if !exists {
+			continue
+		}
+		if err := stateful.ImportState(data); err != nil {
+			return fmt.Errorf("failed to import %s state: %w", stateful.StateKey(), err)
+		}
+	}
+	return nil
+}
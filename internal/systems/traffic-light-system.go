@@ -2,6 +2,7 @@ package systems
 
 import (
 	"traffic-sim/internal/road"
+	"traffic-sim/internal/vehicle"
 	"traffic-sim/internal/world"
 )
 
@@ -42,6 +43,10 @@ func (tls *TrafficLightSystem) enforceTrafficRules(w *world.World) {
 			continue
 		}
 
+		if v.IsEmergency() && tls.isIntersectionClear(w, v) {
+			continue
+		}
+
 		distToEnd := v.Road.Length - v.Distance
 
 		stopDist := 30.0
@@ -59,6 +64,20 @@ func (tls *TrafficLightSystem) enforceTrafficRules(w *world.World) {
 	}
 }
 
+// isIntersectionClear reports whether no other vehicle is currently crossing
+// the node at the end of the vehicle's road.
+func (tls *TrafficLightSystem) isIntersectionClear(w *world.World, v *vehicle.Vehicle) bool {
+	for _, other := range w.Vehicles {
+		if other == v || !other.InTransition {
+			continue
+		}
+		if other.Road.To == v.Road.To {
+			return false
+		}
+	}
+	return true
+}
+
 func (tls *TrafficLightSystem) buildLightsByRoadMap(w *world.World) map[string]*road.TrafficLight {
 	lightsByRoad := make(map[string]*road.TrafficLight)
 
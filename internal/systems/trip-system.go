@@ -0,0 +1,26 @@
+package systems
+
+import (
+	"traffic-sim/internal/world"
+)
+
+// TripSystem keeps each vehicle's trip statistics up to date so they can be
+// reported when the vehicle despawns.
+type TripSystem struct{}
+
+func NewTripSystem() *TripSystem {
+	return &TripSystem{}
+}
+
+func (ts *TripSystem) Reset() {
+	// No state to reset
+}
+
+func (ts *TripSystem) Update(w *world.World, dt float64) {
+	w.Mu.Lock()
+	defer w.Mu.Unlock()
+
+	for _, v := range w.Vehicles {
+		v.Trip.Record(v.Road.ID, v.Speed, dt)
+	}
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/query"
+	"traffic-sim/internal/road"
+)
+
+type DetectorTool struct {
+	executor        *commands.CommandExecutor
+	query           *query.WorldQuery
+	maxSnapDist     float64
+	detectorCounter int
+}
+
+func NewDetectorTool(executor *commands.CommandExecutor, query *query.WorldQuery) *DetectorTool {
+	return &DetectorTool{
+		executor:    executor,
+		query:       query,
+		maxSnapDist: 15.0,
+	}
+}
+
+func (t *DetectorTool) GetHoverRoad(mouseX, mouseY float64) (*road.Road, float64) {
+	rd, snapX, snapY := t.query.FindNearestRoad(mouseX, mouseY, t.maxSnapDist)
+	if rd == nil {
+		return nil, 0
+	}
+
+	straight := math.Hypot(rd.To.X-rd.From.X, rd.To.Y-rd.From.Y)
+	if straight == 0 {
+		return rd, 0
+	}
+	along := math.Hypot(snapX-rd.From.X, snapY-rd.From.Y) / straight * rd.Length
+	return rd, math.Min(math.Max(along, 0), rd.Length)
+}
+
+// Click places a detector on the road under the cursor, with its loop
+// starting at the clicked point.
+func (t *DetectorTool) Click(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	position := math.Min(dist, math.Max(rd.Length-road.DefaultDetectorLength, 0))
+	cmd := &commands.AddDetectorCommand{
+		Road:     rd,
+		Detector: road.NewDetector(t.nextDetectorID(), position),
+	}
+	return t.executor.Execute(cmd)
+}
+
+// RemoveAt deletes the detector under the cursor, if any.
+func (t *DetectorTool) RemoveAt(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	if d := t.query.FindDetectorAt(rd, dist, 3.0); d != nil {
+		return t.executor.Execute(&commands.RemoveDetectorCommand{DetectorID: d.ID})
+	}
+
+	return nil
+}
+
+func (t *DetectorTool) Cancel() {
+	// Placing a detector is a single click
+}
+
+func (t *DetectorTool) nextDetectorID() string {
+	for {
+		t.detectorCounter++
+		id := fmt.Sprintf("det%d", t.detectorCounter)
+		if t.query.FindDetector(id) == nil {
+			return id
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/query"
+	"traffic-sim/internal/road"
+)
+
+type EmergencyTool struct {
+	executor      *commands.CommandExecutor
+	query         *query.WorldQuery
+	selectedSpawn *road.SpawnPoint
+	maxSnapDist   float64
+}
+
+func NewEmergencyTool(executor *commands.CommandExecutor, query *query.WorldQuery) *EmergencyTool {
+	return &EmergencyTool{
+		executor:    executor,
+		query:       query,
+		maxSnapDist: 15.0,
+	}
+}
+
+func (t *EmergencyTool) GetHoverSpawnPoint(mouseX, mouseY float64) *road.SpawnPoint {
+	sp, _, _ := t.query.FindNearestSpawnPoint(mouseX, mouseY, t.maxSnapDist)
+	return sp
+}
+
+func (t *EmergencyTool) GetHoverDespawnPoint(mouseX, mouseY float64) *road.DespawnPoint {
+	dp, _, _ := t.query.FindNearestDespawnPoint(mouseX, mouseY, t.maxSnapDist)
+	return dp
+}
+
+func (t *EmergencyTool) GetSelectedSpawnPoint() *road.SpawnPoint {
+	return t.selectedSpawn
+}
+
+func (t *EmergencyTool) Click(mouseX, mouseY float64) error {
+	if t.selectedSpawn == nil {
+		t.selectedSpawn = t.GetHoverSpawnPoint(mouseX, mouseY)
+		return nil
+	}
+
+	target := t.GetHoverDespawnPoint(mouseX, mouseY)
+	if target == nil {
+		return nil
+	}
+
+	cmd := &commands.SpawnEmergencyVehicleCommand{
+		SpawnPoint: t.selectedSpawn,
+		Target:     target,
+	}
+
+	if err := t.executor.Execute(cmd); err != nil {
+		return err
+	}
+
+	t.Cancel()
+	return nil
+}
+
+func (t *EmergencyTool) Cancel() {
+	t.selectedSpawn = nil
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/query"
+	"traffic-sim/internal/road"
+)
+
+type IncidentTool struct {
+	executor        *commands.CommandExecutor
+	query           *query.WorldQuery
+	maxSnapDist     float64
+	selectedRoad    *road.Road
+	startDist       float64
+	kind            road.IncidentKind
+	duration        float64
+	incidentCounter int
+}
+
+func NewIncidentTool(executor *commands.CommandExecutor, query *query.WorldQuery) *IncidentTool {
+	return &IncidentTool{
+		executor:    executor,
+		query:       query,
+		maxSnapDist: 15.0,
+		kind:        road.IncidentClosure,
+	}
+}
+
+func (t *IncidentTool) GetHoverRoad(mouseX, mouseY float64) (*road.Road, float64) {
+	rd, snapX, snapY := t.query.FindNearestRoad(mouseX, mouseY, t.maxSnapDist)
+	if rd == nil {
+		return nil, 0
+	}
+
+	straight := math.Hypot(rd.To.X-rd.From.X, rd.To.Y-rd.From.Y)
+	if straight == 0 {
+		return rd, 0
+	}
+	along := math.Hypot(snapX-rd.From.X, snapY-rd.From.Y) / straight * rd.Length
+	return rd, math.Min(math.Max(along, 0), rd.Length)
+}
+
+func (t *IncidentTool) GetSelectedRoad() *road.Road {
+	return t.selectedRoad
+}
+
+func (t *IncidentTool) GetStartDistance() float64 {
+	return t.startDist
+}
+
+func (t *IncidentTool) Kind() road.IncidentKind {
+	return t.kind
+}
+
+// Duration is the incident length in sim seconds; zero means until cleared.
+func (t *IncidentTool) Duration() float64 {
+	return t.duration
+}
+
+func (t *IncidentTool) CycleKind() {
+	if t.kind == road.IncidentClosure {
+		t.kind = road.IncidentSlowdown
+	} else {
+		t.kind = road.IncidentClosure
+	}
+}
+
+func (t *IncidentTool) AdjustDuration(delta float64) {
+	t.duration = math.Max(0, t.duration+delta)
+}
+
+// Click picks the start of the affected stretch, then its end. Clicking the
+// start point again covers the rest of the road.
+func (t *IncidentTool) Click(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	if t.selectedRoad == nil || t.selectedRoad != rd {
+		t.selectedRoad = rd
+		t.startDist = dist
+		return nil
+	}
+
+	start, end := t.startDist, dist
+	if math.Abs(end-start) < 1.0 {
+		end = 0
+	} else if end < start {
+		start, end = end, start
+	}
+
+	t.incidentCounter++
+	inc := road.NewIncident(fmt.Sprintf("inc%d", t.incidentCounter), t.kind, rd)
+	inc.Start = start
+	inc.End = end
+	inc.From = t.query.SimTime()
+	if t.duration > 0 {
+		inc.Until = inc.From + t.duration
+	}
+
+	if err := t.executor.Execute(&commands.CreateIncidentCommand{Incident: inc}); err != nil {
+		return err
+	}
+
+	t.Cancel()
+	return nil
+}
+
+// ClearAt removes the incident under the cursor, reopening the road.
+func (t *IncidentTool) ClearAt(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	inc := t.query.FindIncidentAt(rd, dist)
+	if inc == nil {
+		return nil
+	}
+
+	return t.executor.Execute(&commands.ClearIncidentCommand{IncidentID: inc.ID})
+}
+
+func (t *IncidentTool) Cancel() {
+	t.selectedRoad = nil
+	t.startDist = 0
+}
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/query"
+	"traffic-sim/internal/road"
+)
+
+type SpeedZoneTool struct {
+	executor     *commands.CommandExecutor
+	query        *query.WorldQuery
+	maxSnapDist  float64
+	selectedRoad *road.Road
+	startDist    float64
+	limit        float64
+	zoneCounter  int
+}
+
+func NewSpeedZoneTool(executor *commands.CommandExecutor, query *query.WorldQuery) *SpeedZoneTool {
+	return &SpeedZoneTool{
+		executor:    executor,
+		query:       query,
+		maxSnapDist: 15.0,
+		limit:       20.0,
+	}
+}
+
+func (t *SpeedZoneTool) GetHoverRoad(mouseX, mouseY float64) (*road.Road, float64) {
+	rd, snapX, snapY := t.query.FindNearestRoad(mouseX, mouseY, t.maxSnapDist)
+	if rd == nil {
+		return nil, 0
+	}
+	return rd, t.distanceAlong(rd, snapX, snapY)
+}
+
+func (t *SpeedZoneTool) GetSelectedRoad() *road.Road {
+	return t.selectedRoad
+}
+
+func (t *SpeedZoneTool) GetStartDistance() float64 {
+	return t.startDist
+}
+
+func (t *SpeedZoneTool) Limit() float64 {
+	return t.limit
+}
+
+func (t *SpeedZoneTool) AdjustLimit(delta float64) {
+	t.limit = math.Max(5.0, t.limit+delta)
+}
+
+func (t *SpeedZoneTool) Click(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	if t.selectedRoad == nil || t.selectedRoad != rd {
+		t.selectedRoad = rd
+		t.startDist = dist
+		return nil
+	}
+
+	if math.Abs(dist-t.startDist) < 1.0 {
+		return nil
+	}
+
+	cmd := &commands.AddSpeedZoneCommand{
+		Road: rd,
+		Zone: road.NewSpeedZone(t.nextZoneID(rd), t.startDist, dist, t.limit),
+	}
+
+	if err := t.executor.Execute(cmd); err != nil {
+		return err
+	}
+
+	t.Cancel()
+	return nil
+}
+
+// RemoveAt deletes the zone under the cursor, if any.
+func (t *SpeedZoneTool) RemoveAt(mouseX, mouseY float64) error {
+	rd, dist := t.GetHoverRoad(mouseX, mouseY)
+	if rd == nil {
+		return nil
+	}
+
+	for _, z := range rd.SpeedZones {
+		if z.Contains(dist) {
+			return t.executor.Execute(&commands.RemoveSpeedZoneCommand{Road: rd, ZoneID: z.ID})
+		}
+	}
+
+	return nil
+}
+
+func (t *SpeedZoneTool) Cancel() {
+	t.selectedRoad = nil
+	t.startDist = 0
+}
+
+func (t *SpeedZoneTool) nextZoneID(rd *road.Road) string {
+	for {
+		t.zoneCounter++
+		id := fmt.Sprintf("sz%d", t.zoneCounter)
+		if rd.FindSpeedZone(id) == nil {
+			return id
+		}
+	}
+}
+
+func (t *SpeedZoneTool) distanceAlong(rd *road.Road, x, y float64) float64 {
+	straight := math.Hypot(rd.To.X-rd.From.X, rd.To.Y-rd.From.Y)
+	if straight == 0 {
+		return 0
+	}
+
+	along := math.Hypot(x-rd.From.X, y-rd.From.Y) / straight * rd.Length
+	return math.Min(math.Max(along, 0), rd.Length)
+}
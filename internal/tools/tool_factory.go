@@ -16,6 +16,10 @@ type ToolSet struct {
 	RoadProperties     *RoadPropertiesTool
 	SpawnPointProperties *SpawnPointPropertiesTool
 	RoadCurving        *RoadCurveTool
+	Emergency          *EmergencyTool
+	SpeedZone          *SpeedZoneTool
+	Incident           *IncidentTool
+	Detector           *DetectorTool
 }
 
 type ToolFactory struct {
@@ -42,5 +46,9 @@ func (tf *ToolFactory) CreateAll() *ToolSet {
 		RoadProperties:      NewRoadPropertiesTool(tf.executor, tf.query),
 		SpawnPointProperties: NewSpawnPointPropertiesTool(tf.executor, tf.query),
 		RoadCurving:         NewRoadCurveTool(tf.executor, tf.query),
+		Emergency:           NewEmergencyTool(tf.executor, tf.query),
+		SpeedZone:           NewSpeedZoneTool(tf.executor, tf.query),
+		Incident:            NewIncidentTool(tf.executor, tf.query),
+		Detector:            NewDetectorTool(tf.executor, tf.query),
 	}
 }
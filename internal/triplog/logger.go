@@ -0,0 +1,124 @@
+package triplog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"traffic-sim/internal/events"
+)
+
+var csvHeader = []string{
+	"vehicle_id", "emergency", "origin", "destination", "spawn_time", "arrival_time",
+	"travel_time", "distance", "stops", "stopped_time", "max_speed", "route",
+}
+
+// Logger appends completed trips to a file, one per line. Files ending in
+// .csv are written as CSV, anything else as JSON Lines.
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+func NewLogger(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trip log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trip log: %w", err)
+	}
+
+	l := &Logger{file: file}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to open trip log: %w", err)
+		}
+		l.csv = csv.NewWriter(file)
+		if info.Size() == 0 {
+			l.csv.Write(csvHeader)
+			l.csv.Flush()
+		}
+	} else {
+		l.json = json.NewEncoder(file)
+	}
+
+	return l, nil
+}
+
+// Write appends one trip. Lines are flushed immediately so the log can be
+// followed while the simulation runs.
+func (l *Logger) Write(trip events.TripCompletedEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("trip log is closed")
+	}
+
+	if l.json != nil {
+		return l.json.Encode(trip)
+	}
+
+	l.csv.Write(csvRecord(trip))
+	l.csv.Flush()
+	return l.csv.Error()
+}
+
+// Subscribe logs every trip completed in the dispatcher's world until the
+// returned function is called.
+func (l *Logger) Subscribe(d *events.Dispatcher) func() {
+	return d.Subscribe(events.EventTripCompleted, func(payload any) {
+		trip, ok := payload.(events.TripCompletedEvent)
+		if !ok {
+			return
+		}
+		if err := l.Write(trip); err != nil {
+			log.Printf("Failed to log trip %s: %v", trip.VehicleID, err)
+		}
+	})
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func csvRecord(trip events.TripCompletedEvent) []string {
+	return []string{
+		trip.VehicleID,
+		strconv.FormatBool(trip.Emergency),
+		trip.Origin,
+		trip.Destination,
+		formatFloat(trip.SpawnTime),
+		formatFloat(trip.ArrivalTime),
+		formatFloat(trip.TravelTime),
+		formatFloat(trip.Distance),
+		strconv.Itoa(trip.Stops),
+		formatFloat(trip.StoppedTime),
+		formatFloat(trip.MaxSpeed),
+		strings.Join(trip.Route, ";"),
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
@@ -0,0 +1,98 @@
+package triplog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"traffic-sim/internal/events"
+)
+
+func testTrip(id string) events.TripCompletedEvent {
+	return events.TripCompletedEvent{
+		VehicleID:   id,
+		Origin:      "sp1",
+		Destination: "dp1",
+		SpawnTime:   10,
+		ArrivalTime: 55.5,
+		TravelTime:  45.5,
+		Route:       []string{"r1", "r2", "r3"},
+		Distance:    420,
+		Stops:       2,
+		StoppedTime: 12,
+		MaxSpeed:    13.9,
+	}
+}
+
+func TestCSVLogAppendsWithSingleHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trips.csv")
+
+	for _, id := range []string{"v1", "v2"} {
+		l, err := NewLogger(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Write(testTrip(id)); err != nil {
+			t.Fatal(err)
+		}
+		l.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d records", len(records))
+	}
+	if records[0][0] != "vehicle_id" {
+		t.Errorf("expected header row, got %v", records[0])
+	}
+	if records[2][0] != "v2" {
+		t.Errorf("expected second trip to be v2, got %s", records[2][0])
+	}
+	if route := records[1][len(records[1])-1]; route != "r1;r2;r3" {
+		t.Errorf("expected route r1;r2;r3, got %s", route)
+	}
+}
+
+func TestJSONLinesFromEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trips.jsonl")
+	l, err := NewLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := events.NewDispatcher()
+	unsub := l.Subscribe(d)
+	d.Emit(events.EventTripCompleted, testTrip("v1"))
+	unsub()
+	d.Emit(events.EventTripCompleted, testTrip("v2"))
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line after unsubscribing, got %d", len(lines))
+	}
+
+	var trip events.TripCompletedEvent
+	if err := json.Unmarshal([]byte(lines[0]), &trip); err != nil {
+		t.Fatal(err)
+	}
+	if trip.VehicleID != "v1" || trip.Stops != 2 || len(trip.Route) != 3 {
+		t.Errorf("trip did not round-trip: %+v", trip)
+	}
+}
@@ -0,0 +1,450 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"traffic-sim/internal/metrics"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type ChartDock int
+
+const (
+	DockBottom ChartDock = iota
+	DockRight
+	DockFloating
+)
+
+func (d ChartDock) String() string {
+	switch d {
+	case DockRight:
+		return "Right"
+	case DockFloating:
+		return "Float"
+	default:
+		return "Bottom"
+	}
+}
+
+const (
+	chartMargin     = 20.0
+	chartTop        = 130.0
+	chartHeaderSize = 45.0
+	chartCellWidth  = 260.0
+	chartGrip       = 8.0
+	chartMinWidth   = 300.0
+	chartMinHeight  = 200.0
+)
+
+type chartSeries struct {
+	values []float64
+	color  color.RGBA
+}
+
+type chart struct {
+	label  *Label
+	series []chartSeries
+}
+
+// ChartPanel plots the sampler's rolling time series: network load, spawn
+// and despawn rates, speed, waiting vehicles and the selected road's flow
+// and speed. It docks to the bottom or right edge or floats where it is
+// dragged, can be resized from the edge facing the map, and can be paused
+// to freeze the plotted data while the simulation keeps running.
+type ChartPanel struct {
+	X, Y          float64
+	Width, Height float64
+	shadowOffset  float64
+	Visible       bool
+	Dock          ChartDock
+
+	bgColor     color.RGBA
+	shadowColor color.RGBA
+	plotColor   color.RGBA
+	gridColor   color.RGBA
+
+	titleLabel *Label
+	spanLabel  *Label
+	pauseBtn   *Button
+	dockBtn    *Button
+	closeBtn   *Button
+
+	charts []*chart
+	times  []float64
+	window float64
+	paused bool
+
+	screenWidth, screenHeight float64
+	dockedWidth, dockedHeight float64
+
+	dragging              bool
+	resizing              bool
+	grabX, grabY          float64
+	grabWidth, grabHeight float64
+}
+
+func NewChartPanel() *ChartPanel {
+	p := &ChartPanel{
+		shadowOffset: 3,
+		bgColor:      color.RGBA{40, 40, 50, 240},
+		shadowColor:  color.RGBA{0, 0, 0, 80},
+		plotColor:    color.RGBA{28, 28, 36, 255},
+		gridColor:    color.RGBA{70, 70, 85, 255},
+		window:       metrics.DefaultSampleWindow,
+		screenWidth:  1920,
+		screenHeight: 1080,
+		dockedWidth:  420,
+		dockedHeight: 280,
+	}
+
+	p.titleLabel = NewLabel(0, 0, "Live Charts")
+	p.titleLabel.Size = 16
+	p.titleLabel.Color = color.RGBA{255, 255, 255, 255}
+	p.spanLabel = NewLabel(0, 0, "")
+	p.spanLabel.Size = 13
+
+	p.pauseBtn = NewButton(0, 0, 70, 26, "Pause", func() {
+		p.paused = !p.paused
+	})
+	p.dockBtn = NewButton(0, 0, 90, 26, "Dock: Bottom", func() {
+		p.SetDock((p.Dock + 1) % (DockFloating + 1))
+	})
+	p.closeBtn = NewButton(0, 0, 70, 26, "Close (G)", func() {
+		p.Visible = false
+	})
+
+	blue := color.RGBA{90, 150, 220, 255}
+	p.charts = []*chart{
+		{series: []chartSeries{{color: blue}}},
+		{series: []chartSeries{{color: color.RGBA{100, 200, 120, 255}}, {color: color.RGBA{220, 120, 90, 255}}}},
+		{series: []chartSeries{{color: blue}}},
+		{series: []chartSeries{{color: color.RGBA{230, 190, 80, 255}}}},
+		{series: []chartSeries{{color: color.RGBA{180, 130, 220, 255}}}},
+		{series: []chartSeries{{color: blue}}},
+	}
+	for _, c := range p.charts {
+		c.label = NewLabel(0, 0, "")
+		c.label.Size = 13
+	}
+
+	p.layout()
+	return p
+}
+
+func (p *ChartPanel) Contains(x, y int) bool {
+	if !p.Visible {
+		return false
+	}
+	fx, fy := float64(x), float64(y)
+	return fx >= p.X && fx <= p.X+p.Width && fy >= p.Y && fy <= p.Y+p.Height
+}
+
+func (p *ChartPanel) Toggle() {
+	p.Visible = !p.Visible
+}
+
+func (p *ChartPanel) Paused() bool {
+	return p.paused
+}
+
+func (p *ChartPanel) SetDock(dock ChartDock) {
+	p.Dock = dock
+	p.dockBtn.Text = "Dock: " + dock.String()
+	p.layout()
+}
+
+// UpdatePosition re-applies the dock after the window is resized.
+func (p *ChartPanel) UpdatePosition(screenWidth, screenHeight int) {
+	p.screenWidth = float64(screenWidth)
+	p.screenHeight = float64(screenHeight)
+	p.layout()
+}
+
+func (p *ChartPanel) layout() {
+	switch p.Dock {
+	case DockBottom:
+		p.Width = p.screenWidth - 2*chartMargin
+		p.Height = p.dockedHeight
+		p.X = chartMargin
+		p.Y = p.screenHeight - p.Height - chartMargin
+	case DockRight:
+		p.Width = p.dockedWidth
+		p.Height = p.screenHeight - chartTop - chartMargin
+		p.X = p.screenWidth - p.Width - chartMargin
+		p.Y = chartTop
+	default:
+		p.Width = max(p.Width, chartMinWidth)
+		p.Height = max(p.Height, chartMinHeight)
+	}
+
+	p.titleLabel.X, p.titleLabel.Y = p.X+15, p.Y+12
+	p.spanLabel.X, p.spanLabel.Y = p.X+130, p.Y+14
+
+	right := p.X + p.Width - 15
+	p.closeBtn.X, p.closeBtn.Y = right-float64(p.closeBtn.calculateWidth()), p.Y+8
+	right = p.closeBtn.X - 10
+	p.dockBtn.X, p.dockBtn.Y = right-float64(p.dockBtn.calculateWidth()), p.Y+8
+	right = p.dockBtn.X - 10
+	p.pauseBtn.X, p.pauseBtn.Y = right-float64(p.pauseBtn.calculateWidth()), p.Y+8
+}
+
+// cells returns the grid the charts are laid out in: as many columns as
+// fit the panel width, and rows to hold the rest.
+func (p *ChartPanel) cells() (cols, rows int, cellWidth, cellHeight float64) {
+	innerWidth := p.Width - 30
+	cols = max(1, min(len(p.charts), int(innerWidth/chartCellWidth)))
+	rows = (len(p.charts) + cols - 1) / cols
+	cellWidth = innerWidth / float64(cols)
+	cellHeight = (p.Height - chartHeaderSize - 10) / float64(rows)
+	return cols, rows, cellWidth, cellHeight
+}
+
+// Refresh replaces the plotted data with the latest samples. It does
+// nothing while the panel is paused. road may be nil when no road is
+// selected.
+func (p *ChartPanel) Refresh(samples []metrics.Sample, window float64, roadID string, road []metrics.RoadPoint) {
+	if p.paused {
+		return
+	}
+	p.window = window
+
+	n := len(samples)
+	p.times = make([]float64, n)
+	vehicles := make([]float64, n)
+	spawned := make([]float64, n)
+	despawned := make([]float64, n)
+	speed := make([]float64, n)
+	waiting := make([]float64, n)
+	for i, s := range samples {
+		p.times[i] = s.Time
+		vehicles[i] = float64(s.Vehicles)
+		spawned[i] = s.SpawnRate
+		despawned[i] = s.DespawnRate
+		speed[i] = s.MeanSpeed
+		waiting[i] = float64(s.Waiting)
+	}
+
+	// The road series may be shorter when the road was built mid-window;
+	// pad the front so it lines up with the network series.
+	flow := make([]float64, n)
+	roadSpeed := make([]float64, n)
+	for i := range flow {
+		flow[i], roadSpeed[i] = math.NaN(), math.NaN()
+	}
+	offset := n - len(road)
+	for i, rp := range road {
+		if offset+i < 0 {
+			continue
+		}
+		flow[offset+i] = rp.Flow
+		if rp.Vehicles > 0 {
+			roadSpeed[offset+i] = rp.Speed
+		}
+	}
+
+	p.charts[0].series[0].values = vehicles
+	p.charts[1].series[0].values = spawned
+	p.charts[1].series[1].values = despawned
+	p.charts[2].series[0].values = speed
+	p.charts[3].series[0].values = waiting
+	p.charts[4].series[0].values = flow
+	p.charts[5].series[0].values = roadSpeed
+
+	p.charts[0].label.Text = fmt.Sprintf("Vehicles: %.0f", last(vehicles))
+	p.charts[1].label.Text = fmt.Sprintf("Spawn %.1f / despawn %.1f veh/min", last(spawned), last(despawned))
+	p.charts[2].label.Text = fmt.Sprintf("Mean speed: %.1f m/s", last(speed))
+	p.charts[3].label.Text = fmt.Sprintf("Waiting: %.0f", last(waiting))
+	if roadID == "" {
+		p.charts[4].label.Text = "Road flow: click a road"
+		p.charts[5].label.Text = "Road speed: click a road"
+	} else {
+		p.charts[4].label.Text = fmt.Sprintf("%s flow: %.0f veh/h", roadID, last(flow))
+		p.charts[5].label.Text = fmt.Sprintf("%s speed: %.1f m/s", roadID, last(roadSpeed))
+	}
+	p.spanLabel.Text = fmt.Sprintf("last %.0f min of sim time", window/60)
+}
+
+func last(values []float64) float64 {
+	if len(values) == 0 || math.IsNaN(values[len(values)-1]) {
+		return 0
+	}
+	return values[len(values)-1]
+}
+
+func (p *ChartPanel) Update(mouseX, mouseY int, clicked bool) {
+	if !p.Visible {
+		p.dragging, p.resizing = false, false
+		return
+	}
+
+	p.pauseBtn.Update(mouseX, mouseY, clicked)
+	p.dockBtn.Update(mouseX, mouseY, clicked)
+	p.closeBtn.Update(mouseX, mouseY, clicked)
+	p.pauseBtn.Text = "Pause"
+	if p.paused {
+		p.pauseBtn.Text = "Resume"
+	}
+
+	fx, fy := float64(mouseX), float64(mouseY)
+	if clicked {
+		onButton := p.pauseBtn.Contains(mouseX, mouseY) || p.dockBtn.Contains(mouseX, mouseY) || p.closeBtn.Contains(mouseX, mouseY)
+		if p.onGrip(fx, fy) {
+			p.resizing = true
+		} else if !onButton && p.Contains(mouseX, mouseY) && fy <= p.Y+chartHeaderSize {
+			p.dragging = true
+		}
+		p.grabX, p.grabY = fx, fy
+		p.grabWidth, p.grabHeight = p.Width, p.Height
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		p.dragging, p.resizing = false, false
+	}
+
+	if p.dragging {
+		if p.Dock != DockFloating {
+			p.SetDock(DockFloating)
+		}
+		p.X += fx - p.grabX
+		p.Y += fy - p.grabY
+		p.grabX, p.grabY = fx, fy
+		p.layout()
+	}
+	if p.resizing {
+		p.resize(fx-p.grabX, fy-p.grabY)
+	}
+}
+
+// onGrip reports whether a point is on the resize grip: the edge facing the
+// map when docked, or the bottom-right corner when floating.
+func (p *ChartPanel) onGrip(x, y float64) bool {
+	switch p.Dock {
+	case DockBottom:
+		return x >= p.X && x <= p.X+p.Width && math.Abs(y-p.Y) <= chartGrip
+	case DockRight:
+		return y >= p.Y && y <= p.Y+p.Height && math.Abs(x-p.X) <= chartGrip
+	default:
+		return math.Abs(x-(p.X+p.Width)) <= 2*chartGrip && math.Abs(y-(p.Y+p.Height)) <= 2*chartGrip
+	}
+}
+
+func (p *ChartPanel) resize(dx, dy float64) {
+	switch p.Dock {
+	case DockBottom:
+		p.dockedHeight = min(max(p.grabHeight-dy, chartMinHeight), p.screenHeight-chartTop-chartMargin)
+	case DockRight:
+		p.dockedWidth = min(max(p.grabWidth-dx, chartMinWidth), p.screenWidth-2*chartMargin)
+	default:
+		p.Width = max(p.grabWidth+dx, chartMinWidth)
+		p.Height = max(p.grabHeight+dy, chartMinHeight)
+	}
+	p.layout()
+}
+
+func (p *ChartPanel) Draw(screen *ebiten.Image) {
+	if !p.Visible {
+		return
+	}
+
+	NewRect(float32(p.X+p.shadowOffset), float32(p.Y+p.shadowOffset), float32(p.Width), float32(p.Height), 13, p.shadowColor).draw(screen)
+	NewRect(float32(p.X), float32(p.Y), float32(p.Width), float32(p.Height), 10, p.bgColor).draw(screen)
+
+	p.titleLabel.Draw(screen)
+	p.spanLabel.Draw(screen)
+	p.pauseBtn.Draw(screen)
+	p.dockBtn.Draw(screen)
+	p.closeBtn.Draw(screen)
+
+	cols, _, cellWidth, cellHeight := p.cells()
+	for i, c := range p.charts {
+		x := p.X + 15 + float64(i%cols)*cellWidth
+		y := p.Y + chartHeaderSize + float64(i/cols)*cellHeight
+		c.label.X, c.label.Y = x, y
+		c.label.Draw(screen)
+		p.drawPlot(screen, c, x, y+22, cellWidth-15, cellHeight-32)
+	}
+
+	p.drawGrip(screen)
+}
+
+func (p *ChartPanel) drawPlot(screen *ebiten.Image, c *chart, x, y, width, height float64) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	NewRect(float32(x), float32(y), float32(width), float32(height), 4, p.plotColor).draw(screen)
+
+	top := 0.0
+	for _, s := range c.series {
+		for _, v := range s.values {
+			if !math.IsNaN(v) {
+				top = max(top, v)
+			}
+		}
+	}
+	top = niceCeil(top)
+
+	for i := 1; i < 4; i++ {
+		gy := float32(y + height*float64(i)/4)
+		vector.StrokeLine(screen, float32(x), gy, float32(x+width), gy, 1, p.gridColor, false)
+	}
+	scale := NewLabel(x+4, y+2, fmt.Sprintf("%g", top))
+	scale.Size = 11
+	scale.Color = color.RGBA{150, 150, 165, 255}
+	scale.Draw(screen)
+
+	if len(p.times) == 0 {
+		return
+	}
+	end := p.times[len(p.times)-1]
+	start := end - p.window
+	px := func(t float64) float32 {
+		return float32(x + width*(t-start)/p.window)
+	}
+	py := func(v float64) float32 {
+		return float32(y + height - height*v/top)
+	}
+
+	for _, s := range c.series {
+		for i := 1; i < len(s.values) && i < len(p.times); i++ {
+			a, b := s.values[i-1], s.values[i]
+			if math.IsNaN(a) || math.IsNaN(b) {
+				continue
+			}
+			vector.StrokeLine(screen, px(p.times[i-1]), py(a), px(p.times[i]), py(b), 1.5, s.color, true)
+		}
+	}
+}
+
+func (p *ChartPanel) drawGrip(screen *ebiten.Image) {
+	gripColor := color.RGBA{110, 110, 130, 255}
+	switch p.Dock {
+	case DockBottom:
+		cx := float32(p.X + p.Width/2)
+		vector.StrokeLine(screen, cx-20, float32(p.Y+4), cx+20, float32(p.Y+4), 2, gripColor, true)
+	case DockRight:
+		cy := float32(p.Y + p.Height/2)
+		vector.StrokeLine(screen, float32(p.X+4), cy-20, float32(p.X+4), cy+20, 2, gripColor, true)
+	default:
+		rx, ry := float32(p.X+p.Width-4), float32(p.Y+p.Height-4)
+		for _, d := range []float32{6, 11} {
+			vector.StrokeLine(screen, rx-d, ry, rx, ry-d, 2, gripColor, true)
+		}
+	}
+}
+
+// niceCeil rounds a chart maximum up to 1, 2 or 5 times a power of ten so
+// the scale reads cleanly.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*mag {
+			return step * mag
+		}
+	}
+	return 10 * mag
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ConfirmPanel asks a yes or no question in the middle of the screen.
+// Nothing else takes input while it is open.
+type ConfirmPanel struct {
+	X, Y          float64
+	Width, Height float64
+	shadowOffset  float64
+	Visible       bool
+
+	bgColor     color.RGBA
+	shadowColor color.RGBA
+
+	onYes, onNo func()
+
+	titleLabel    *Label
+	messageLabels []*Label
+	yesBtn        *Button
+	noBtn         *Button
+}
+
+func NewConfirmPanel() *ConfirmPanel {
+	p := &ConfirmPanel{
+		Width:        480,
+		Height:       130,
+		shadowOffset: 3,
+		bgColor:      color.RGBA{40, 40, 50, 240},
+		shadowColor:  color.RGBA{0, 0, 0, 80},
+	}
+
+	p.titleLabel = NewLabel(0, 0, "")
+	p.titleLabel.Size = 16
+	p.titleLabel.Color = color.RGBA{255, 255, 255, 255}
+
+	p.yesBtn = NewButton(0, 0, 90, 30, "Yes", func() {
+		p.answer(p.onYes)
+	})
+	p.noBtn = NewButton(0, 0, 90, 30, "No (Esc)", func() {
+		p.answer(p.onNo)
+	})
+
+	p.SetPosition(400, 200)
+	return p
+}
+
+// Ask shows the question. message may span several lines. onYes or onNo
+// is called with the answer; either may be nil.
+func (p *ConfirmPanel) Ask(title, message string, onYes, onNo func()) {
+	p.titleLabel.Text = title
+	p.messageLabels = p.messageLabels[:0]
+	for _, line := range strings.Split(message, "\n") {
+		label := NewLabel(0, 0, line)
+		label.Size = 13
+		p.messageLabels = append(p.messageLabels, label)
+	}
+	p.Height = 110 + float64(len(p.messageLabels))*20
+	p.onYes, p.onNo = onYes, onNo
+	p.Visible = true
+	p.SetPosition(p.X, p.Y)
+}
+
+func (p *ConfirmPanel) IsVisible() bool {
+	return p.Visible
+}
+
+func (p *ConfirmPanel) answer(fn func()) {
+	p.Visible = false
+	p.onYes, p.onNo = nil, nil
+	if fn != nil {
+		fn()
+	}
+}
+
+func (p *ConfirmPanel) Contains(x, y int) bool {
+	if !p.Visible {
+		return false
+	}
+	fx, fy := float64(x), float64(y)
+	return fx >= p.X && fx <= p.X+p.Width && fy >= p.Y && fy <= p.Y+p.Height
+}
+
+// UpdatePosition centres the panel on the screen.
+func (p *ConfirmPanel) UpdatePosition(screenWidth, screenHeight int) {
+	p.SetPosition((float64(screenWidth)-p.Width)/2, (float64(screenHeight)-p.Height)/2)
+}
+
+func (p *ConfirmPanel) SetPosition(x, y float64) {
+	p.X = x
+	p.Y = y
+
+	p.titleLabel.X, p.titleLabel.Y = x+15, y+15
+	for i, label := range p.messageLabels {
+		label.X, label.Y = x+15, y+50+float64(i)*20
+	}
+
+	btnY := y + p.Height - 45
+	p.noBtn.X, p.noBtn.Y = x+p.Width-float64(p.noBtn.calculateWidth())-15, btnY
+	p.yesBtn.X, p.yesBtn.Y = p.noBtn.X-float64(p.yesBtn.calculateWidth())-10, btnY
+}
+
+func (p *ConfirmPanel) Update(mouseX, mouseY int, clicked bool) {
+	if !p.Visible {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		p.answer(p.onNo)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		p.answer(p.onYes)
+		return
+	}
+
+	p.yesBtn.Update(mouseX, mouseY, clicked)
+	if p.Visible {
+		p.noBtn.Update(mouseX, mouseY, clicked)
+	}
+}
+
+func (p *ConfirmPanel) Draw(screen *ebiten.Image) {
+	if !p.Visible {
+		return
+	}
+
+	NewRect(float32(p.X+p.shadowOffset), float32(p.Y+p.shadowOffset), float32(p.Width), float32(p.Height), 13, p.shadowColor).draw(screen)
+	NewRect(float32(p.X), float32(p.Y), float32(p.Width), float32(p.Height), 10, p.bgColor).draw(screen)
+	p.titleLabel.Draw(screen)
+	for _, label := range p.messageLabels {
+		label.Draw(screen)
+	}
+	p.yesBtn.Draw(screen)
+	p.noBtn.Draw(screen)
+}
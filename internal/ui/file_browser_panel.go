@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"traffic-sim/internal/persistence"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	fileBrowserRows      = 12
+	fileBrowserRowHeight = 28.0
+)
+
+// FileBrowserPanel lists the save files in a directory so one can be picked
+// when no native file dialog is available. When saving it also offers a
+// new file.
+type FileBrowserPanel struct {
+	X, Y          float64
+	Width, Height float64
+	shadowOffset  float64
+	Visible       bool
+
+	bgColor     color.RGBA
+	shadowColor color.RGBA
+	rowColor    color.RGBA
+	hoverColor  color.RGBA
+
+	dir     string
+	files   []persistence.SaveFileInfo
+	scroll  int
+	hovered int
+	newPath string
+	onPick  func(path string)
+
+	titleLabel *Label
+	emptyLabel *Label
+	nameLabels []*Label
+	timeLabels []*Label
+	newBtn     *Button
+	cancelBtn  *Button
+}
+
+func NewFileBrowserPanel(dir string) *FileBrowserPanel {
+	p := &FileBrowserPanel{
+		Width:        560,
+		Height:       130 + fileBrowserRows*fileBrowserRowHeight,
+		shadowOffset: 3,
+		bgColor:      color.RGBA{40, 40, 50, 240},
+		shadowColor:  color.RGBA{0, 0, 0, 80},
+		rowColor:     color.RGBA{55, 55, 65, 255},
+		hoverColor:   color.RGBA{80, 80, 95, 255},
+		dir:          dir,
+		hovered:      -1,
+	}
+
+	p.titleLabel = NewLabel(0, 0, "")
+	p.titleLabel.Size = 16
+	p.titleLabel.Color = color.RGBA{255, 255, 255, 255}
+	p.emptyLabel = NewLabel(0, 0, "No saves in "+dir)
+	p.emptyLabel.Size = 13
+
+	for i := 0; i < fileBrowserRows; i++ {
+		name := NewLabel(0, 0, "")
+		name.Size = 13
+		when := NewLabel(0, 0, "")
+		when.Size = 12
+		when.Color = color.RGBA{160, 160, 170, 255}
+		p.nameLabels = append(p.nameLabels, name)
+		p.timeLabels = append(p.timeLabels, when)
+	}
+
+	p.newBtn = NewButton(0, 0, 90, 30, "New file", func() {
+		p.pick(p.newPath)
+	})
+	p.cancelBtn = NewButton(0, 0, 90, 30, "Cancel (Esc)", func() {
+		p.Hide()
+	})
+
+	p.SetPosition(400, 200)
+	return p
+}
+
+// Browse opens the panel on the directory's saves, newest first. onPick is
+// called with the chosen path; newPath, when set, is offered as a new file
+// for saving.
+func (p *FileBrowserPanel) Browse(title, newPath string, onPick func(path string)) {
+	files, err := persistence.ListSaveFiles(p.dir)
+	if err != nil {
+		log.Printf("Failed to list saves: %v", err)
+	}
+	p.files = files
+	p.scroll = 0
+	p.newPath = newPath
+	p.onPick = onPick
+	p.titleLabel.Text = title
+	p.Visible = true
+	p.SetPosition(p.X, p.Y)
+}
+
+func (p *FileBrowserPanel) IsVisible() bool {
+	return p.Visible
+}
+
+func (p *FileBrowserPanel) Hide() {
+	p.Visible = false
+	p.onPick = nil
+}
+
+func (p *FileBrowserPanel) pick(path string) {
+	onPick := p.onPick
+	p.Hide()
+	if onPick != nil && path != "" {
+		onPick(path)
+	}
+}
+
+func (p *FileBrowserPanel) Contains(x, y int) bool {
+	if !p.Visible {
+		return false
+	}
+	fx, fy := float64(x), float64(y)
+	return fx >= p.X && fx <= p.X+p.Width && fy >= p.Y && fy <= p.Y+p.Height
+}
+
+// UpdatePosition centres the panel on the screen.
+func (p *FileBrowserPanel) UpdatePosition(screenWidth, screenHeight int) {
+	p.SetPosition((float64(screenWidth)-p.Width)/2, (float64(screenHeight)-p.Height)/2)
+}
+
+func (p *FileBrowserPanel) SetPosition(x, y float64) {
+	p.X = x
+	p.Y = y
+
+	p.titleLabel.X, p.titleLabel.Y = x+15, y+15
+	p.emptyLabel.X, p.emptyLabel.Y = x+15, y+60
+	for i := range p.nameLabels {
+		rowY := p.rowY(i)
+		p.nameLabels[i].X, p.nameLabels[i].Y = x+25, rowY+6
+		p.timeLabels[i].X, p.timeLabels[i].Y = x+p.Width-170, rowY+7
+	}
+
+	btnY := y + p.Height - 45
+	p.cancelBtn.X, p.cancelBtn.Y = x+p.Width-float64(p.cancelBtn.calculateWidth())-15, btnY
+	p.newBtn.X, p.newBtn.Y = x+15, btnY
+}
+
+func (p *FileBrowserPanel) rowY(i int) float64 {
+	return p.Y + 50 + float64(i)*fileBrowserRowHeight
+}
+
+// rowAt returns the file row under the mouse, or -1.
+func (p *FileBrowserPanel) rowAt(mouseX, mouseY int) int {
+	fx, fy := float64(mouseX), float64(mouseY)
+	if fx < p.X+15 || fx > p.X+p.Width-15 || fy < p.rowY(0) {
+		return -1
+	}
+	i := int((fy - p.rowY(0)) / fileBrowserRowHeight)
+	if i >= fileBrowserRows || p.scroll+i >= len(p.files) {
+		return -1
+	}
+	return i
+}
+
+func (p *FileBrowserPanel) Update(mouseX, mouseY int, clicked bool) {
+	if !p.Visible {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		p.Hide()
+		return
+	}
+
+	_, wheel := ebiten.Wheel()
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || wheel < 0 {
+		p.scroll++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || wheel > 0 {
+		p.scroll--
+	}
+	p.scroll = max(0, min(p.scroll, len(p.files)-fileBrowserRows))
+
+	p.hovered = p.rowAt(mouseX, mouseY)
+	if clicked && p.hovered >= 0 {
+		p.pick(p.files[p.scroll+p.hovered].Path)
+		return
+	}
+
+	if p.newPath != "" {
+		p.newBtn.Update(mouseX, mouseY, clicked)
+	}
+	p.cancelBtn.Update(mouseX, mouseY, clicked)
+}
+
+func (p *FileBrowserPanel) Draw(screen *ebiten.Image) {
+	if !p.Visible {
+		return
+	}
+
+	NewRect(float32(p.X+p.shadowOffset), float32(p.Y+p.shadowOffset), float32(p.Width), float32(p.Height), 13, p.shadowColor).draw(screen)
+	NewRect(float32(p.X), float32(p.Y), float32(p.Width), float32(p.Height), 10, p.bgColor).draw(screen)
+	p.titleLabel.Draw(screen)
+
+	if len(p.files) == 0 {
+		p.emptyLabel.Draw(screen)
+	}
+	for i := 0; i < fileBrowserRows && p.scroll+i < len(p.files); i++ {
+		f := p.files[p.scroll+i]
+		bg := p.rowColor
+		if i == p.hovered {
+			bg = p.hoverColor
+		}
+		NewRect(float32(p.X+15), float32(p.rowY(i)+2), float32(p.Width-30), float32(fileBrowserRowHeight-4), 5, bg).draw(screen)
+		p.nameLabels[i].Text = f.Name
+		p.timeLabels[i].Text = fmt.Sprintf("%s  %4d KB", f.ModTime.Format("2006-01-02 15:04"), (f.Size+1023)/1024)
+		p.nameLabels[i].Draw(screen)
+		p.timeLabels[i].Draw(screen)
+	}
+
+	if p.newPath != "" {
+		p.newBtn.Draw(screen)
+	}
+	p.cancelBtn.Draw(screen)
+}
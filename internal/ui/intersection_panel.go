@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"traffic-sim/internal/metrics"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// IntersectionPanel shows the delay, queue and level-of-service report of
+// the selected intersection, per approach and overall.
+type IntersectionPanel struct {
+	X, Y          float64
+	Width, Height float64
+	shadowOffset  float64
+	Visible       bool
+
+	bgColor     color.RGBA
+	shadowColor color.RGBA
+
+	titleLabel *Label
+	labels     []*Label
+	exportBtn  *Button
+	closeBtn   *Button
+
+	onExport func()
+	onClose  func()
+}
+
+func NewIntersectionPanel(x, y float64) *IntersectionPanel {
+	p := &IntersectionPanel{
+		X:            x,
+		Y:            y,
+		Width:        380,
+		shadowOffset: 3,
+		bgColor:      color.RGBA{40, 40, 50, 240},
+		shadowColor:  color.RGBA{0, 0, 0, 80},
+	}
+
+	p.titleLabel = NewLabel(0, 0, "")
+	p.titleLabel.Size = 16
+	p.titleLabel.Color = color.RGBA{255, 255, 255, 255}
+
+	p.exportBtn = NewButton(0, 0, 90, 30, "Export CSV", func() {
+		if p.onExport != nil {
+			p.onExport()
+		}
+	})
+	p.closeBtn = NewButton(0, 0, 90, 30, "Close", func() {
+		if p.onClose != nil {
+			p.onClose()
+		}
+	})
+
+	p.SetPosition(x, y)
+	return p
+}
+
+func (p *IntersectionPanel) Contains(x, y int) bool {
+	if !p.Visible {
+		return false
+	}
+	fx, fy := float64(x), float64(y)
+	return fx >= p.X && fx <= p.X+p.Width && fy >= p.Y && fy <= p.Y+p.Height
+}
+
+func (p *IntersectionPanel) SetOnExport(callback func()) {
+	p.onExport = callback
+}
+
+func (p *IntersectionPanel) SetOnClose(callback func()) {
+	p.onClose = callback
+}
+
+func (p *IntersectionPanel) Hide() {
+	p.Visible = false
+}
+
+// Show fills the panel from a report. It is called every frame while an
+// intersection is selected, so the numbers follow the running simulation.
+func (p *IntersectionPanel) Show(report *metrics.IntersectionReport) {
+	p.Visible = true
+
+	control := "unsignalized"
+	if report.Signalized {
+		control = "signalized"
+	}
+	p.titleLabel.Text = fmt.Sprintf("Intersection %s (%s)", report.NodeID, control)
+
+	lines := []string{
+		fmt.Sprintf("LOS %s - %.1fs delay/veh over %d vehicles", report.LOS, report.AvgDelay, report.Vehicles),
+		fmt.Sprintf("Queue: max %d, avg %.1f veh - %.0f%% stopped", report.MaxQueue, report.AvgQueue, report.StoppedShare*100),
+		fmt.Sprintf("Observed %s to %s", formatClock(report.Start), formatClock(report.End)),
+		"Approach: veh, delay, queue max/avg, stopped, LOS",
+	}
+	for _, a := range report.Approaches {
+		lines = append(lines, fmt.Sprintf("%s: %d, %.1fs, %d/%.1f, %.0f%%, %s",
+			a.RoadID, a.Vehicles, a.AvgDelay, a.MaxQueue, a.AvgQueue, a.StoppedShare*100, a.LOS))
+	}
+
+	for len(p.labels) < len(lines) {
+		label := NewLabel(0, 0, "")
+		label.Size = 13
+		p.labels = append(p.labels, label)
+	}
+	p.labels = p.labels[:len(lines)]
+	for i, line := range lines {
+		p.labels[i].Text = line
+	}
+
+	p.SetPosition(p.X, p.Y)
+}
+
+func (p *IntersectionPanel) SetPosition(x, y float64) {
+	p.X = x
+	p.Y = y
+
+	p.titleLabel.X, p.titleLabel.Y = x+15, y+15
+	rowY := y + 50
+	for i, label := range p.labels {
+		if i == 3 {
+			rowY += 8
+		}
+		label.X, label.Y = x+15, rowY
+		rowY += 22
+	}
+
+	p.exportBtn.X, p.exportBtn.Y = x+22, rowY+10
+	p.closeBtn.X, p.closeBtn.Y = x+p.Width-float64(p.closeBtn.calculateWidth())-22, rowY+10
+	p.Height = rowY + 55 - y
+}
+
+func (p *IntersectionPanel) Update(mouseX, mouseY int, clicked bool) {
+	if !p.Visible {
+		return
+	}
+
+	p.exportBtn.Update(mouseX, mouseY, clicked)
+	p.closeBtn.Update(mouseX, mouseY, clicked)
+}
+
+func (p *IntersectionPanel) Draw(screen *ebiten.Image) {
+	if !p.Visible {
+		return
+	}
+
+	NewRect(float32(p.X+p.shadowOffset), float32(p.Y+p.shadowOffset), float32(p.Width), float32(p.Height), 13, p.shadowColor).draw(screen)
+	NewRect(float32(p.X), float32(p.Y), float32(p.Width), float32(p.Height), 10, p.bgColor).draw(screen)
+
+	p.titleLabel.Draw(screen)
+	for _, label := range p.labels {
+		label.Draw(screen)
+	}
+	p.exportBtn.Draw(screen)
+	p.closeBtn.Draw(screen)
+}
@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"traffic-sim/internal/replay"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ReplayPanel controls a recording being played back: play/pause, playback
+// speed and a timeline that can be clicked or dragged to scrub.
+type ReplayPanel struct {
+	X, Y          float64
+	Width, Height float64
+	shadowOffset  float64
+
+	bgColor     color.RGBA
+	shadowColor color.RGBA
+	trackColor  color.RGBA
+	fillColor   color.RGBA
+
+	player *replay.Player
+
+	titleLabel *Label
+	timeLabel  *Label
+	speedLabel *Label
+	playBtn    *Button
+	slowerBtn  *Button
+	fasterBtn  *Button
+	exitBtn    *Button
+
+	trackX, trackY, trackWidth float64
+	scrubbing                  bool
+
+	onExit func()
+}
+
+func NewReplayPanel(player *replay.Player, path string) *ReplayPanel {
+	p := &ReplayPanel{
+		Width:        900,
+		Height:       110,
+		shadowOffset: 3,
+		bgColor:      color.RGBA{40, 40, 50, 240},
+		shadowColor:  color.RGBA{0, 0, 0, 80},
+		trackColor:   color.RGBA{70, 70, 85, 255},
+		fillColor:    color.RGBA{90, 150, 220, 255},
+		player:       player,
+	}
+
+	p.titleLabel = NewLabel(0, 0, "Replay: "+filepath.Base(path))
+	p.titleLabel.Size = 16
+	p.titleLabel.Color = color.RGBA{255, 255, 255, 255}
+	p.timeLabel = NewLabel(0, 0, "")
+	p.speedLabel = NewLabel(0, 0, "")
+
+	p.playBtn = NewButton(0, 0, 90, 30, "Play (Space)", func() {
+		p.player.TogglePlay()
+	})
+	p.slowerBtn = NewButton(0, 0, 40, 30, "Slower (Down)", func() {
+		p.player.Slower()
+	})
+	p.fasterBtn = NewButton(0, 0, 40, 30, "Faster (Up)", func() {
+		p.player.Faster()
+	})
+	p.exitBtn = NewButton(0, 0, 90, 30, "Exit (Esc)", func() {
+		if p.onExit != nil {
+			p.onExit()
+		}
+	})
+
+	p.SetPosition(20, 900)
+	return p
+}
+
+func (p *ReplayPanel) SetOnExit(callback func()) {
+	p.onExit = callback
+}
+
+// UpdatePosition places the panel centred at the bottom of the screen.
+func (p *ReplayPanel) UpdatePosition(screenWidth, screenHeight int) {
+	p.SetPosition((float64(screenWidth)-p.Width)/2, float64(screenHeight)-p.Height-20)
+}
+
+func (p *ReplayPanel) SetPosition(x, y float64) {
+	p.X = x
+	p.Y = y
+
+	p.titleLabel.X, p.titleLabel.Y = x+15, y+12
+	p.speedLabel.X, p.speedLabel.Y = x+p.Width-320, y+12
+	p.timeLabel.X, p.timeLabel.Y = x+p.Width-180, y+12
+
+	btnY := y + 45
+	p.playBtn.X, p.playBtn.Y = x+15, btnY
+	p.slowerBtn.X, p.slowerBtn.Y = p.playBtn.X+float64(p.playBtn.calculateWidth())+10, btnY
+	p.fasterBtn.X, p.fasterBtn.Y = p.slowerBtn.X+float64(p.slowerBtn.calculateWidth())+10, btnY
+	p.exitBtn.X, p.exitBtn.Y = x+p.Width-float64(p.exitBtn.calculateWidth())-15, btnY
+
+	p.trackX = p.fasterBtn.X + float64(p.fasterBtn.calculateWidth()) + 30
+	p.trackWidth = p.exitBtn.X - 30 - p.trackX
+	p.trackY = btnY + 11
+}
+
+func (p *ReplayPanel) Update(mouseX, mouseY int, clicked bool, dt float64) {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		p.player.TogglePlay()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		p.player.Faster()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		p.player.Slower()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		p.player.Seek(p.player.Time() + 10)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		p.player.Seek(p.player.Time() - 10)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) && p.onExit != nil {
+		p.onExit()
+		return
+	}
+
+	p.playBtn.Update(mouseX, mouseY, clicked)
+	p.slowerBtn.Update(mouseX, mouseY, clicked)
+	p.fasterBtn.Update(mouseX, mouseY, clicked)
+	p.exitBtn.Update(mouseX, mouseY, clicked)
+
+	if clicked && p.onTrack(mouseX, mouseY) {
+		p.scrubbing = true
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		p.scrubbing = false
+	}
+	if p.scrubbing {
+		f := (float64(mouseX) - p.trackX) / p.trackWidth
+		p.player.SeekFraction(max(0, min(f, 1)))
+	} else {
+		p.player.Update(dt)
+	}
+
+	p.playBtn.Text = "Play (Space)"
+	if p.player.Playing() {
+		p.playBtn.Text = "Pause (Space)"
+	}
+	p.speedLabel.Text = fmt.Sprintf("Speed: %gx", p.player.Speed())
+	p.timeLabel.Text = fmt.Sprintf("%s / %s", formatClock(p.player.Time()), formatClock(p.player.End()))
+}
+
+func (p *ReplayPanel) onTrack(mouseX, mouseY int) bool {
+	fx, fy := float64(mouseX), float64(mouseY)
+	return fx >= p.trackX-5 && fx <= p.trackX+p.trackWidth+5 && fy >= p.trackY-10 && fy <= p.trackY+18
+}
+
+func (p *ReplayPanel) Draw(screen *ebiten.Image) {
+	NewRect(float32(p.X+p.shadowOffset), float32(p.Y+p.shadowOffset), float32(p.Width), float32(p.Height), 13, p.shadowColor).draw(screen)
+	NewRect(float32(p.X), float32(p.Y), float32(p.Width), float32(p.Height), 10, p.bgColor).draw(screen)
+
+	p.titleLabel.Draw(screen)
+	p.speedLabel.Draw(screen)
+	p.timeLabel.Draw(screen)
+	p.playBtn.Draw(screen)
+	p.slowerBtn.Draw(screen)
+	p.fasterBtn.Draw(screen)
+	p.exitBtn.Draw(screen)
+
+	NewRect(float32(p.trackX), float32(p.trackY), float32(p.trackWidth), 8, 4, p.trackColor).draw(screen)
+	filled := p.trackWidth * p.player.Progress()
+	if filled >= 8 {
+		NewRect(float32(p.trackX), float32(p.trackY), float32(filled), 8, 4, p.fillColor).draw(screen)
+	}
+	NewRect(float32(p.trackX+filled-6), float32(p.trackY-4), 12, 16, 5, color.RGBA{230, 230, 240, 255}).draw(screen)
+}
+
+func formatClock(seconds float64) string {
+	s := int(seconds)
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
@@ -26,6 +26,10 @@ type StatsPanel struct {
 	spawnCount     int
 	despawnCount   int
 	trafficLights  int
+
+	emergencyArrivals  int
+	totalResponseTime  float64
+	lastResponseTime   float64
 	
 	world        *world.World
 	unsubscribers []func()
@@ -36,7 +40,7 @@ func NewStatsPanel(x, y float64, w *world.World) *StatsPanel {
 		X:            x,
 		Y:            y,
 		Width:        280,
-		Height:       240,
+		Height:       265,
 		shadowOffset: 3,
 		bgColor:      color.RGBA{40, 40, 50, 240},
 		textColor:    color.RGBA{220, 220, 220, 255},
@@ -90,6 +94,9 @@ func (p *StatsPanel) setupUI() {
 	yOffset += 25
 	
 	p.labels = append(p.labels, NewLabel(p.X+15, yOffset, fmt.Sprintf("Traffic Lights: %d", p.trafficLights)))
+	yOffset += 25
+
+	p.labels = append(p.labels, NewLabel(p.X+15, yOffset, p.emergencyText()))
 	
 	for _, label := range p.labels {
 		label.Size = 14
@@ -143,6 +150,18 @@ func (p *StatsPanel) subscribeToEvents() {
 	})
 	p.unsubscribers = append(p.unsubscribers, unsub7)
 	
+	unsub9 := p.world.Events.Subscribe(events.EventEmergencyArrived, func(payload any) {
+		ev, ok := payload.(events.EmergencyArrivedEvent)
+		if !ok {
+			return
+		}
+		p.emergencyArrivals++
+		p.totalResponseTime += ev.ResponseTime
+		p.lastResponseTime = ev.ResponseTime
+		p.updateLabels()
+	})
+	p.unsubscribers = append(p.unsubscribers, unsub9)
+
 	unsub8 := p.world.Events.Subscribe(events.EventWorldLoaded, func(payload any) {
 		ev, ok := payload.(events.WorldLoadedEvent)
 		if !ok {
@@ -155,7 +174,7 @@ func (p *StatsPanel) subscribeToEvents() {
 }
 
 func (p *StatsPanel) updateLabels() {
-	if len(p.labels) < 7 {
+	if len(p.labels) < 8 {
 		return
 	}
 	
@@ -165,6 +184,15 @@ func (p *StatsPanel) updateLabels() {
 	p.labels[4].Text = fmt.Sprintf("Spawn Points: %d", p.spawnCount)
 	p.labels[5].Text = fmt.Sprintf("Despawn Points: %d", p.despawnCount)
 	p.labels[6].Text = fmt.Sprintf("Traffic Lights: %d", p.trafficLights)
+	p.labels[7].Text = p.emergencyText()
+}
+
+func (p *StatsPanel) emergencyText() string {
+	if p.emergencyArrivals == 0 {
+		return "Emergency: no arrivals"
+	}
+	avg := p.totalResponseTime / float64(p.emergencyArrivals)
+	return fmt.Sprintf("Emergency: %d (last %.1fs, avg %.1fs)", p.emergencyArrivals, p.lastResponseTime, avg)
 }
 
 func (p *StatsPanel) Update() {
@@ -209,6 +237,9 @@ func (p *StatsPanel) ReplaceWorld(newWorld *world.World) {
 	p.unsubscribers = make([]func(), 0)
 	
 	p.world = newWorld
+	p.emergencyArrivals = 0
+	p.totalResponseTime = 0
+	p.lastResponseTime = 0
 	p.initializeStats()
 	p.updateLabels()
 	p.subscribeToEvents()
@@ -3,10 +3,13 @@ package ui
 import (
 	"fmt"
 	"image/color"
+	"traffic-sim/internal/filedialog"
 	"traffic-sim/internal/input"
+	"traffic-sim/internal/metrics"
 	"traffic-sim/internal/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
 type Toolbar struct {
@@ -28,10 +31,23 @@ type Toolbar struct {
 	roadPropBtn *Button
 	spawnPointPropBtn *Button
 	roadCurveBtn *Button
+	emergencyBtn    *Button
+	speedZoneBtn    *Button
+	incidentBtn     *Button
+	detectorBtn     *Button
+	chartsBtn       *Button
 	saveBtn         *Button
 	loadBtn         *Button
+	snapshotBtn     *Button
+	recordBtn       *Button
+	replayBtn       *Button
+	metricsBtn      *Button
     roadPropertiesPanel *RoadPropertiesPanel
     spawnPointPropertiesPanel *SpawnerPropertiesPanel
+	intersectionPanel *IntersectionPanel
+	chartPanel        *ChartPanel
+	fileBrowserPanel  *FileBrowserPanel
+	confirmPanel      *ConfirmPanel
 
 	world *world.World
 }
@@ -135,6 +151,60 @@ func (tb *Toolbar) setupUI() {
 	
 	tb.loadBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Load (Ctrl+O)", nil)
 	tb.uiManager.AddButton(tb.loadBtn)
+	currentX += float64(tb.loadBtn.calculateWidth()) + spacingX
+
+	tb.snapshotBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Snapshot (Ctrl+Shift+S)", func() {
+		tb.inputHandler.SaveSnapshot()
+	})
+	tb.uiManager.AddButton(tb.snapshotBtn)
+	currentX += float64(tb.snapshotBtn.calculateWidth()) + spacingX
+
+	tb.recordBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Record (Ctrl+R)", func() {
+		tb.inputHandler.ToggleRecording()
+	})
+	tb.uiManager.AddButton(tb.recordBtn)
+	currentX += float64(tb.recordBtn.calculateWidth()) + spacingX
+
+	tb.replayBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Replay (Ctrl+P)", func() {
+		tb.inputHandler.OpenReplay()
+	})
+	tb.uiManager.AddButton(tb.replayBtn)
+	currentX += float64(tb.replayBtn.calculateWidth()) + spacingX
+
+	tb.metricsBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Metrics CSV (Ctrl+M)", func() {
+		tb.inputHandler.ExportMetrics()
+	})
+	tb.uiManager.AddButton(tb.metricsBtn)
+	currentX += float64(tb.metricsBtn.calculateWidth()) + spacingX
+
+	tb.emergencyBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Emergency (E)", func() {
+		tb.inputHandler.SetMode(input.ModeEmergency)
+	})
+	tb.uiManager.AddButton(tb.emergencyBtn)
+	currentX += float64(tb.emergencyBtn.calculateWidth()) + spacingX
+
+	tb.speedZoneBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Speed Zone (Z)", func() {
+		tb.inputHandler.SetMode(input.ModeSpeedZone)
+	})
+	tb.uiManager.AddButton(tb.speedZoneBtn)
+	currentX += float64(tb.speedZoneBtn.calculateWidth()) + spacingX
+
+	tb.incidentBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Incident (I)", func() {
+		tb.inputHandler.SetMode(input.ModeIncident)
+	})
+	tb.uiManager.AddButton(tb.incidentBtn)
+	currentX += float64(tb.incidentBtn.calculateWidth()) + spacingX
+
+	tb.detectorBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Detector (L)", func() {
+		tb.inputHandler.SetMode(input.ModeDetector)
+	})
+	tb.uiManager.AddButton(tb.detectorBtn)
+	currentX += float64(tb.detectorBtn.calculateWidth()) + spacingX
+
+	tb.chartsBtn = NewButton(currentX, btnY, btnWidth, btnHeight, "Charts (G)", func() {
+		tb.chartPanel.Toggle()
+	})
+	tb.uiManager.AddButton(tb.chartsBtn)
 	
 	btnY += btnHeight + spacingY
 
@@ -170,8 +240,26 @@ func (tb *Toolbar) setupUI() {
 		}
 	})
 	
+	tb.intersectionPanel = NewIntersectionPanel(1520, 200)
+	tb.intersectionPanel.SetOnExport(func() {
+		tb.inputHandler.ExportIntersections()
+	})
+	tb.intersectionPanel.SetOnClose(func() {
+		tb.inputHandler.ClearSelectedIntersection()
+	})
+	
 	tb.inputHandler.SetRoadPropertiesPanel(tb.roadPropertiesPanel)
 	tb.inputHandler.SetSpawnPointPropertiesPanel(tb.spawnPointPropertiesPanel)
+	tb.chartPanel = NewChartPanel()
+
+	tb.inputHandler.SetIntersectionPanel(tb.intersectionPanel)
+	tb.inputHandler.SetChartPanel(tb.chartPanel)
+
+	tb.fileBrowserPanel = NewFileBrowserPanel(filedialog.SaveDir)
+	tb.inputHandler.SetFileBrowser(tb.fileBrowserPanel)
+
+	tb.confirmPanel = NewConfirmPanel()
+	tb.inputHandler.SetConfirmPanel(tb.confirmPanel)
 }
 
 func (tb *Toolbar) UpdatePanelPositions(screenWidth, screenHeight int) {
@@ -181,9 +269,21 @@ func (tb *Toolbar) UpdatePanelPositions(screenWidth, screenHeight int) {
 	
 	tb.roadPropertiesPanel.SetPosition(panelX, panelY)
 	tb.spawnPointPropertiesPanel.SetPosition(panelX, panelY)
+	tb.intersectionPanel.SetPosition(float64(screenWidth)-tb.intersectionPanel.Width-panelMargin, panelY)
+	tb.chartPanel.UpdatePosition(screenWidth, screenHeight)
+	tb.fileBrowserPanel.UpdatePosition(screenWidth, screenHeight)
+	tb.confirmPanel.UpdatePosition(screenWidth, screenHeight)
 }
 
 func (tb *Toolbar) Update(mouseX, mouseY int, clicked bool) {
+	if tb.confirmPanel.Visible {
+		tb.confirmPanel.Update(mouseX, mouseY, clicked)
+		return
+	}
+	if tb.fileBrowserPanel.Visible {
+		tb.fileBrowserPanel.Update(mouseX, mouseY, clicked)
+		return
+	}
 	tb.uiManager.Update(mouseX, mouseY, clicked)
 	tb.updateModeIndicator()
 	tb.updateSimulationStatus()
@@ -211,6 +311,57 @@ func (tb *Toolbar) Update(mouseX, mouseY int, clicked bool) {
 	
 	tb.roadPropertiesPanel.Update(mouseX, mouseY, clicked)
 	tb.spawnPointPropertiesPanel.Update(mouseX, mouseY, clicked)
+	tb.updateIntersectionPanel(mouseX, mouseY, clicked)
+	tb.updateChartPanel(mouseX, mouseY, clicked)
+}
+
+func (tb *Toolbar) updateChartPanel(mouseX, mouseY int, clicked bool) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		tb.chartPanel.Toggle()
+	}
+	if !tb.chartPanel.Visible || tb.chartPanel.Paused() {
+		tb.chartPanel.Update(mouseX, mouseY, clicked)
+		return
+	}
+
+	rd := tb.inputHandler.ChartRoad()
+	if rd != nil {
+		tb.world.Mu.RLock()
+		exists := tb.world.FindRoad(rd.ID) == rd
+		tb.world.Mu.RUnlock()
+		if !exists {
+			tb.inputHandler.ClearChartRoad()
+			rd = nil
+		}
+	}
+
+	sampler := tb.inputHandler.Simulator.Sampler()
+	roadID := ""
+	var points []metrics.RoadPoint
+	if rd != nil {
+		roadID = rd.ID
+		points = sampler.Road(rd.ID)
+	}
+	tb.chartPanel.Refresh(sampler.Samples(), sampler.Window(), roadID, points)
+	tb.chartPanel.Update(mouseX, mouseY, clicked)
+}
+
+func (tb *Toolbar) updateIntersectionPanel(mouseX, mouseY int, clicked bool) {
+	node := tb.inputHandler.SelectedIntersection()
+	if tb.inputHandler.Mode() != input.ModeNormal || node == nil {
+		tb.intersectionPanel.Hide()
+		return
+	}
+
+	report := tb.inputHandler.Simulator.Intersections().Report(tb.world, node.ID)
+	if report == nil {
+		tb.inputHandler.ClearSelectedIntersection()
+		tb.intersectionPanel.Hide()
+		return
+	}
+
+	tb.intersectionPanel.Show(report)
+	tb.intersectionPanel.Update(mouseX, mouseY, clicked)
 }
 
 func (tb *Toolbar) updateModeIndicator() {
@@ -221,7 +372,7 @@ func (tb *Toolbar) updateModeIndicator() {
 	
 	switch mode {
 	case input.ModeNormal:
-		modeText = "Mode: Normal"
+		modeText = "Mode: Normal (Click node for intersection report, road for charts)"
 		bgColor = color.RGBA{45, 50, 65, 240}
 	case input.ModeRoadBuilding:
 		modeText = "Mode: Build Road"
@@ -287,6 +438,33 @@ func (tb *Toolbar) updateModeIndicator() {
 	case input.ModeRoadCurving:
 		modeText = tb.inputHandler.RoadCurveTool().GetStatusMessage()
 		bgColor = color.RGBA{75, 60, 90, 240}
+	case input.ModeEmergency:
+		modeText = "Mode: Emergency - Click spawn point, then destination despawn point"
+		bgColor = color.RGBA{110, 40, 40, 240}
+		if tb.inputHandler.EmergencyTool().GetSelectedSpawnPoint() != nil {
+			modeText = "Mode: Emergency (Origin Selected - Click despawn point to dispatch)"
+		}
+	case input.ModeSpeedZone:
+		zoneTool := tb.inputHandler.SpeedZoneTool()
+		modeText = fmt.Sprintf("Mode: Speed Zone %.0f (Up/Down) - Click zone start on road, right-click zone to remove", zoneTool.Limit())
+		bgColor = color.RGBA{110, 85, 35, 240}
+		if zoneTool.GetSelectedRoad() != nil {
+			modeText = fmt.Sprintf("Mode: Speed Zone %.0f (Start at %.0fm - Click zone end)", zoneTool.Limit(), zoneTool.GetStartDistance())
+		}
+	case input.ModeIncident:
+		incidentTool := tb.inputHandler.IncidentTool()
+		duration := "until cleared"
+		if incidentTool.Duration() > 0 {
+			duration = fmt.Sprintf("%.0fs", incidentTool.Duration())
+		}
+		modeText = fmt.Sprintf("Mode: Incident %s, %s (Tab kind, Up/Down duration) - Click start on road, right-click to clear", incidentTool.Kind(), duration)
+		bgColor = color.RGBA{120, 60, 30, 240}
+		if incidentTool.GetSelectedRoad() != nil {
+			modeText = fmt.Sprintf("Mode: Incident %s (Start at %.0fm - Click end, or start again for rest of road)", incidentTool.Kind(), incidentTool.GetStartDistance())
+		}
+	case input.ModeDetector:
+		modeText = "Mode: Detector - Click road to place a loop detector, right-click detector to remove"
+		bgColor = color.RGBA{35, 85, 100, 240}
 	}
 	
 	tb.modeIndicator.Text = modeText
@@ -308,6 +486,13 @@ func (tb *Toolbar) updateSimulationStatus() {
 		bgColor = color.RGBA{45, 55, 45, 240}
 	}
 	
+	if tb.inputHandler.IsRecording() {
+		modeText += " (Recording)"
+		tb.recordBtn.Text = "Stop Rec (Ctrl+R)"
+	} else {
+		tb.recordBtn.Text = "Record (Ctrl+R)"
+	}
+	
 	tb.simulationState.Text = modeText
 	tb.simulationState.SetBackground(bgColor)
 }
@@ -392,6 +577,30 @@ func (tb *Toolbar) updateButtonStates() {
 		tb.roadCurveBtn.SetColors(normalColor, normalHover, normalPress, textColor, borderColor)
 	}
 	
+	if mode == input.ModeEmergency {
+		tb.emergencyBtn.SetColors(activeColor, activeHover, activePress, textColor, borderColor)
+	} else {
+		tb.emergencyBtn.SetColors(normalColor, normalHover, normalPress, textColor, borderColor)
+	}
+	
+	if mode == input.ModeSpeedZone {
+		tb.speedZoneBtn.SetColors(activeColor, activeHover, activePress, textColor, borderColor)
+	} else {
+		tb.speedZoneBtn.SetColors(normalColor, normalHover, normalPress, textColor, borderColor)
+	}
+	
+	if mode == input.ModeIncident {
+		tb.incidentBtn.SetColors(activeColor, activeHover, activePress, textColor, borderColor)
+	} else {
+		tb.incidentBtn.SetColors(normalColor, normalHover, normalPress, textColor, borderColor)
+	}
+	
+	if mode == input.ModeDetector {
+		tb.detectorBtn.SetColors(activeColor, activeHover, activePress, textColor, borderColor)
+	} else {
+		tb.detectorBtn.SetColors(normalColor, normalHover, normalPress, textColor, borderColor)
+	}
+	
 	if tb.inputHandler.RoadTool().IsBidirectional() {
 		tb.bidirToggle.Text = "Bidir: ON (B)"
 		tb.bidirToggle.SetColors(activeColor, activeHover, activePress, textColor, borderColor)
@@ -406,6 +615,16 @@ func (tb *Toolbar) Draw(screen *ebiten.Image) {
 	tb.statsPanel.Draw(screen)
 	tb.roadPropertiesPanel.Draw(screen)
 	tb.spawnPointPropertiesPanel.Draw(screen)
+	tb.intersectionPanel.Draw(screen)
+	tb.chartPanel.Draw(screen)
+	tb.fileBrowserPanel.Draw(screen)
+	tb.confirmPanel.Draw(screen)
+}
+
+// Confirm asks a yes or no question over everything else. onYes or onNo is
+// called with the answer; either may be nil.
+func (tb *Toolbar) Confirm(title, message string, onYes, onNo func()) {
+	tb.confirmPanel.Ask(title, message, onYes, onNo)
 }
 
 func (tb *Toolbar) GetUIManager() *UIManager {
@@ -0,0 +1,43 @@
+package vehicle
+
+const (
+	stopSpeed   = 0.5
+	resumeSpeed = 2.0
+)
+
+// TripStats accumulates what happens to a vehicle between spawn and despawn.
+type TripStats struct {
+	Route       []string
+	Distance    float64
+	Stops       int
+	StoppedTime float64
+	MaxSpeed    float64
+	Stopped     bool
+}
+
+// Record adds one tick spent on roadID at the given speed. A vehicle counts
+// as stopped below stopSpeed and moving again only above resumeSpeed, so
+// creeping in a queue is not counted as many separate stops.
+func (t *TripStats) Record(roadID string, speed, dt float64) {
+	if n := len(t.Route); n == 0 || t.Route[n-1] != roadID {
+		t.Route = append(t.Route, roadID)
+	}
+
+	t.Distance += speed * dt
+	if speed > t.MaxSpeed {
+		t.MaxSpeed = speed
+	}
+
+	if t.Stopped {
+		if speed > resumeSpeed {
+			t.Stopped = false
+		}
+	} else if speed < stopSpeed {
+		t.Stopped = true
+		t.Stops++
+	}
+
+	if t.Stopped {
+		t.StoppedTime += dt
+	}
+}
@@ -0,0 +1,30 @@
+package vehicle
+
+import "testing"
+
+func TestTripStatsCountsStopsWithHysteresis(t *testing.T) {
+	var trip TripStats
+
+	speeds := []float64{10, 0.2, 1.0, 0.1, 5, 0, 8}
+	for _, s := range speeds {
+		trip.Record("r1", s, 1)
+	}
+	trip.Record("r2", 8, 1)
+	trip.Record("r2", 8, 1)
+
+	if trip.Stops != 2 {
+		t.Errorf("expected 2 stops, got %d", trip.Stops)
+	}
+	if trip.StoppedTime != 4 {
+		t.Errorf("expected 4s stopped, got %.1f", trip.StoppedTime)
+	}
+	if trip.MaxSpeed != 10 {
+		t.Errorf("expected max speed 10, got %.1f", trip.MaxSpeed)
+	}
+	if trip.Distance != 40.3 {
+		t.Errorf("expected distance 40.3, got %.2f", trip.Distance)
+	}
+	if len(trip.Route) != 2 || trip.Route[0] != "r1" || trip.Route[1] != "r2" {
+		t.Errorf("expected route [r1 r2], got %v", trip.Route)
+	}
+}
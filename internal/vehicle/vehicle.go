@@ -11,8 +11,16 @@ type Vec2 struct {
 	Y float64
 }
 
+type Kind int
+
+const (
+	KindCar Kind = iota
+	KindEmergency
+)
+
 type Vehicle struct {
 	ID       string
+	Kind     Kind
 	Road     *road.Road
 	NextRoad *road.Road
 	Distance float64
@@ -25,6 +33,19 @@ type Vehicle struct {
 	TransitionSpeed   float64
 	
 	TargetDespawn     *road.DespawnPoint
+
+	Origin      *road.SpawnPoint
+	SpawnTime   float64
+	PullingOver bool
+	// PullOver is how far the vehicle has moved towards the road edge, from
+	// 0 in its lane to 1 fully pulled over.
+	PullOver float64
+
+	Trip TripStats
+}
+
+func (v *Vehicle) IsEmergency() bool {
+	return v.Kind == KindEmergency
 }
 
 func (v *Vehicle) Position() Vec2 {
@@ -0,0 +1,42 @@
+package world
+
+import "traffic-sim/internal/road"
+
+// IsRoadClosed reports whether an active closure blocks any part of rd.
+func (w *World) IsRoadClosed(rd *road.Road) bool {
+	for _, inc := range w.Incidents {
+		if inc.Active && inc.IsClosure() && inc.Road == rd {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *World) ActiveIncidentsOn(rd *road.Road) []*road.Incident {
+	var active []*road.Incident
+	for _, inc := range w.Incidents {
+		if inc.Active && inc.Road == rd {
+			active = append(active, inc)
+		}
+	}
+	return active
+}
+
+func (w *World) FindIncident(id string) *road.Incident {
+	for _, inc := range w.Incidents {
+		if inc.ID == id {
+			return inc
+		}
+	}
+	return nil
+}
+
+func (w *World) RemoveIncident(id string) *road.Incident {
+	for i, inc := range w.Incidents {
+		if inc.ID == id {
+			w.Incidents = append(w.Incidents[:i], w.Incidents[i+1:]...)
+			return inc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package world
+
+import "traffic-sim/internal/road"
+
+// The Find* helpers expect the caller to hold w.Mu.
+
+func (w *World) FindRoad(id string) *road.Road {
+	for _, rd := range w.Roads {
+		if rd.ID == id {
+			return rd
+		}
+	}
+	return nil
+}
+
+func (w *World) FindSpawnPoint(id string) *road.SpawnPoint {
+	for _, sp := range w.SpawnPoints {
+		if sp.ID == id {
+			return sp
+		}
+	}
+	return nil
+}
+
+func (w *World) FindDespawnPoint(id string) *road.DespawnPoint {
+	for _, dp := range w.DespawnPoints {
+		if dp.ID == id {
+			return dp
+		}
+	}
+	return nil
+}
+
+func (w *World) FindTrafficLight(id string) *road.TrafficLight {
+	for _, tl := range w.TrafficLights {
+		if tl.ID == id {
+			return tl
+		}
+	}
+	return nil
+}
+
+// FindDetector looks a detector up by ID across all roads.
+func (w *World) FindDetector(id string) (*road.Road, *road.Detector) {
+	for _, rd := range w.Roads {
+		if d := rd.FindDetector(id); d != nil {
+			return rd, d
+		}
+	}
+	return nil, nil
+}
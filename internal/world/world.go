@@ -1,7 +1,9 @@
 package world
 
 import (
+	"math/rand/v2"
 	"sync"
+	"time"
 
 	"traffic-sim/internal/events"
 	"traffic-sim/internal/road"
@@ -16,9 +18,18 @@ type World struct {
 	SpawnPoints   []*road.SpawnPoint
 	DespawnPoints []*road.DespawnPoint
 	TrafficLights []*road.TrafficLight 
+	Incidents     []*road.Incident
 
 	IntersectionsByNode map[string]*road.Intersection
 
+	SimTime float64
+
+	// Seed and RNG drive every random choice the systems make, so a world
+	// restored from a snapshot continues exactly where it left off.
+	Seed uint64
+	RNG  *rand.PCG
+	Rand *rand.Rand
+
 	Mu sync.RWMutex
 	Events *events.Dispatcher
 }
@@ -30,12 +41,36 @@ func New() *World {
 		Vehicles:            make([]*vehicle.Vehicle,0),
 		SpawnPoints:         make([]*road.SpawnPoint, 0),
 		DespawnPoints:       make([]*road.DespawnPoint, 0),
+		Incidents:           make([]*road.Incident, 0),
 		IntersectionsByNode: make(map[string]*road.Intersection),
 		Events:              events.NewDispatcher(),
 	}
+	w.SetSeed(uint64(time.Now().UnixNano()))
 	return w
 }
 
+func (w *World) SetSeed(seed uint64) {
+	w.Seed = seed
+	w.RNG = rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+	w.Rand = rand.New(w.RNG)
+}
+
+func (w *World) RNGState() ([]byte, error) {
+	return w.RNG.MarshalBinary()
+}
+
+// RestoreRNG puts the generator back into a state returned by RNGState.
+func (w *World) RestoreRNG(seed uint64, state []byte) error {
+	rng := rand.NewPCG(0, 0)
+	if err := rng.UnmarshalBinary(state); err != nil {
+		return err
+	}
+	w.Seed = seed
+	w.RNG = rng
+	w.Rand = rand.New(rng)
+	return nil
+}
+
 func (w *World) GetIntersection(nodeID string) *road.Intersection {
 	return w.IntersectionsByNode[nodeID]
 }
@@ -0,0 +1,295 @@
+// Package trafficsim is the embeddable API of the traffic simulator: build
+// or load a road network, run it with a Simulation, subscribe to its events
+// and read its metrics. It does not depend on the editor's graphics or file
+// dialogs.
+//
+//	net := trafficsim.NewNetwork()
+//	net.AddNode("a", 0, 0)
+//	net.AddNode("b", 400, 0)
+//	road, _ := net.AddRoad("a", "b", trafficsim.RoadOptions{})
+//	net.AddSpawnPoint("in", road, trafficsim.SpawnOptions{Interval: 2})
+//	net.AddDespawnPoint("out", road)
+//
+//	sim := trafficsim.NewSimulation(net, trafficsim.Options{Seed: 1})
+//	sim.Run(context.Background(), 600)
+package trafficsim
+
+import (
+	"fmt"
+
+	"traffic-sim/internal/commands"
+	"traffic-sim/internal/persistence"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/world"
+)
+
+const (
+	DefaultMaxSpeed = 40.0
+	DefaultWidth    = 8.0
+)
+
+// RoadOptions configures a new road. Zero fields take the defaults; TwoWay
+// also adds the road in the opposite direction.
+type RoadOptions struct {
+	MaxSpeed float64
+	Width    float64
+	TwoWay   bool
+}
+
+// SpawnOptions configures a spawn point. Zero fields keep the editor's
+// defaults: a vehicle every 3s at 20-40 speed, at most 50 at once.
+type SpawnOptions struct {
+	Interval    float64
+	MinSpeed    float64
+	MaxSpeed    float64
+	MaxVehicles int
+}
+
+// LightTiming sets the fixed-time cycle of a traffic light in seconds. Zero
+// fields keep their current value.
+type LightTiming struct {
+	Green  float64
+	Yellow float64
+	Red    float64
+}
+
+// Network is a road network under construction. Edits take the world lock,
+// so a network may be changed while a Simulation runs it.
+type Network struct {
+	world *world.World
+	exec  *commands.CommandExecutor
+}
+
+func NewNetwork() *Network {
+	return newNetwork(world.New())
+}
+
+func newNetwork(w *world.World) *Network {
+	return &Network{world: w, exec: commands.NewCommandExecutor(w)}
+}
+
+// LoadNetwork reads a save file written by the editor or by Save. Snapshots
+// load with their vehicles, but a Simulation starts their systems afresh.
+func LoadNetwork(path string) (*Network, error) {
+	w, _, err := persistence.LoadWorldFrom(path)
+	if err != nil {
+		return nil, err
+	}
+	return newNetwork(w), nil
+}
+
+// Save writes the network in the editor's save format.
+func (n *Network) Save(path string) error {
+	return n.exec.Execute(&commands.SaveWorldCommand{Path: path})
+}
+
+func (n *Network) AddNode(id string, x, y float64) error {
+	if id == "" {
+		return fmt.Errorf("node ID must not be empty")
+	}
+	if n.node(id) != nil {
+		return fmt.Errorf("node %q already exists", id)
+	}
+	return n.exec.Execute(&commands.CreateNodeCommand{X: x, Y: y, NodeID: id})
+}
+
+// MoveNode moves a node and the ends of every road attached to it.
+func (n *Network) MoveNode(id string, x, y float64) error {
+	node := n.node(id)
+	if node == nil {
+		return fmt.Errorf("node %q not found", id)
+	}
+	return n.exec.Execute(&commands.MoveNodeCommand{Node: node, NewX: x, NewY: y})
+}
+
+// AddRoad connects two nodes and returns the new road's ID, which is always
+// "<from>-<to>". With TwoWay set, the reverse road is "<to>-<from>".
+func (n *Network) AddRoad(from, to string, opts RoadOptions) (string, error) {
+	if opts.MaxSpeed <= 0 {
+		opts.MaxSpeed = DefaultMaxSpeed
+	}
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	fromNode, toNode := n.node(from), n.node(to)
+	switch {
+	case fromNode == nil:
+		return "", fmt.Errorf("node %q not found", from)
+	case toNode == nil:
+		return "", fmt.Errorf("node %q not found", to)
+	}
+
+	id := roadID(from, to)
+	if n.road(id) != nil {
+		return "", fmt.Errorf("road %q already exists", id)
+	}
+	if err := n.exec.Execute(&commands.CreateRoadCommand{From: fromNode, To: toNode, MaxSpeed: opts.MaxSpeed, Width: opts.Width}); err != nil {
+		return "", err
+	}
+	if opts.TwoWay && from != to && n.road(roadID(to, from)) == nil {
+		if err := n.exec.Execute(&commands.CreateRoadCommand{From: toNode, To: fromNode, MaxSpeed: opts.MaxSpeed, Width: opts.Width}); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// CurveRoad bends a road into a Bezier curve that leaves along the incoming
+// road and arrives along the outgoing one, as the editor's curve tool does.
+// Either may be empty to keep that end straight. A two-way road's reverse
+// follows the same curve.
+func (n *Network) CurveRoad(roadID, incoming, outgoing string) error {
+	rd := n.road(roadID)
+	if rd == nil {
+		return fmt.Errorf("road %q not found", roadID)
+	}
+	cmd := &commands.CurveRoadCommand{Road: rd}
+	if incoming != "" {
+		if cmd.IncomingRoad = n.road(incoming); cmd.IncomingRoad == nil {
+			return fmt.Errorf("road %q not found", incoming)
+		}
+	}
+	if outgoing != "" {
+		if cmd.OutgoingRoad = n.road(outgoing); cmd.OutgoingRoad == nil {
+			return fmt.Errorf("road %q not found", outgoing)
+		}
+	}
+	return n.exec.Execute(cmd)
+}
+
+// AddSpawnPoint releases vehicles onto a road at its start node.
+func (n *Network) AddSpawnPoint(id, roadID string, opts SpawnOptions) error {
+	rd := n.road(roadID)
+	if rd == nil {
+		return fmt.Errorf("road %q not found", roadID)
+	}
+	if n.spawnPoint(id) != nil {
+		return fmt.Errorf("spawn point %q already exists", id)
+	}
+	if err := n.exec.Execute(&commands.CreateSpawnPointCommand{SpawnID: id, Node: rd.From, Road: rd}); err != nil {
+		return err
+	}
+
+	update := &commands.UpdateSpawnPointPropertiesCommand{
+		SpawnPoint:  n.spawnPoint(id),
+		Interval:    opts.Interval,
+		MinSpeed:    -1,
+		MaxSpeed:    opts.MaxSpeed,
+		MaxVehicles: opts.MaxVehicles,
+		Enabled:     true,
+	}
+	if opts.MinSpeed > 0 {
+		update.MinSpeed = opts.MinSpeed
+	}
+	return n.exec.Execute(update)
+}
+
+// AddDespawnPoint removes vehicles that reach the end node of a road.
+func (n *Network) AddDespawnPoint(id, roadID string) error {
+	rd := n.road(roadID)
+	if rd == nil {
+		return fmt.Errorf("road %q not found", roadID)
+	}
+	if n.despawnPoint(id) != nil {
+		return fmt.Errorf("despawn point %q already exists", id)
+	}
+	return n.exec.Execute(&commands.CreateDespawnPointCommand{DespawnID: id, Node: rd.To, Road: rd})
+}
+
+// AddTrafficLight puts a light on roads arriving at a node. Lights at the
+// same node alternate: the first starts green, the second red, and so on.
+func (n *Network) AddTrafficLight(id, nodeID string, roadIDs ...string) error {
+	node := n.node(nodeID)
+	if node == nil {
+		return fmt.Errorf("node %q not found", nodeID)
+	}
+	if n.light(id) != nil {
+		return fmt.Errorf("traffic light %q already exists", id)
+	}
+	if len(roadIDs) == 0 {
+		return fmt.Errorf("traffic light %q controls no roads", id)
+	}
+	roads := make([]*road.Road, 0, len(roadIDs))
+	for _, rid := range roadIDs {
+		rd := n.road(rid)
+		if rd == nil {
+			return fmt.Errorf("road %q not found", rid)
+		}
+		if rd.To != node {
+			return fmt.Errorf("road %q does not end at node %q", rid, nodeID)
+		}
+		roads = append(roads, rd)
+	}
+	return n.exec.Execute(&commands.CreateTrafficLightCommand{LightID: id, Node: node, Roads: roads})
+}
+
+func (n *Network) SetLightTiming(id string, timing LightTiming) error {
+	tl := n.light(id)
+	if tl == nil {
+		return fmt.Errorf("traffic light %q not found", id)
+	}
+	return n.exec.Execute(&commands.UpdateTrafficLightTimingCommand{Light: tl, GreenTime: timing.Green, YellowTime: timing.Yellow, RedTime: timing.Red})
+}
+
+// Nodes and Roads list the IDs in the network, in the order they were added.
+func (n *Network) Nodes() []string {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+
+	ids := make([]string, len(n.world.Nodes))
+	for i, node := range n.world.Nodes {
+		ids[i] = node.ID
+	}
+	return ids
+}
+
+func (n *Network) Roads() []string {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+
+	ids := make([]string, len(n.world.Roads))
+	for i, rd := range n.world.Roads {
+		ids[i] = rd.ID
+	}
+	return ids
+}
+
+func roadID(from, to string) string {
+	return fmt.Sprintf("%s-%s", from, to)
+}
+
+func (n *Network) node(id string) *road.Node {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+
+	for _, node := range n.world.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func (n *Network) road(id string) *road.Road {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+	return n.world.FindRoad(id)
+}
+
+func (n *Network) spawnPoint(id string) *road.SpawnPoint {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+	return n.world.FindSpawnPoint(id)
+}
+
+func (n *Network) despawnPoint(id string) *road.DespawnPoint {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+	return n.world.FindDespawnPoint(id)
+}
+
+func (n *Network) light(id string) *road.TrafficLight {
+	n.world.Mu.RLock()
+	defer n.world.Mu.RUnlock()
+	return n.world.FindTrafficLight(id)
+}
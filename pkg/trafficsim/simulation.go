@@ -0,0 +1,239 @@
+package trafficsim
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"traffic-sim/internal/config"
+	"traffic-sim/internal/road"
+	"traffic-sim/internal/sim"
+)
+
+const DefaultTick = 8 * time.Millisecond
+
+// Options configures a Simulation. Zero fields take the defaults of the
+// shipped config.yaml; a Seed of 0 keeps the network's current generator.
+type Options struct {
+	Tick            time.Duration
+	Seed            uint64
+	MetricsInterval float64
+	// DisableRightOfWay turns off yielding at unsignalised junctions.
+	DisableRightOfWay bool
+}
+
+// Simulation runs a Network with the same systems as the editor and the
+// headless runner. Step and Run are serialised, so they may be called from
+// several goroutines, but events are delivered on the goroutine that steps.
+type Simulation struct {
+	net *Network
+	sim *sim.Simulator
+
+	mu       sync.Mutex
+	handlers map[string][]*subscription
+	unsubs   map[string]func()
+	pending  []Event
+}
+
+type subscription struct {
+	fn func(Event)
+}
+
+func NewSimulation(n *Network, opts Options) *Simulation {
+	if opts.Tick <= 0 {
+		opts.Tick = DefaultTick
+	}
+	cfg := config.Default()
+	if opts.MetricsInterval > 0 {
+		cfg.Metrics.Interval = opts.MetricsInterval
+	}
+	cfg.FeatureFlags.RightOfWaySystem = !opts.DisableRightOfWay
+
+	if opts.Seed != 0 {
+		n.world.Mu.Lock()
+		n.world.SetSeed(opts.Seed)
+		n.world.Mu.Unlock()
+	}
+
+	return &Simulation{
+		net:      n,
+		sim:      sim.NewSimulatorWithConfig(n.world, opts.Tick, cfg),
+		handlers: make(map[string][]*subscription),
+		unsubs:   make(map[string]func()),
+	}
+}
+
+func (s *Simulation) Network() *Network {
+	return s.net
+}
+
+// Time is the simulated time in seconds.
+func (s *Simulation) Time() float64 {
+	w := s.net.world
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+	return w.SimTime
+}
+
+// Step advances the simulation by one tick.
+func (s *Simulation) Step() {
+	s.sim.Step()
+	s.deliver()
+}
+
+// Run advances the simulation by the given number of simulated seconds as
+// fast as it can, checking ctx between ticks.
+func (s *Simulation) Run(ctx context.Context, seconds float64) error {
+	end := s.Time() + seconds
+	for s.Time() < end {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.Step()
+	}
+	return nil
+}
+
+// Subscribe calls fn for every event with the given name, one of the Event*
+// constants. Events are queued while the simulation steps and delivered
+// once the tick is done, so fn may call back into the Simulation and
+// Network; events caused by network edits arrive with the next step. The
+// returned function cancels the subscription.
+func (s *Simulation) Subscribe(name string, fn func(Event)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subscription{fn: fn}
+	s.handlers[name] = append(s.handlers[name], sub)
+	if s.unsubs[name] == nil {
+		s.unsubs[name] = s.net.world.Events.Subscribe(name, func(payload any) {
+			s.queue(name, payload)
+		})
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		subs := s.handlers[name]
+		for i := range subs {
+			if subs[i] == sub {
+				s.handlers[name] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// queue runs inside the simulation, possibly with the world locked, so it
+// only copies what the event refers to.
+func (s *Simulation) queue(name string, payload any) {
+	ev := newEvent(name, payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, ev)
+}
+
+// deliver stamps queued events with the time of the tick that raised them
+// and hands them to the subscribers.
+func (s *Simulation) deliver() {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	now := s.Time()
+	for _, ev := range pending {
+		ev.Time = now
+		s.mu.Lock()
+		subs := append([]*subscription(nil), s.handlers[ev.Name]...)
+		s.mu.Unlock()
+
+		for _, sub := range subs {
+			sub.fn(ev)
+		}
+	}
+}
+
+// Vehicles lists the vehicles currently on the network.
+func (s *Simulation) Vehicles() []Vehicle {
+	w := s.net.world
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	vehicles := make([]Vehicle, len(w.Vehicles))
+	for i, v := range w.Vehicles {
+		vehicles[i] = Vehicle{
+			ID:        v.ID,
+			Road:      v.Road.ID,
+			Distance:  v.Distance,
+			X:         v.Pos.X,
+			Y:         v.Pos.Y,
+			Speed:     v.Speed,
+			Emergency: v.IsEmergency(),
+		}
+	}
+	return vehicles
+}
+
+// Lights lists the traffic lights and their current state.
+func (s *Simulation) Lights() []Light {
+	w := s.net.world
+	w.Mu.RLock()
+	defer w.Mu.RUnlock()
+
+	lights := make([]Light, len(w.TrafficLights))
+	for i, tl := range w.TrafficLights {
+		lights[i] = Light{ID: tl.ID, State: lightState(tl.State), Timer: tl.Timer}
+		if tl.Intersection != nil {
+			lights[i].Node = tl.Intersection.ID
+		}
+	}
+	return lights
+}
+
+func lightState(state road.LightState) string {
+	switch state {
+	case road.LightGreen:
+		return "green"
+	case road.LightYellow:
+		return "yellow"
+	default:
+		return "red"
+	}
+}
+
+// RoadMetrics returns the per-road aggregates of every completed metrics
+// interval. FlushMetrics closes the interval in progress early.
+func (s *Simulation) RoadMetrics() []RoadInterval {
+	return s.sim.Metrics().Intervals()
+}
+
+func (s *Simulation) DetectorMetrics() []DetectorInterval {
+	return s.sim.Metrics().DetectorIntervals()
+}
+
+func (s *Simulation) FlushMetrics() {
+	s.sim.Metrics().Flush(s.net.world)
+}
+
+// IntersectionReports summarises delay, queues and level of service at every
+// intersection since the simulation started.
+func (s *Simulation) IntersectionReports() []IntersectionReport {
+	return s.sim.Intersections().Reports(s.net.world)
+}
+
+// Samples is the network-wide time series over the last ten minutes of
+// simulated time, one sample per second.
+func (s *Simulation) Samples() []Sample {
+	return s.sim.Sampler().Samples()
+}
+
+// RoadSeries is the time series of one road over the same window.
+func (s *Simulation) RoadSeries(roadID string) []RoadPoint {
+	return s.sim.Sampler().Road(roadID)
+}
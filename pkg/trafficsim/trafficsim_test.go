@@ -0,0 +1,147 @@
+package trafficsim_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"traffic-sim/pkg/trafficsim"
+)
+
+func buildCorridor(t *testing.T) *trafficsim.Network {
+	t.Helper()
+	net := trafficsim.NewNetwork()
+	for i, id := range []string{"a", "b", "c"} {
+		if err := net.AddNode(id, float64(i)*200, 0); err != nil {
+			t.Fatalf("add node failed: %v", err)
+		}
+	}
+	first, err := net.AddRoad("a", "b", trafficsim.RoadOptions{MaxSpeed: 20})
+	if err != nil {
+		t.Fatalf("add road failed: %v", err)
+	}
+	second, err := net.AddRoad("b", "c", trafficsim.RoadOptions{MaxSpeed: 20, TwoWay: true})
+	if err != nil {
+		t.Fatalf("add road failed: %v", err)
+	}
+	if err := net.CurveRoad(second, first, ""); err != nil {
+		t.Fatalf("curve road failed: %v", err)
+	}
+	if err := net.AddSpawnPoint("in", first, trafficsim.SpawnOptions{Interval: 2, MinSpeed: 10, MaxSpeed: 20}); err != nil {
+		t.Fatalf("add spawn point failed: %v", err)
+	}
+	if err := net.AddDespawnPoint("out", second); err != nil {
+		t.Fatalf("add despawn point failed: %v", err)
+	}
+	if err := net.AddTrafficLight("tl", "b", first); err != nil {
+		t.Fatalf("add traffic light failed: %v", err)
+	}
+	if err := net.SetLightTiming("tl", trafficsim.LightTiming{Green: 10, Red: 5}); err != nil {
+		t.Fatalf("set light timing failed: %v", err)
+	}
+	return net
+}
+
+func TestNetworkRejectsBadEdits(t *testing.T) {
+	net := buildCorridor(t)
+
+	if err := net.AddNode("a", 0, 0); err == nil {
+		t.Errorf("expected a duplicate node to be rejected")
+	}
+	if _, err := net.AddRoad("a", "b", trafficsim.RoadOptions{}); err == nil {
+		t.Errorf("expected a duplicate road to be rejected")
+	}
+	if _, err := net.AddRoad("a", "nowhere", trafficsim.RoadOptions{}); err == nil {
+		t.Errorf("expected a road to a missing node to be rejected")
+	}
+	if err := net.AddTrafficLight("tl2", "a", "a-b"); err == nil {
+		t.Errorf("expected a light on a road leaving the node to be rejected")
+	}
+	if got := net.Roads(); len(got) != 3 || got[2] != "c-b" {
+		t.Errorf("expected the two-way road to add its reverse, got %v", got)
+	}
+}
+
+func TestSimulationRunsNetwork(t *testing.T) {
+	net := buildCorridor(t)
+	sim := trafficsim.NewSimulation(net, trafficsim.Options{Seed: 1, MetricsInterval: 30})
+
+	var trips []trafficsim.Event
+	unsubscribe := sim.Subscribe(trafficsim.EventTripCompleted, func(ev trafficsim.Event) {
+		trips = append(trips, ev)
+		// Handlers run outside the tick, so calling back in is safe.
+		sim.Vehicles()
+	})
+	var nodes []string
+	sim.Subscribe(trafficsim.EventNodeCreated, func(ev trafficsim.Event) {
+		nodes = append(nodes, ev.ID)
+	})
+
+	if err := sim.Run(context.Background(), 120); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if sim.Time() < 120 {
+		t.Errorf("expected 120s simulated, got %f", sim.Time())
+	}
+	if len(trips) == 0 {
+		t.Fatal("expected completed trips")
+	}
+	if trip := trips[0].Trip; trip == nil || trip.Origin != "in" || trip.Destination != "out" || trips[0].Time < trip.ArrivalTime {
+		t.Errorf("unexpected trip event %+v", trips[0])
+	}
+	if len(sim.RoadMetrics()) == 0 {
+		t.Errorf("expected completed metrics intervals")
+	}
+	if len(sim.Samples()) == 0 {
+		t.Errorf("expected time series samples")
+	}
+	if lights := sim.Lights(); len(lights) != 1 || lights[0].Node != "b" {
+		t.Errorf("unexpected lights %+v", lights)
+	}
+
+	unsubscribe()
+	seen := len(trips)
+	if err := net.AddNode("d", 600, 0); err != nil {
+		t.Fatalf("add node failed: %v", err)
+	}
+	sim.Run(context.Background(), 60)
+	if len(trips) != seen {
+		t.Errorf("expected no trips after unsubscribing")
+	}
+	if len(nodes) != 1 || nodes[0] != "d" {
+		t.Errorf("expected the node created mid-run to be reported, got %v", nodes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sim.Run(ctx, 60); err == nil {
+		t.Errorf("expected a cancelled run to stop")
+	}
+}
+
+func TestNetworkSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corridor.json")
+	if err := buildCorridor(t).Save(path); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	net, err := trafficsim.LoadNetwork(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := net.Nodes(); len(got) != 3 {
+		t.Errorf("expected 3 nodes, got %v", got)
+	}
+
+	run := func() int {
+		net, err := trafficsim.LoadNetwork(path)
+		if err != nil {
+			t.Fatalf("load failed: %v", err)
+		}
+		sim := trafficsim.NewSimulation(net, trafficsim.Options{Seed: 9})
+		sim.Run(context.Background(), 60)
+		return len(sim.Vehicles())
+	}
+	if a, b := run(), run(); a != b {
+		t.Errorf("expected the same seed to give the same run, got %d and %d vehicles", a, b)
+	}
+}
@@ -0,0 +1,107 @@
+package trafficsim
+
+import (
+	"traffic-sim/internal/events"
+	"traffic-sim/internal/metrics"
+)
+
+// The metric types are those of the simulator's own reports and CSV exports.
+type (
+	RoadInterval       = metrics.RoadInterval
+	DetectorInterval   = metrics.DetectorInterval
+	IntersectionReport = metrics.IntersectionReport
+	ApproachReport     = metrics.ApproachReport
+	Sample             = metrics.Sample
+	RoadPoint          = metrics.RoadPoint
+	Trip               = events.TripCompletedEvent
+)
+
+const (
+	EventRoadCreated           = events.EventRoadCreated
+	EventRoadDeleted           = events.EventRoadDeleted
+	EventNodeCreated           = events.EventNodeCreated
+	EventNodeDeleted           = events.EventNodeDeleted
+	EventSpawnPointCreated     = events.EventSpawnPointCreated
+	EventDespawnPointCreated   = events.EventDespawnPointCreated
+	EventTrafficLightCreated   = events.EventTrafficLightCreated
+	EventRoadPropertiesUpdated = events.EventRoadPropertiesUpdated
+	EventEmergencyDispatched   = events.EventEmergencyDispatched
+	EventEmergencyArrived      = events.EventEmergencyArrived
+	EventSpeedZoneCreated      = events.EventSpeedZoneCreated
+	EventSpeedZoneRemoved      = events.EventSpeedZoneRemoved
+	EventSpeedZoneUpdated      = events.EventSpeedZoneUpdated
+	EventIncidentCreated       = events.EventIncidentCreated
+	EventIncidentCleared       = events.EventIncidentCleared
+	EventIncidentStarted       = events.EventIncidentStarted
+	EventIncidentEnded         = events.EventIncidentEnded
+	EventTripCompleted         = events.EventTripCompleted
+	EventDetectorCreated       = events.EventDetectorCreated
+	EventDetectorRemoved       = events.EventDetectorRemoved
+)
+
+// Event is a change in the simulation. ID names what it is about: the road,
+// node, spawn point, light, vehicle, speed zone, incident or detector. Trip
+// is only set for EventTripCompleted.
+type Event struct {
+	Name string
+	Time float64
+	ID   string
+	Trip *Trip
+}
+
+func newEvent(name string, payload any) Event {
+	ev := Event{Name: name}
+	switch p := payload.(type) {
+	case events.RoadCreatedEvent:
+		ev.ID = p.Road.ID
+	case events.RoadDeletedEvent:
+		ev.ID = p.RoadID
+	case events.NodeCreatedEvent:
+		ev.ID = p.Node.ID
+	case events.NodeDeletedEvent:
+		ev.ID = p.NodeID
+	case events.SpawnPointCreatedEvent:
+		ev.ID = p.SpawnPoint.ID
+	case events.DespawnPointCreatedEvent:
+		ev.ID = p.DespawnPoint.ID
+	case events.TrafficLightCreatedEvent:
+		ev.ID = p.TrafficLight.ID
+	case events.RoadPropertiesUpdatedEvent:
+		ev.ID = p.Road.ID
+	case events.EmergencyDispatchedEvent:
+		ev.ID = p.VehicleID
+	case events.EmergencyArrivedEvent:
+		ev.ID = p.VehicleID
+	case events.SpeedZoneEvent:
+		ev.ID = p.Zone.ID
+	case events.IncidentEvent:
+		ev.ID = p.Incident.ID
+	case events.DetectorEvent:
+		ev.ID = p.Detector.ID
+	case events.TripCompletedEvent:
+		trip := p
+		ev.ID = p.VehicleID
+		ev.Trip = &trip
+	}
+	return ev
+}
+
+// Vehicle is a copy of a vehicle's state at the time it was read. Distance
+// is how far along its road it is.
+type Vehicle struct {
+	ID        string
+	Road      string
+	Distance  float64
+	X, Y      float64
+	Speed     float64
+	Emergency bool
+}
+
+// Light is a copy of a traffic light's state: "green", "yellow" or "red",
+// with Timer the seconds spent in it so far.
+type Light struct {
+	ID    string
+	Node  string
+	State string
+	Timer float64
+}